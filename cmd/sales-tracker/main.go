@@ -16,6 +16,21 @@ func main() {
 		zlog.Logger.Fatal().Err(err).Msg("Failed to load config")
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			os.Exit(app.Verify(cfg, &zlog.Logger, os.Args[2:], os.Stdout))
+		case "apikey":
+			os.Exit(app.APIKey(cfg, &zlog.Logger, os.Args[2:], os.Stdout))
+		case "workspace":
+			os.Exit(app.Workspace(cfg, &zlog.Logger, os.Args[2:], os.Stdout))
+		case "ledger":
+			os.Exit(app.Ledger(cfg, &zlog.Logger, os.Args[2:], os.Stdout))
+		default:
+			zlog.Logger.Fatal().Str("command", os.Args[1]).Msg("Unknown command")
+		}
+	}
+
 	application, err := app.NewApp(cfg, &zlog.Logger)
 	if err != nil {
 		zlog.Logger.Fatal().Err(err).Msg("Failed to create application")
@@ -0,0 +1,11 @@
+#!/bin/sh
+# Создаёт роль приложения при первой инициализации тома PostgreSQL. Роль
+# без SUPERUSER и BYPASSRLS, поэтому политики RLS действуют на все её
+# запросы; права на таблицы выдаёт миграция 017_create_app_role.sql.
+set -e
+
+psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" \
+    -v app_password="$APP_DB_PASSWORD" <<'SQL'
+CREATE ROLE sales_tracker_app LOGIN PASSWORD :'app_password'
+    NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE;
+SQL
@@ -2,7 +2,12 @@ module sales-tracker
 
 go 1.24.7
 
-require github.com/wb-go/wbf v0.0.12
+require (
+	github.com/lib/pq v1.10.9
+	github.com/wb-go/wbf v0.0.12
+	github.com/xuri/excelize/v2 v2.9.1
+	golang.org/x/text v0.32.0
+)
 
 require (
 	github.com/BurntSushi/toml v1.2.1 // indirect
@@ -11,9 +16,13 @@ require (
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/richardlehane/mscfb v1.0.4 // indirect
+	github.com/richardlehane/msoleps v1.0.4 // indirect
+	github.com/tiendc/go-deepcopy v1.6.0 // indirect
+	github.com/xuri/efp v0.0.1 // indirect
+	github.com/xuri/nfp v0.0.1 // indirect
 	golang.org/x/crypto v0.46.0 // indirect
-	golang.org/x/text v0.32.0 // indirect
+	golang.org/x/net v0.47.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3 // indirect
 )
@@ -24,6 +33,6 @@ require (
 	github.com/ilyakaznacheev/cleanenv v1.5.0
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
-	github.com/rs/zerolog v1.30.0 // indirect
+	github.com/rs/zerolog v1.30.0
 	golang.org/x/sys v0.39.0 // indirect
 )
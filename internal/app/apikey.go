@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sales-tracker/internal/auth"
+	"sales-tracker/internal/config"
+	"sales-tracker/internal/domain"
+	apikeys_postgres "sales-tracker/internal/repository/apikeys/postgres"
+	"sales-tracker/internal/repository/tenantdb"
+	workspaces_postgres "sales-tracker/internal/repository/workspaces/postgres"
+	auth_usecase "sales-tracker/internal/usecase/auth"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+)
+
+const apiKeyUsage = `usage:
+  sales-tracker apikey create [-workspace SLUG] -name NAME -roles ROLE[,ROLE...] [-ttl DURATION]
+  sales-tracker apikey list [-workspace SLUG]
+  sales-tracker apikey revoke [-workspace SLUG] ID`
+
+// APIKey управляет API-ключами из командной строки: так выпускается первый
+// ключ администратора, когда обратиться к /auth/keys ещё нечем. Ключ
+// принадлежит пространству -workspace (по умолчанию default). Возвращает
+// код выхода.
+func APIKey(cfg *config.Config, logger *zlog.Zerolog, args []string, out io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(out, apiKeyUsage)
+		return 2
+	}
+	service, err := newAuthService(cfg, logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to open database")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("apikey "+args[0], flag.ContinueOnError)
+	fs.SetOutput(out)
+	slug := fs.String("workspace", domain.DefaultWorkspaceSlug, "workspace slug")
+	name := fs.String("name", "", "key name")
+	roles := fs.String("roles", "", "comma-separated roles")
+	ttl := fs.Duration("ttl", 0, "key lifetime, 0 — unlimited")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	ws, err := service.GetWorkspace(cliContext(), *slug)
+	if err != nil {
+		logger.Error().Err(err).Str("workspace", *slug).Msg("Failed to find workspace")
+		return 1
+	}
+	ctx := domain.WithWorkspace(cliContext(), ws.ID)
+
+	switch args[0] {
+	case "create":
+		key := &domain.APIKey{Name: *name, Roles: strings.Split(*roles, ",")}
+		if *ttl > 0 {
+			expiresAt := time.Now().Add(*ttl)
+			key.ExpiresAt = &expiresAt
+		}
+		secret, err := service.CreateAPIKey(ctx, key)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to create api key")
+			return 1
+		}
+		fmt.Fprintf(out, "created api key %d (%s) in workspace %s, roles: %s\n", key.ID, key.Name, ws.Slug, strings.Join(key.Roles, ","))
+		fmt.Fprintln(out, secret)
+		return 0
+	case "list":
+		keys, err := service.ListAPIKeys(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to list api keys")
+			return 1
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tROLES\tCREATED\tEXPIRES\tLAST USED\tSTATUS")
+		now := time.Now()
+		for _, key := range keys {
+			status := "active"
+			if !key.Active(now) {
+				status = "inactive"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				key.ID, key.Name, key.Prefix, strings.Join(key.Roles, ","),
+				key.CreatedAt.Format(time.RFC3339), formatTime(key.ExpiresAt), formatTime(key.LastUsedAt), status)
+		}
+		tw.Flush()
+		return 0
+	case "revoke":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(out, apiKeyUsage)
+			return 2
+		}
+		id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			fmt.Fprintln(out, apiKeyUsage)
+			return 2
+		}
+		if _, err := service.RevokeAPIKey(ctx, id); err != nil {
+			logger.Error().Err(err).Int64("id", id).Msg("Failed to revoke api key")
+			return 1
+		}
+		fmt.Fprintf(out, "revoked api key %d\n", id)
+		return 0
+	default:
+		fmt.Fprintln(out, apiKeyUsage)
+		return 2
+	}
+}
+
+// newAuthService собирает auth-сервис для команд CLI.
+func newAuthService(cfg *config.Config, logger *zlog.Zerolog) (*auth_usecase.Service, error) {
+	db, err := openDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	retries := cfg.DefaultRetryStrategy()
+	repo := apikeys_postgres.NewAPIKeysPostgresRepository(tenantdb.New(db), retries)
+	workspaces := workspaces_postgres.NewWorkspacesPostgresRepository(db, retries)
+	// Проверка JWT командам не нужна.
+	tokens, _ := auth.NewJWTVerifier(auth.JWTOptions{})
+	return auth_usecase.NewService(repo, workspaces, tokens, auth_usecase.Options{}, logger), nil
+}
+
+// cliContext — контекст команд CLI: они выполняются от имени system.
+func cliContext() context.Context {
+	return domain.WithPrincipal(context.Background(), domain.SystemPrincipal)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -6,14 +6,37 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sales-tracker/internal/auth"
 	"sales-tracker/internal/config"
+	"sales-tracker/internal/domain"
+	accounts_handler "sales-tracker/internal/http-server/handler/accounts"
 	analytics_handler "sales-tracker/internal/http-server/handler/analytics"
+	audit_handler "sales-tracker/internal/http-server/handler/audit"
+	auth_handler "sales-tracker/internal/http-server/handler/auth"
 	items_handler "sales-tracker/internal/http-server/handler/items"
+	ledger_handler "sales-tracker/internal/http-server/handler/ledger"
+	rates_handler "sales-tracker/internal/http-server/handler/rates"
+	reports_handler "sales-tracker/internal/http-server/handler/reports"
+	"sales-tracker/internal/http-server/middleware"
 	"sales-tracker/internal/http-server/router"
+	accounts_postgres "sales-tracker/internal/repository/accounts/postgres"
 	analytics_postgres "sales-tracker/internal/repository/analytics/postgres"
+	apikeys_postgres "sales-tracker/internal/repository/apikeys/postgres"
+	artifacts_local "sales-tracker/internal/repository/artifacts/local"
 	items_postgres "sales-tracker/internal/repository/items/postgres"
+	ledger_postgres "sales-tracker/internal/repository/ledger/postgres"
+	rates_postgres "sales-tracker/internal/repository/rates/postgres"
+	reports_postgres "sales-tracker/internal/repository/reports/postgres"
+	"sales-tracker/internal/repository/tenantdb"
+	workspaces_postgres "sales-tracker/internal/repository/workspaces/postgres"
+	accounts_usecase "sales-tracker/internal/usecase/accounts"
 	analytics_usecase "sales-tracker/internal/usecase/analytics"
+	auth_usecase "sales-tracker/internal/usecase/auth"
 	items_usecase "sales-tracker/internal/usecase/items"
+	ledger_usecase "sales-tracker/internal/usecase/ledger"
+	rates_usecase "sales-tracker/internal/usecase/rates"
+	reports_usecase "sales-tracker/internal/usecase/reports"
+	"sync"
 	"syscall"
 
 	"github.com/wb-go/wbf/dbpg"
@@ -21,33 +44,89 @@ import (
 )
 
 type App struct {
-	cfg    *config.Config
-	logger *zlog.Zerolog
-	server *http.Server
+	cfg     *config.Config
+	logger  *zlog.Zerolog
+	server  *http.Server
+	items   *items_usecase.Service
+	reports *reports_usecase.Service
 }
 
 func NewApp(cfg *config.Config, logger *zlog.Zerolog) (*App, error) {
 	retries := cfg.DefaultRetryStrategy()
-	dbOpts := &dbpg.Options{
-		MaxOpenConns:    cfg.DB.MaxOpenConns,
-		MaxIdleConns:    cfg.DB.MaxIdleConns,
-		ConnMaxLifetime: cfg.DB.ConnMaxLifetime,
+	db, err := openDB(cfg)
+	if err != nil {
+		return nil, err
 	}
-	db, err := dbpg.New(cfg.DBDSN(), []string{}, dbOpts)
+	warnIfRLSBypassed(db, logger)
+
+	// Данные пространств, в том числе курсы валют, читаются и пишутся только
+	// через tenantdb; справочник пространств общий.
+	tenantDB := tenantdb.New(db)
+	itemsRepo := items_postgres.NewPostgresRepository(tenantDB, retries)
+	analyticsRepo := analytics_postgres.NewAnalyticsPostgresRepository(tenantDB, retries)
+	ratesRepo := rates_postgres.NewRatesPostgresRepository(tenantDB, retries)
+	reportsRepo := reports_postgres.NewReportsPostgresRepository(tenantDB, retries)
+	apiKeysRepo := apikeys_postgres.NewAPIKeysPostgresRepository(tenantDB, retries)
+	accountsRepo := accounts_postgres.NewAccountsPostgresRepository(tenantDB, retries)
+	ledgerRepo := ledger_postgres.NewLedgerPostgresRepository(tenantDB, retries)
+	workspacesRepo := workspaces_postgres.NewWorkspacesPostgresRepository(db, retries)
+	artifacts, err := artifacts_local.NewStorage(cfg.Reports.Dir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to init reports storage: %w", err)
 	}
 
-	itemsRepo := items_postgres.NewPostgresRepository(db, retries)
-	analyticsRepo := analytics_postgres.NewAnalyticsPostgresRepository(db, retries)
+	tokens, err := auth.NewJWTVerifier(auth.JWTOptions{
+		Secret:   cfg.Auth.JWTSecret,
+		JWKSFile: cfg.Auth.JWKSFile,
+		Issuer:   cfg.Auth.JWTIssuer,
+		Audience: cfg.Auth.JWTAudience,
+		Leeway:   cfg.Auth.JWTLeeway,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init JWT verifier: %w", err)
+	}
 
-	itemsUsecase := items_usecase.NewService(itemsRepo, logger)
+	authUsecase := auth_usecase.NewService(apiKeysRepo, workspacesRepo, tokens, auth_usecase.Options{
+		DefaultWorkspace: cfg.Auth.JWTDefaultWorkspace,
+	}, logger)
+	itemsUsecase := items_usecase.NewService(itemsRepo, items_usecase.Options{
+		IdempotencyTTL: cfg.Items.IdempotencyTTL,
+		TrashRetention: cfg.Items.TrashRetention,
+		PurgeInterval:  cfg.Items.PurgeInterval,
+	}, logger)
 	analyticsUsecase := analytics_usecase.NewService(analyticsRepo, logger)
+	ratesUsecase := rates_usecase.NewService(ratesRepo, logger)
+	accountsUsecase := accounts_usecase.NewService(accountsRepo, logger)
+	ledgerUsecase := ledger_usecase.NewService(ledgerRepo, logger)
+	reportsUsecase := reports_usecase.NewService(reportsRepo, analyticsUsecase, artifacts, reports_usecase.Options{
+		Workers:         cfg.Reports.Workers,
+		TTL:             cfg.Reports.TTL,
+		PollInterval:    cfg.Reports.PollInterval,
+		CleanupInterval: cfg.Reports.CleanupInterval,
+		JobTimeout:      cfg.Reports.JobTimeout,
+	}, logger)
 
 	itemsHandler := items_handler.NewHandler(itemsUsecase, analyticsUsecase, logger)
 	analyticsHandler := analytics_handler.NewHandler(analyticsUsecase, logger)
+	ratesHandler := rates_handler.NewHandler(ratesUsecase, logger)
+	reportsHandler := reports_handler.NewHandler(reportsUsecase, logger)
+	auditHandler := audit_handler.NewHandler(itemsUsecase, logger)
+	authHandler := auth_handler.NewHandler(authUsecase, logger)
+	accountsHandler := accounts_handler.NewHandler(accountsUsecase, logger)
+	ledgerHandler := ledger_handler.NewHandler(ledgerUsecase, logger)
+
+	authMiddleware := middleware.AuthMiddleware(authUsecase)
+	if !cfg.Auth.Enabled {
+		for _, role := range cfg.Auth.AnonymousRoles {
+			if !domain.IsRole(role) {
+				return nil, fmt.Errorf("unknown role %q in AUTH_ANONYMOUS_ROLES", role)
+			}
+		}
+		authMiddleware = middleware.AnonymousMiddleware(cfg.Auth.AnonymousRoles)
+		logger.Warn().Strs("roles", cfg.Auth.AnonymousRoles).Msg("Authentication is disabled, API is open to anyone")
+	}
 
-	mux := router.NewRouter(itemsHandler, analyticsHandler, logger)
+	mux := router.NewRouter(itemsHandler, analyticsHandler, ratesHandler, reportsHandler, auditHandler, authHandler, accountsHandler, ledgerHandler, authMiddleware, logger)
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Server.Addr,
@@ -58,13 +137,60 @@ func NewApp(cfg *config.Config, logger *zlog.Zerolog) (*App, error) {
 	}
 
 	return &App{
-		cfg:    cfg,
-		logger: logger,
-		server: server,
+		cfg:     cfg,
+		logger:  logger,
+		server:  server,
+		items:   itemsUsecase,
+		reports: reportsUsecase,
 	}, nil
 }
 
+func openDB(cfg *config.Config) (*dbpg.DB, error) {
+	dbOpts := &dbpg.Options{
+		MaxOpenConns:    cfg.DB.MaxOpenConns,
+		MaxIdleConns:    cfg.DB.MaxIdleConns,
+		ConnMaxLifetime: cfg.DB.ConnMaxLifetime,
+	}
+	db, err := dbpg.New(cfg.DBDSN(), []string{}, dbOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
+
+// warnIfRLSBypassed предупреждает, если роль подключения обходит политики
+// RLS: тогда пространства разделяют только условия запросов.
+func warnIfRLSBypassed(db *dbpg.DB, logger *zlog.Zerolog) {
+	var bypass bool
+	query := `SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`
+	if err := db.Master.QueryRowContext(context.Background(), query).Scan(&bypass); err != nil {
+		logger.Warn().Err(err).Msg("Failed to check database role attributes")
+		return
+	}
+	if bypass {
+		logger.Warn().Msg("Database role is a superuser or has BYPASSRLS, row-level security is not enforced")
+	}
+}
+
 func (a *App) Run() error {
+	// Фоновые задачи выполняются от имени system и проходят проверки прав.
+	// Они обслуживают все пространства; задачи отдельного пространства
+	// (отчёты) сужают контекст сами.
+	workersCtx, stopWorkers := context.WithCancel(domain.WithAllWorkspaces(
+		domain.WithPrincipal(context.Background(), domain.SystemPrincipal)))
+	var workers sync.WaitGroup
+	for _, run := range []func(context.Context){a.reports.Run, a.items.Run} {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workersCtx)
+		}()
+	}
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+	}()
+
 	errCh := make(chan error, 1)
 	go func() {
 		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sales-tracker/internal/config"
+	"sales-tracker/internal/domain"
+	ledger_postgres "sales-tracker/internal/repository/ledger/postgres"
+	"sales-tracker/internal/repository/tenantdb"
+	workspaces_postgres "sales-tracker/internal/repository/workspaces/postgres"
+	ledger_usecase "sales-tracker/internal/usecase/ledger"
+
+	"github.com/wb-go/wbf/zlog"
+)
+
+const ledgerUsage = `usage:
+  sales-tracker ledger enable [-workspace SLUG]`
+
+// Ledger управляет режимом двойной записи. enable включает его в
+// пространстве -workspace (по умолчанию default) и проводит существующие
+// счета и операции; повторный запуск перестраивает автоматические проводки.
+// Возвращает код выхода.
+func Ledger(cfg *config.Config, logger *zlog.Zerolog, args []string, out io.Writer) int {
+	if len(args) == 0 || args[0] != "enable" {
+		fmt.Fprintln(out, ledgerUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("ledger enable", flag.ContinueOnError)
+	fs.SetOutput(out)
+	slug := fs.String("workspace", domain.DefaultWorkspaceSlug, "workspace slug")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to open database")
+		return 2
+	}
+	retries := cfg.DefaultRetryStrategy()
+	tenantDB := tenantdb.New(db)
+	service := ledger_usecase.NewService(ledger_postgres.NewLedgerPostgresRepository(tenantDB, retries), logger)
+	ws, err := workspaces_postgres.NewWorkspacesPostgresRepository(db, retries).GetWorkspaceBySlug(cliContext(), *slug)
+	if err != nil {
+		logger.Error().Err(err).Str("workspace", *slug).Msg("Failed to find workspace")
+		return 1
+	}
+
+	if err := service.EnableLedger(domain.WithWorkspace(cliContext(), ws.ID)); err != nil {
+		logger.Error().Err(err).Str("workspace", ws.Slug).Msg("Failed to enable ledger")
+		return 1
+	}
+	fmt.Fprintf(out, "ledger enabled in workspace %s\n", ws.Slug)
+	return 0
+}
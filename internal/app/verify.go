@@ -0,0 +1,93 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sales-tracker/internal/config"
+	"sales-tracker/internal/domain"
+	items_postgres "sales-tracker/internal/repository/items/postgres"
+	"sales-tracker/internal/repository/tenantdb"
+	workspaces_postgres "sales-tracker/internal/repository/workspaces/postgres"
+	items_usecase "sales-tracker/internal/usecase/items"
+
+	"github.com/wb-go/wbf/zlog"
+)
+
+// Verify проверяет цепочки хешей журнала изменений и печатает результат
+// в out. Без флага --workspace проверяются все пространства. Флаг
+// --expect-head <seq>:<hash> сверяет цепочку пространства с головой,
+// сохранённой при прошлой проверке, и требует --workspace. Возвращает код
+// выхода: 0 — все цепочки целы, 1 — хотя бы одна нарушена, 2 — проверку
+// выполнить не удалось.
+func Verify(cfg *config.Config, logger *zlog.Zerolog, args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(out)
+	slug := fs.String("workspace", "", "workspace slug (default: all workspaces)")
+	expectHead := fs.String("expect-head", "", "expected chain head as <seq>:<hash>")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	var anchor *domain.AuditAnchor
+	if *expectHead != "" {
+		if *slug == "" {
+			logger.Error().Msg("--expect-head requires --workspace")
+			return 2
+		}
+		a, err := domain.ParseAuditAnchor(*expectHead)
+		if err != nil {
+			logger.Error().Err(err).Str("expect_head", *expectHead).Msg("Invalid expected head")
+			return 2
+		}
+		anchor = a
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to open database")
+		return 2
+	}
+	retries := cfg.DefaultRetryStrategy()
+	repo := items_postgres.NewPostgresRepository(tenantdb.New(db), retries)
+	service := items_usecase.NewService(repo, items_usecase.Options{}, logger)
+	workspacesRepo := workspaces_postgres.NewWorkspacesPostgresRepository(db, retries)
+
+	var workspaces []*domain.Workspace
+	if *slug != "" {
+		ws, err := workspacesRepo.GetWorkspaceBySlug(cliContext(), *slug)
+		if err != nil {
+			logger.Error().Err(err).Str("workspace", *slug).Msg("Failed to get workspace")
+			return 2
+		}
+		workspaces = []*domain.Workspace{ws}
+	} else {
+		workspaces, err = workspacesRepo.ListWorkspaces(cliContext())
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to list workspaces")
+			return 2
+		}
+	}
+
+	code := 0
+	for _, ws := range workspaces {
+		result, err := service.VerifyAudit(domain.WithWorkspace(cliContext(), ws.ID), anchor)
+		if err != nil {
+			logger.Error().Err(err).Str("workspace", ws.Slug).Msg("Audit verification failed")
+			return 2
+		}
+		if !result.Valid {
+			b := result.Broken
+			if b.EntryID == 0 {
+				fmt.Fprintf(out, "[%s] audit chain BROKEN at seq %d: %s\n", ws.Slug, b.Seq, b.Reason)
+			} else {
+				fmt.Fprintf(out, "[%s] audit chain BROKEN at seq %d (entry id %d): %s\n", ws.Slug, b.Seq, b.EntryID, b.Reason)
+			}
+			fmt.Fprintf(out, "[%s] last valid entry: seq %d, hash %s\n", ws.Slug, result.HeadSeq, result.HeadHash)
+			code = 1
+			continue
+		}
+		fmt.Fprintf(out, "[%s] audit chain OK: %d entries\n", ws.Slug, result.Checked)
+		fmt.Fprintf(out, "[%s] head: seq %d, hash %s\n", ws.Slug, result.HeadSeq, result.HeadHash)
+	}
+	return code
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sales-tracker/internal/config"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wb-go/wbf/zlog"
+)
+
+const workspaceUsage = `usage:
+  sales-tracker workspace create -slug SLUG [-name NAME]
+  sales-tracker workspace list`
+
+// Workspace управляет рабочими пространствами. Первый ключ администратора
+// нового пространства выпускается командой apikey create -workspace SLUG.
+// Возвращает код выхода.
+func Workspace(cfg *config.Config, logger *zlog.Zerolog, args []string, out io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(out, workspaceUsage)
+		return 2
+	}
+	service, err := newAuthService(cfg, logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to open database")
+		return 2
+	}
+	ctx := cliContext()
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("workspace create", flag.ContinueOnError)
+		fs.SetOutput(out)
+		slug := fs.String("slug", "", "workspace slug")
+		name := fs.String("name", "", "display name, defaults to slug")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		ws := &domain.Workspace{Slug: *slug, Name: *name}
+		if err := service.CreateWorkspace(ctx, ws); err != nil {
+			logger.Error().Err(err).Msg("Failed to create workspace")
+			if errors.Is(err, customErr.ErrInvalidInput) {
+				return 2
+			}
+			return 1
+		}
+		fmt.Fprintf(out, "created workspace %d (%s)\n", ws.ID, ws.Slug)
+		return 0
+	case "list":
+		workspaces, err := service.ListWorkspaces(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("Failed to list workspaces")
+			return 1
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSLUG\tNAME\tCREATED")
+		for _, ws := range workspaces {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", ws.ID, ws.Slug, ws.Name, ws.CreatedAt.Format(time.RFC3339))
+		}
+		tw.Flush()
+		return 0
+	default:
+		fmt.Fprintln(out, workspaceUsage)
+		return 2
+	}
+}
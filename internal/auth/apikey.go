@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix отличает API-ключи от JWT в заголовке Authorization.
+const APIKeyPrefix = "st_"
+
+const (
+	lookupLen = 8
+	secretLen = 32
+)
+
+// GenerateAPIKey создаёт ключ вида st_<lookup>_<secret> и возвращает его
+// вместе с частью lookup, по которой ключ ищется в базе.
+func GenerateAPIKey() (key, lookup string, err error) {
+	var lookupBytes [lookupLen / 2]byte
+	if _, err := rand.Read(lookupBytes[:]); err != nil {
+		return "", "", err
+	}
+	var secret [secretLen]byte
+	if _, err := rand.Read(secret[:]); err != nil {
+		return "", "", err
+	}
+	lookup = hex.EncodeToString(lookupBytes[:])
+	key = APIKeyPrefix + lookup + "_" + base64.RawURLEncoding.EncodeToString(secret[:])
+	return key, lookup, nil
+}
+
+// IsAPIKey сообщает, похож ли токен на API-ключ.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// ParseAPIKey извлекает lookup из ключа.
+func ParseAPIKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	lookup, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(lookup) != lookupLen || secret == "" {
+		return "", false
+	}
+	return lookup, true
+}
+
+// HashAPIKey возвращает SHA-256 ключа в hex. Ключ содержит 256 бит
+// случайности, поэтому медленный хеш паролей не нужен.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// MatchAPIKey сравнивает ключ с сохранённым хешем за постоянное время.
+func MatchAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
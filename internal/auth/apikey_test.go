@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, lookup, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsAPIKey(key) {
+		t.Fatalf("key %q has no %q prefix", key, APIKeyPrefix)
+	}
+	parsed, ok := ParseAPIKey(key)
+	if !ok || parsed != lookup {
+		t.Fatalf("ParseAPIKey(%q) = %q, %v; want %q", key, parsed, ok, lookup)
+	}
+	if !MatchAPIKey(key, HashAPIKey(key)) {
+		t.Fatal("key does not match its own hash")
+	}
+	other, otherLookup, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key || otherLookup == lookup {
+		t.Fatal("two generated keys are equal")
+	}
+}
+
+func TestParseAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		wantLookup string
+		wantOK     bool
+	}{
+		{"valid", "st_0123abcd_secret", "0123abcd", true},
+		{"secret with separator", "st_0123abcd_sec_ret", "0123abcd", true},
+		{"wrong prefix", "sk_0123abcd_secret", "", false},
+		{"uppercase prefix", "ST_0123abcd_secret", "", false},
+		{"no prefix", "0123abcd_secret", "", false},
+		{"bearer JWT", "eyJhbGciOiJIUzI1NiJ9.e30.sig", "", false},
+		{"short lookup", "st_0123abc_secret", "", false},
+		{"long lookup", "st_0123abcde_secret", "", false},
+		{"missing secret", "st_0123abcd_", "", false},
+		{"missing separator", "st_0123abcdsecret", "", false},
+		{"prefix only", "st_", "", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookup, ok := ParseAPIKey(tt.key)
+			if lookup != tt.wantLookup || ok != tt.wantOK {
+				t.Fatalf("ParseAPIKey(%q) = %q, %v; want %q, %v", tt.key, lookup, ok, tt.wantLookup, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMatchAPIKey(t *testing.T) {
+	key := "st_0123abcd_secret"
+	hash := HashAPIKey(key)
+	tests := []struct {
+		name string
+		key  string
+		hash string
+		want bool
+	}{
+		{"same key", key, hash, true},
+		{"other secret", "st_0123abcd_secreT", hash, false},
+		{"other lookup", "st_0123abce_secret", hash, false},
+		{"truncated hash", key, hash[:len(hash)-1], false},
+		{"uppercase hash", key, strings.ToUpper(hash), false},
+		{"empty hash", key, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchAPIKey(tt.key, tt.hash); got != tt.want {
+				t.Fatalf("MatchAPIKey = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
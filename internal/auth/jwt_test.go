@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "test-secret"
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, header, claims map[string]any, secret []byte) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, header, claims map[string]any, key *rsa.PrivateKey) string {
+	t.Helper()
+	signed := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	t.Helper()
+	set := map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":       "user-1",
+		"roles":     []string{"viewer"},
+		"workspace": "default",
+		"iss":       "issuer",
+		"aud":       "sales-tracker",
+		"exp":       testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func withClaim(name string, value any) map[string]any {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
+
+func TestJWTVerifierVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksPath := writeJWKS(t, "key-1", &key.PublicKey)
+
+	hmacOnly, err := NewJWTVerifier(JWTOptions{Secret: testSecret, Issuer: "issuer", Audience: "sales-tracker", Leeway: 30 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaOnly, err := NewJWTVerifier(JWTOptions{JWKSFile: jwksPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	both, err := NewJWTVerifier(JWTOptions{Secret: testSecret, JWKSFile: jwksPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hs256 := map[string]any{"alg": "HS256", "typ": "JWT"}
+	rs256 := map[string]any{"alg": "RS256", "kid": "key-1"}
+	unsigned := encodeSegment(t, map[string]any{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + "."
+
+	tests := []struct {
+		name     string
+		verifier *JWTVerifier
+		token    string
+		wantErr  error
+	}{
+		{"valid HS256", hmacOnly, signHS256(t, hs256, validClaims(), []byte(testSecret)), nil},
+		{"valid RS256", rsaOnly, signRS256(t, rs256, validClaims(), key), nil},
+		{"RS256 without kid and single key", rsaOnly, signRS256(t, map[string]any{"alg": "RS256"}, validClaims(), key), nil},
+		{"alg none", hmacOnly, unsigned, ErrUnsupportedAlg},
+		{"alg none with JWKS", rsaOnly, unsigned, ErrUnsupportedAlg},
+		{"alg lowercase", hmacOnly, signHS256(t, map[string]any{"alg": "hs256"}, validClaims(), []byte(testSecret)), ErrUnsupportedAlg},
+		{"HS256 signed with RSA public key, JWKS only", rsaOnly, signHS256(t, hs256, validClaims(), publicDER), ErrUnsupportedAlg},
+		{"HS256 signed with RSA public key, both configured", both, signHS256(t, hs256, validClaims(), publicDER), ErrBadSignature},
+		{"RS256 without JWKS", hmacOnly, signRS256(t, rs256, validClaims(), key), ErrUnsupportedAlg},
+		{"wrong secret", hmacOnly, signHS256(t, hs256, validClaims(), []byte("other")), ErrBadSignature},
+		{"wrong kid", rsaOnly, signRS256(t, map[string]any{"alg": "RS256", "kid": "key-2"}, validClaims(), key), ErrUnknownKey},
+		{"expired", hmacOnly, signHS256(t, hs256, withClaim("exp", testNow.Add(-time.Minute).Unix()), []byte(testSecret)), ErrTokenExpired},
+		{"expired within leeway", hmacOnly, signHS256(t, hs256, withClaim("exp", testNow.Add(-10*time.Second).Unix()), []byte(testSecret)), nil},
+		{"missing exp", hmacOnly, signHS256(t, hs256, withClaim("exp", nil), []byte(testSecret)), ErrBadClaims},
+		{"not yet valid", hmacOnly, signHS256(t, hs256, withClaim("nbf", testNow.Add(time.Minute).Unix()), []byte(testSecret)), ErrTokenNotActive},
+		{"nbf within leeway", hmacOnly, signHS256(t, hs256, withClaim("nbf", testNow.Add(10*time.Second).Unix()), []byte(testSecret)), nil},
+		{"missing sub", hmacOnly, signHS256(t, hs256, withClaim("sub", nil), []byte(testSecret)), ErrBadClaims},
+		{"wrong issuer", hmacOnly, signHS256(t, hs256, withClaim("iss", "other"), []byte(testSecret)), ErrBadClaims},
+		{"audience list", hmacOnly, signHS256(t, hs256, withClaim("aud", []string{"other", "sales-tracker"}), []byte(testSecret)), nil},
+		{"wrong audience", hmacOnly, signHS256(t, hs256, withClaim("aud", "other"), []byte(testSecret)), ErrBadClaims},
+		{"two segments", hmacOnly, "abc.def", ErrMalformedToken},
+		{"four segments", hmacOnly, "a.b.c.d", ErrMalformedToken},
+		{"empty token", hmacOnly, "", ErrMalformedToken},
+		{"header not base64", hmacOnly, "!!!." + encodeSegment(t, validClaims()) + ".sig", ErrMalformedToken},
+		{"header not JSON", hmacOnly, base64.RawURLEncoding.EncodeToString([]byte("nope")) + "." + encodeSegment(t, validClaims()) + ".sig", ErrMalformedToken},
+		{"signature not base64", hmacOnly, encodeSegment(t, hs256) + "." + encodeSegment(t, validClaims()) + ".!!!", ErrMalformedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.verifier.Verify(tt.token, testNow)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if claims.Subject != "user-1" {
+					t.Fatalf("subject = %q, want user-1", claims.Subject)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierTamperedPayload(t *testing.T) {
+	v, err := NewJWTVerifier(JWTOptions{Secret: testSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := signHS256(t, map[string]any{"alg": "HS256"}, validClaims(), []byte(testSecret))
+	forged := encodeSegment(t, map[string]any{"alg": "HS256"}) + "." +
+		encodeSegment(t, withClaim("roles", []string{"admin"})) + token[strings.LastIndex(token, "."):]
+	if _, err := v.Verify(forged, testNow); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("error = %v, want %v", err, ErrBadSignature)
+	}
+}
+
+func TestLoadJWKS(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not JSON", "{"},
+		{"no keys", `{"keys": []}`},
+		{"only encryption keys", `{"keys": [{"kty": "RSA", "kid": "k", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`},
+		{"only EC keys", `{"keys": [{"kty": "EC", "kid": "k"}]}`},
+		{"exponent too small", `{"keys": [{"kty": "RSA", "kid": "k", "n": "AQAB", "e": "AQ"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewJWTVerifier(JWTOptions{JWKSFile: write(t, tt.content)}); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+	if _, err := NewJWTVerifier(JWTOptions{JWKSFile: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
@@ -32,6 +32,29 @@ type Config struct {
 		DelayMs  int     `env:"RETRIES_DELAY_MS" validate:"required"`
 		Backoff  float64 `env:"RETRIES_BACKOFF" validate:"required"`
 	}
+	Items struct {
+		IdempotencyTTL time.Duration `env:"ITEMS_IDEMPOTENCY_TTL" env-default:"24h" validate:"required"`
+		TrashRetention time.Duration `env:"ITEMS_TRASH_RETENTION" env-default:"720h" validate:"required"`
+		PurgeInterval  time.Duration `env:"ITEMS_PURGE_INTERVAL" env-default:"1h" validate:"required"`
+	}
+	Auth struct {
+		Enabled             bool          `env:"AUTH_ENABLED" env-default:"true"`
+		JWTSecret           string        `env:"AUTH_JWT_SECRET"`
+		JWKSFile            string        `env:"AUTH_JWKS_FILE"`
+		JWTIssuer           string        `env:"AUTH_JWT_ISSUER"`
+		JWTAudience         string        `env:"AUTH_JWT_AUDIENCE"`
+		JWTLeeway           time.Duration `env:"AUTH_JWT_LEEWAY" env-default:"30s"`
+		JWTDefaultWorkspace string        `env:"AUTH_JWT_DEFAULT_WORKSPACE"`
+		AnonymousRoles      []string      `env:"AUTH_ANONYMOUS_ROLES" env-default:"viewer,editor"`
+	}
+	Reports struct {
+		Dir             string        `env:"REPORTS_DIR" env-default:"./data/reports" validate:"required"`
+		Workers         int           `env:"REPORTS_WORKERS" env-default:"2" validate:"gte=1"`
+		TTL             time.Duration `env:"REPORTS_TTL" env-default:"24h" validate:"required"`
+		PollInterval    time.Duration `env:"REPORTS_POLL_INTERVAL" env-default:"5s" validate:"required"`
+		CleanupInterval time.Duration `env:"REPORTS_CLEANUP_INTERVAL" env-default:"10m" validate:"required"`
+		JobTimeout      time.Duration `env:"REPORTS_JOB_TIMEOUT" env-default:"30m" validate:"required"`
+	}
 }
 
 func MustLoad() (*Config, error) {
@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+const (
+	AccountKindCash = "cash"
+	AccountKindBank = "bank"
+	AccountKindCard = "card"
+)
+
+// Account — место, где лежат деньги: касса, банковский счёт или карта.
+// Остаток ведётся в валюте счёта от OpeningBalance.
+type Account struct {
+	ID             int64
+	Name           string `validate:"required,max=255"`
+	Kind           string `validate:"required,oneof=cash bank card"`
+	Currency       string `validate:"required,iso4217"`
+	OpeningBalance Money
+	CreatedAt      time.Time
+}
+
+// AccountBalance — текущий остаток счёта с учётом всех действующих операций.
+type AccountBalance struct {
+	Account *Account
+	Balance Money
+}
+
+// AccountEntry — операция в выписке счёта: Amount — изменение остатка
+// (со знаком), Balance — остаток после операции.
+type AccountEntry struct {
+	Item    *Item
+	Amount  Money
+	Balance Money
+}
+
+// AccountStatementParams — период и страница выписки. Пустые From и To не
+// ограничивают период.
+type AccountStatementParams struct {
+	From   *time.Time
+	To     *time.Time
+	Offset int `validate:"gte=0"`
+	Limit  int `validate:"gte=1,lte=1000"`
+}
+
+// AccountStatement — выписка счёта с нарастающим остатком. OpeningBalance —
+// остаток до начала периода, ClosingBalance — на его конец; Entries — одна
+// страница операций периода в порядке (date, id).
+type AccountStatement struct {
+	Account        *Account
+	OpeningBalance Money
+	ClosingBalance Money
+	Total          int64
+	Entries        []*AccountEntry
+}
+
+// AccountPatch — изменяемые поля счёта; nil оставляет поле как есть.
+// Валюта не меняется: в ней записаны все операции счёта.
+type AccountPatch struct {
+	Name           *string
+	Kind           *string
+	OpeningBalance *Money
+}
@@ -1,15 +1,120 @@
 package domain
 
+import "time"
+
+const (
+	CompareNone           = ""
+	ComparePreviousPeriod = "previous_period"
+	ComparePreviousYear   = "previous_year"
+)
+
+type AnalyticsParams struct {
+	From        time.Time
+	To          time.Time
+	Currency    string    `validate:"required,iso4217"`
+	Compare     string    `validate:"omitempty,oneof=previous_period previous_year"`
+	Percentiles []float64 `validate:"max=20,unique,dive,gte=0,lte=1"`
+	Histogram   string    `validate:"omitempty,oneof=fixed log"`
+	Bins        int       `validate:"gte=0,lte=100"`
+	SkipDetails bool
+}
+
 type ItemAnalytics struct {
-	Sum       float64
-	Avg       float64
-	Count     int64
-	Median    float64
-	Percent90 float64
+	Sum         Money
+	Avg         Money
+	Count       int64
+	Median      Money
+	Percent90   Money
+	Percentiles map[float64]Money
+	Histogram   []*HistogramBin
 }
 
 type Analytics struct {
-	Income  *ItemAnalytics
-	Expense *ItemAnalytics
-	Details []*Item
+	Currency   string
+	Income     *ItemAnalytics
+	Expense    *ItemAnalytics
+	Details    []*Item
+	Comparison *AnalyticsComparison
+}
+
+// MoneyDelta — изменение показателя относительно периода сравнения.
+// Percent равен nil, если в периоде сравнения показатель был нулевым.
+type MoneyDelta struct {
+	Abs     Money
+	Percent *float64
+}
+
+type CountDelta struct {
+	Abs     int64
+	Percent *float64
+}
+
+type ItemAnalyticsDelta struct {
+	Sum       MoneyDelta
+	Avg       MoneyDelta
+	Count     CountDelta
+	Median    MoneyDelta
+	Percent90 MoneyDelta
+}
+
+type AnalyticsComparison struct {
+	Mode     string
+	From     time.Time
+	To       time.Time
+	Previous *Analytics
+	Income   *ItemAnalyticsDelta
+	Expense  *ItemAnalyticsDelta
+}
+
+type TimeSeriesParams struct {
+	From     time.Time
+	To       time.Time
+	Interval string `validate:"required,oneof=day week month quarter"`
+	TimeZone string `validate:"required,timezone"`
+	Currency string `validate:"required,iso4217"`
+}
+
+type TimeSeriesStats struct {
+	Sum   Money
+	Count int64
+	Avg   Money
+}
+
+type TimeSeriesBucket struct {
+	Start   time.Time
+	Income  TimeSeriesStats
+	Expense TimeSeriesStats
+	Net     Money
+}
+
+type TimeSeries struct {
+	Interval string
+	TimeZone string
+	Currency string
+	Buckets  []*TimeSeriesBucket
+}
+
+type BreakdownParams struct {
+	From     time.Time
+	To       time.Time
+	GroupBy  []string `validate:"required,min=1,max=3,unique,dive,oneof=category type month"`
+	Top      int      `validate:"gte=0,lte=1000"`
+	TimeZone string   `validate:"required,timezone"`
+	Currency string   `validate:"required,iso4217"`
+}
+
+// BreakdownGroup — статистика одной группы. Для сводной группы «прочее»
+// Other = true, а Keys содержит только тип операции. Share — доля группы
+// в сумме операций того же типа.
+type BreakdownGroup struct {
+	Keys  map[string]string
+	Other bool
+	Stats *ItemAnalytics
+	Share float64
+}
+
+type Breakdown struct {
+	GroupBy  []string
+	Currency string
+	Groups   []*BreakdownGroup
 }
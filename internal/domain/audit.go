@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// SystemActor — автор изменений, сделанных не по запросу пользователя
+// (фоновая очистка корзины, команды CLI).
+const SystemActor = "system"
+
+// AnonymousActor — автор изменений при выключенной аутентификации.
+const AnonymousActor = "anonymous"
+
+// AuditEntry — запись истории изменений операции. Before и After — снимки
+// строки items до и после изменения; для создания Before пуст, для
+// окончательного удаления пуст After. Записи образуют цепочку: Seq — номер
+// в цепочке, Hash покрывает поля записи и PrevHash — хеш предыдущей.
+type AuditEntry struct {
+	ID        int64
+	Seq       int64
+	ItemID    int64
+	Action    string
+	Before    json.RawMessage
+	After     json.RawMessage
+	Actor     string
+	RequestID string
+	CreatedAt time.Time
+	PrevHash  string
+	Hash      string
+}
+
+// AuditGenesisHash — PrevHash первой записи цепочки.
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+// ChainHash вычисляет хеш записи по хешу предыдущей: SHA-256 от prevHash и
+// полей записи, каждое из которых предваряется длиной в байтах. Совпадает
+// с SQL-функцией item_history_hash, которой хеш считается при вставке.
+func (e *AuditEntry) ChainHash(prevHash string) string {
+	var b strings.Builder
+	b.WriteString(prevHash)
+	for _, field := range []string{
+		strconv.FormatInt(e.Seq, 10),
+		strconv.FormatInt(e.ItemID, 10),
+		e.Action,
+		string(e.Before),
+		string(e.After),
+		e.Actor,
+		e.RequestID,
+		strconv.FormatInt(e.CreatedAt.UnixMicro(), 10),
+	} {
+		b.WriteString(strconv.Itoa(len(field)))
+		b.WriteByte(':')
+		b.WriteString(field)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+const (
+	AuditBreakSeqGap         = "seq_gap"
+	AuditBreakPrevHash       = "prev_hash_mismatch"
+	AuditBreakHashMismatch   = "hash_mismatch"
+	AuditBreakAnchorMismatch = "anchor_mismatch"
+	AuditBreakTruncated      = "truncated"
+)
+
+// AuditAnchor — сохранённая вне базы голова цепочки из прошлой проверки.
+// Цепочка без ключа не защищает от того, кто пересчитает все хеши заново
+// или отрежет конец журнала; сверка с якорем выявляет и то и другое.
+type AuditAnchor struct {
+	Seq  int64
+	Hash string
+}
+
+var auditAnchorPattern = regexp.MustCompile(`^([1-9][0-9]*):([0-9a-f]{64})$`)
+
+// ParseAuditAnchor разбирает якорь в формате "<seq>:<hash>".
+func ParseAuditAnchor(s string) (*AuditAnchor, error) {
+	m := auditAnchorPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return nil, errors.New("audit anchor must be <seq>:<sha256 hex>")
+	}
+	seq, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditAnchor{Seq: seq, Hash: m[2]}, nil
+}
+
+// AuditBreak — первое нарушенное звено цепочки.
+type AuditBreak struct {
+	Seq     int64
+	EntryID int64
+	Reason  string
+}
+
+// AuditVerification — результат проверки цепочки. HeadSeq и HeadHash
+// описывают последнюю проверенную запись: сохранённые вне БД, они позволяют
+// обнаружить и удаление записей из конца журнала.
+type AuditVerification struct {
+	Valid    bool
+	Checked  int64
+	HeadSeq  int64
+	HeadHash string
+	Broken   *AuditBreak
+}
+
+// AuditFilter — условия выборки журнала. After — Seq записи, после которой
+// продолжается выдача.
+type AuditFilter struct {
+	From  *time.Time
+	To    *time.Time
+	Actor string `validate:"max=255"`
+	After int64  `validate:"gte=0"`
+	Limit int    `validate:"gte=1,lte=1000"`
+}
+
+// AuditMeta — кто и в рамках какого запроса меняет данные.
+type AuditMeta struct {
+	Actor     string
+	RequestID string
+}
+
+type auditMetaKey struct{}
+
+func WithAuditMeta(ctx context.Context, meta AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+// AuditMetaFrom возвращает сведения об авторе изменений из контекста;
+// без них изменения приписываются SystemActor.
+func AuditMetaFrom(ctx context.Context) AuditMeta {
+	meta, _ := ctx.Value(auditMetaKey{}).(AuditMeta)
+	if meta.Actor == "" {
+		meta.Actor = SystemActor
+	}
+	return meta
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// Ожидаемые хеши — результат SQL-функции item_history_hash для тех же
+// значений: before и after заданы в текстовом виде jsonb, actor содержит
+// кириллицу, чтобы длины полей считались в байтах, а не в символах.
+func TestAuditEntryChainHash(t *testing.T) {
+	first := &AuditEntry{
+		Seq:       1,
+		ItemID:    42,
+		Action:    AuditActionCreate,
+		After:     json.RawMessage(`{"amount": 1050, "category": "Еда"}`),
+		Actor:     "Иван",
+		RequestID: "req-1",
+		CreatedAt: time.Date(2024, 3, 1, 12, 34, 56, 789012000, time.UTC),
+	}
+	second := &AuditEntry{
+		Seq:       2,
+		ItemID:    42,
+		Action:    AuditActionUpdate,
+		Before:    json.RawMessage(`{"amount": 1050, "category": "Еда"}`),
+		After:     json.RawMessage(`{"amount": 990, "category": "Еда"}`),
+		Actor:     SystemActor,
+		CreatedAt: time.Date(2024, 3, 1, 15, 35, 0, 0, time.FixedZone("MSK", 3*60*60)),
+	}
+	const (
+		firstHash  = "c1ac12badff990e62a2f3b7deae588c94ea312e6f91669e6d42d01e7520cb378"
+		secondHash = "1a764e81ad1b7ddeb28dcb9b8e6b2b8cc1069e07dd2c9843fb5cf08d66eaee65"
+	)
+	if got := first.ChainHash(AuditGenesisHash); got != firstHash {
+		t.Errorf("first.ChainHash = %s, want %s", got, firstHash)
+	}
+	if got := second.ChainHash(firstHash); got != secondHash {
+		t.Errorf("second.ChainHash = %s, want %s", got, secondHash)
+	}
+}
+
+func TestParseAuditAnchor(t *testing.T) {
+	const hash = "c1ac12badff990e62a2f3b7deae588c94ea312e6f91669e6d42d01e7520cb378"
+	got, err := ParseAuditAnchor(" 811:" + hash + " ")
+	if err != nil {
+		t.Fatalf("ParseAuditAnchor: %v", err)
+	}
+	if got.Seq != 811 || got.Hash != hash {
+		t.Errorf("ParseAuditAnchor = %+v", got)
+	}
+	for _, in := range []string{
+		"",
+		hash,
+		"0:" + hash,
+		"-1:" + hash,
+		"811:" + hash[:63],
+		"811:" + hash + "0",
+		"811:" + hash[:63] + "g",
+		"99999999999999999999:" + hash,
+	} {
+		if _, err := ParseAuditAnchor(in); err == nil {
+			t.Errorf("ParseAuditAnchor(%q): expected error", in)
+		}
+	}
+}
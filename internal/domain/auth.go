@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	customErr "sales-tracker/internal/domain/errors"
+	"slices"
+	"time"
+)
+
+const (
+	AuthMethodAPIKey    = "api_key"
+	AuthMethodJWT       = "jwt"
+	AuthMethodAnonymous = "anonymous"
+	AuthMethodSystem    = "system"
+)
+
+const (
+	RoleViewer     = "viewer"
+	RoleEditor     = "editor"
+	RoleAdmin      = "admin"
+	RoleExporter   = "exporter"
+	RoleAccountant = "accountant"
+)
+
+// Permission — действие, на которое проверяются права в usecase-слое.
+type Permission string
+
+const (
+	PermItemsRead     Permission = "items:read"
+	PermItemsWrite    Permission = "items:write"
+	PermItemsDelete   Permission = "items:delete"
+	PermAnalyticsRead Permission = "analytics:read"
+	PermRatesWrite    Permission = "rates:write"
+	PermExport        Permission = "export"
+	PermAuditRead     Permission = "audit:read"
+	PermKeysManage    Permission = "keys:manage"
+	PermLedgerRead    Permission = "ledger:read"
+	PermLedgerWrite   Permission = "ledger:write"
+	// PermWorkspacesManage не входит ни в одну роль: роли действуют внутри
+	// пространства, а пространства создаются командами CLI от имени system.
+	PermWorkspacesManage Permission = "workspaces:manage"
+	// PermLedgerManage — включение двойной записи в пространстве; как и
+	// PermWorkspacesManage, доступно только командам CLI.
+	PermLedgerManage Permission = "ledger:manage"
+)
+
+// rolePermissions — права ролей. Роли viewer, editor и admin вложены друг
+// в друга; exporter даёт только выгрузку и фоновые отчёты, accountant —
+// чтение данных и двойную запись.
+var rolePermissions = map[string][]Permission{
+	RoleViewer:     {PermItemsRead, PermAnalyticsRead},
+	RoleEditor:     {PermItemsRead, PermAnalyticsRead, PermItemsWrite, PermRatesWrite},
+	RoleAdmin:      {PermItemsRead, PermAnalyticsRead, PermItemsWrite, PermRatesWrite, PermItemsDelete, PermExport, PermAuditRead, PermKeysManage, PermLedgerRead, PermLedgerWrite},
+	RoleExporter:   {PermExport},
+	RoleAccountant: {PermItemsRead, PermAnalyticsRead, PermLedgerRead, PermLedgerWrite},
+}
+
+// Roles — все известные роли.
+var Roles = []string{RoleViewer, RoleEditor, RoleAdmin, RoleExporter, RoleAccountant}
+
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Principal — аутентифицированный субъект запроса. Subject попадает в
+// журнал изменений как автор: "apikey:<id>" для ключей, sub — для JWT.
+// WorkspaceID — пространство, с данными которого работает субъект.
+type Principal struct {
+	Subject     string
+	Name        string
+	Method      string
+	Roles       []string
+	WorkspaceID int64
+}
+
+// Can сообщает, даёт ли хотя бы одна из ролей субъекта право perm.
+// Неизвестные роли (например, из чужого JWT) прав не дают; system может всё.
+func (p *Principal) Can(perm Permission) bool {
+	if p.Method == AuthMethodSystem {
+		return true
+	}
+	for _, role := range p.Roles {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize проверяет право субъекта из контекста: без субъекта —
+// ErrUnauthorized, без права — ErrForbidden.
+func Authorize(ctx context.Context, perm Permission) error {
+	p := PrincipalFrom(ctx)
+	if p == nil {
+		return customErr.ErrUnauthorized
+	}
+	if !p.Can(perm) {
+		return fmt.Errorf("%w: %s lacks %s", customErr.ErrForbidden, p.Subject, perm)
+	}
+	return nil
+}
+
+// SystemPrincipal — субъект команд CLI и фоновых задач; имеет все права.
+var SystemPrincipal = &Principal{Subject: SystemActor, Name: SystemActor, Method: AuthMethodSystem, Roles: []string{RoleAdmin}}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom возвращает субъект запроса или nil, если запрос не
+// аутентифицирован.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// APIKey — ключ доступа для скриптов. Сам ключ показывается один раз при
+// создании; хранятся только его префикс для поиска и SHA-256.
+type APIKey struct {
+	ID          int64
+	WorkspaceID int64
+	Name        string `validate:"required,max=255"`
+	Prefix      string
+	Hash        string
+	Roles       []string `validate:"required,min=1,dive,required,max=64"`
+	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+// Active сообщает, можно ли аутентифицироваться ключом в момент now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -4,14 +4,34 @@ import "errors"
 
 // Бизнес-ошибки
 var (
-	ErrItemNotFound      = errors.New("item not found")
-	ErrInvalidItemType   = errors.New("invalid item type")
-	ErrInvalidAmount     = errors.New("invalid amount")
-	ErrInvalidDateRange  = errors.New("invalid date range")
-	ErrInvalidInput      = errors.New("invalid input")
-	ErrMissingParameter  = errors.New("missing required parameter")
-	ErrUnsupportedFormat = errors.New("unsupported date format")
-	ErrPeriodTooLarge    = errors.New("date range exceeds maximum allowed period")
+	ErrItemNotFound          = errors.New("item not found")
+	ErrInvalidItemType       = errors.New("invalid item type")
+	ErrInvalidAmount         = errors.New("invalid amount")
+	ErrInvalidDateRange      = errors.New("invalid date range")
+	ErrInvalidInput          = errors.New("invalid input")
+	ErrMissingParameter      = errors.New("missing required parameter")
+	ErrUnsupportedFormat     = errors.New("unsupported date format")
+	ErrPeriodTooLarge        = errors.New("date range exceeds maximum allowed period")
+	ErrRateNotFound          = errors.New("exchange rate not found")
+	ErrReportNotFound        = errors.New("report not found")
+	ErrReportNotReady        = errors.New("report is not ready")
+	ErrReportExpired         = errors.New("report has expired")
+	ErrIdempotencyKey        = errors.New("idempotency key reused with a different request")
+	ErrVersionMismatch       = errors.New("item version does not match")
+	ErrUnauthorized          = errors.New("authentication required")
+	ErrForbidden             = errors.New("access denied")
+	ErrAPIKeyNotFound        = errors.New("api key not found")
+	ErrAPIKeyPrefixTaken     = errors.New("api key prefix already taken")
+	ErrWorkspaceNotFound     = errors.New("workspace not found")
+	ErrWorkspaceExists       = errors.New("workspace already exists")
+	ErrAccountNotFound       = errors.New("account not found")
+	ErrAccountExists         = errors.New("account already exists")
+	ErrAccountInUse          = errors.New("account has items")
+	ErrLedgerDisabled        = errors.New("ledger is not enabled")
+	ErrLedgerAccountNotFound = errors.New("ledger account not found")
+	ErrLedgerAccountExists   = errors.New("ledger account already exists")
+	ErrLedgerAccountInUse    = errors.New("ledger account has postings")
+	ErrJournalEntryNotFound  = errors.New("journal entry not found")
 )
 
 // Технические ошибки
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// BaseCurrency — валюта, к которой хранятся курсы в exchange_rates.
+const BaseCurrency = "RUB"
+
+type ExchangeRate struct {
+	Currency  string    `validate:"required,iso4217"`
+	Date      time.Time `validate:"required"`
+	Rate      string    `validate:"required,numeric"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
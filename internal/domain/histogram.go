@@ -0,0 +1,42 @@
+package domain
+
+import "math"
+
+const (
+	HistogramFixed = "fixed"
+	HistogramLog   = "log"
+)
+
+type HistogramBin struct {
+	From  Money
+	To    Money
+	Count int64
+}
+
+// HistogramEdges возвращает нижние границы корзин для сумм из [lo, hi].
+// Для шкалы log границы растут геометрически начиная с одной копейки, поэтому
+// нулевые суммы попадают в первую корзину. Совпадающие после округления
+// до копейки границы схлопываются, так что корзин может оказаться меньше bins.
+func HistogramEdges(scale string, lo, hi Money, bins int) []Money {
+	if bins <= 0 || hi < lo {
+		return nil
+	}
+	if hi == lo {
+		return []Money{lo}
+	}
+	edges := make([]Money, 0, bins)
+	edges = append(edges, lo)
+	for i := 1; i < bins; i++ {
+		var edge Money
+		if scale == HistogramLog {
+			start := math.Max(float64(lo), 1)
+			edge = Money(math.Round(start * math.Pow(float64(hi)/start, float64(i)/float64(bins))))
+		} else {
+			edge = lo + Money(int64(hi-lo)*int64(i)/int64(bins))
+		}
+		if edge > edges[len(edges)-1] && edge < hi {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
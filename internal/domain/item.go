@@ -1,14 +1,102 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	customErr "sales-tracker/internal/domain/errors"
+	"time"
+)
 
+const (
+	ItemTypeIncome  = "income"
+	ItemTypeExpense = "expense"
+	// ItemTypeTransfer — перемещение денег со счёта AccountID на ToAccountID.
+	// Переводы не являются ни доходом, ни расходом и не входят в аналитику.
+	ItemTypeTransfer = "transfer"
+)
+
+// Item — операция. AccountID — счёт, на котором она отражается (для
+// перевода — счёт списания); у операций, заведённых до появления счетов,
+// он может быть пустым.
 type Item struct {
 	ID          int64
-	Type        string    `validate:"required,oneof=income expense"`
-	Amount      float64   `validate:"gte=0"`
+	Type        string    `validate:"required,oneof=income expense transfer"`
+	Amount      Money     `validate:"gte=0"`
+	Currency    string    `validate:"required,iso4217"`
 	Date        time.Time `validate:"required"`
 	Category    string
 	Description string
+	ExternalID  string
+	AccountID   *int64 `validate:"omitempty,gt=0"`
+	ToAccountID *int64 `validate:"omitempty,gt=0"`
+	Version     int64
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	DeletedAt   *time.Time
+}
+
+// ValidateAccounts проверяет связь операции со счетами: перевод требует
+// двух разных счетов, у дохода и расхода нет счёта зачисления. Существование
+// счетов и совпадение валют проверяет БД.
+func (i *Item) ValidateAccounts() error {
+	if i.Type == ItemTypeTransfer {
+		if i.AccountID == nil || i.ToAccountID == nil {
+			return fmt.Errorf("%w: transfer requires account_id and to_account_id", customErr.ErrInvalidInput)
+		}
+		if *i.AccountID == *i.ToAccountID {
+			return fmt.Errorf("%w: transfer accounts must differ", customErr.ErrInvalidInput)
+		}
+		return nil
+	}
+	if i.ToAccountID != nil {
+		return fmt.Errorf("%w: to_account_id is allowed only for transfers", customErr.ErrInvalidInput)
+	}
+	return nil
+}
+
+// ItemPatch — частичное изменение операции (JSON Merge Patch). nil-поле
+// не меняется; указатель на пустое значение (для счетов — на 0) очищает поле.
+type ItemPatch struct {
+	Type        *string
+	Amount      *Money
+	Currency    *string
+	Date        *time.Time
+	Category    *string
+	Description *string
+	AccountID   *int64
+	ToAccountID *int64
+}
+
+// Apply переносит заданные в патче поля в операцию.
+func (p *ItemPatch) Apply(item *Item) {
+	if p.Type != nil {
+		item.Type = *p.Type
+	}
+	if p.Amount != nil {
+		item.Amount = *p.Amount
+	}
+	if p.Currency != nil {
+		item.Currency = *p.Currency
+	}
+	if p.Date != nil {
+		item.Date = *p.Date
+	}
+	if p.Category != nil {
+		item.Category = *p.Category
+	}
+	if p.Description != nil {
+		item.Description = *p.Description
+	}
+	if p.AccountID != nil {
+		item.AccountID = accountRef(*p.AccountID)
+	}
+	if p.ToAccountID != nil {
+		item.ToAccountID = accountRef(*p.ToAccountID)
+	}
+}
+
+func accountRef(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
 }
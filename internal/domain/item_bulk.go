@@ -0,0 +1,70 @@
+package domain
+
+const (
+	BulkOpCreate = "create"
+	BulkOpUpdate = "update"
+	BulkOpDelete = "delete"
+)
+
+// BulkOperation — одна операция пакетного запроса. Item нужен для create и
+// update (полная замена записи), Version > 0 включает проверку версии для
+// update и delete.
+type BulkOperation struct {
+	Op      string `validate:"required,oneof=create update delete"`
+	ID      int64  `validate:"gte=0"`
+	Version int64  `validate:"gte=0"`
+	Item    *Item
+}
+
+type BulkOptions struct {
+	// Atomic — все операции выполняются одной транзакцией: ошибка любой
+	// отменяет остальные.
+	Atomic bool
+}
+
+// BulkOpResult — итог операции: ID и версия записи после изменения либо ошибка.
+// RolledBack означает, что операция прошла бы, но отменена вместе с транзакцией,
+// NotExecuted — что неатомарный пакет прервался до этой операции.
+type BulkOpResult struct {
+	Index       int
+	Op          string
+	ID          int64
+	Version     int64
+	Err         error
+	RolledBack  bool
+	NotExecuted bool
+}
+
+type BulkResult struct {
+	Atomic    bool
+	Succeeded int
+	Failed    int
+	// Rejected — атомарный пакет не применён из-за ошибки в одной из операций.
+	Rejected bool
+	Results  []*BulkOpResult
+}
+
+// Reject помечает атомарный пакет отклонённым: операции без собственной
+// ошибки считаются отменёнными.
+func (r *BulkResult) Reject() {
+	r.Rejected = true
+	for _, res := range r.Results {
+		if res.Err == nil {
+			res.RolledBack = true
+		}
+	}
+}
+
+// BulkOpError — ошибка операции пакета, выполняемого одной транзакцией.
+type BulkOpError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkOpError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BulkOpError) Unwrap() error {
+	return e.Err
+}
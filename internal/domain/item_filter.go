@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// ItemsFilter — условия выборки списка операций. Пустые поля не ограничивают выборку.
+type ItemsFilter struct {
+	Type string `validate:"omitempty,oneof=income expense transfer"`
+	// AccountID отбирает операции, затрагивающие счёт, в том числе переводы на него.
+	AccountID  int64 `validate:"gte=0"`
+	Categories []string
+	From       *time.Time
+	To         *time.Time
+	MinAmount  *Money `validate:"omitempty,gte=0"`
+	MaxAmount  *Money `validate:"omitempty,gte=0"`
+	Search     string `validate:"max=200"`
+	SortBy     string `validate:"omitempty,oneof=date amount type category created_at updated_at id"`
+	SortOrder  string `validate:"omitempty,oneof=asc desc"`
+}
+
+// HasConditions сообщает, ограничивает ли фильтр выборку; сортировка не учитывается.
+func (f *ItemsFilter) HasConditions() bool {
+	return f.Type != "" || len(f.Categories) > 0 || f.AccountID != 0 || f.From != nil || f.To != nil ||
+		f.MinAmount != nil || f.MaxAmount != nil || f.Search != ""
+}
+
+// ItemsCursor — позиция в списке, отсортированном по (date, id).
+// Backward означает запрос страницы, предшествующей позиции.
+type ItemsCursor struct {
+	Date     time.Time
+	ID       int64
+	Backward bool
+}
+
+type ItemsPage struct {
+	Items      []*Item
+	NextCursor *ItemsCursor
+	PrevCursor *ItemsCursor
+	Total      *int64
+}
@@ -0,0 +1,62 @@
+package domain
+
+// ImportRow — строка файла импорта после разбора. Err заполняется,
+// если строку не удалось разобрать; такая строка попадает в отчёт об ошибках.
+// SkipReason — строка намеренно не импортируется (например, неподтверждённая
+// банковская операция).
+type ImportRow struct {
+	Line       int
+	Item       *Item
+	Err        error
+	SkipReason string
+}
+
+type ImportOptions struct {
+	// DryRun — только проверить строки, ничего не записывая.
+	DryRun bool
+	// Atomic — «всё или ничего»: при любой ошибке не записывается ни одна строка.
+	Atomic    bool
+	BatchSize int `validate:"gte=0,lte=5000"`
+	// AccountID — счёт, к которому привязываются загружаемые операции; 0 — без счёта.
+	AccountID int64 `validate:"gte=0"`
+}
+
+type ImportRowError struct {
+	Line  int
+	Error string
+}
+
+// ImportSkip — строка, пропущенная без ошибки: операция уже загружена
+// или повторяется в файле.
+type ImportSkip struct {
+	Line       int
+	ExternalID string
+	Reason     string
+}
+
+type ImportResult struct {
+	Total    int
+	Valid    int
+	Imported int
+	Failed   int
+	DryRun   bool
+	// Rejected — атомарный импорт отклонён из-за ошибок в строках.
+	Rejected bool
+	Errors   []*ImportRowError
+	Skipped  []*ImportSkip
+}
+
+const (
+	ImportSkipDuplicate = "duplicate_in_file"
+	ImportSkipExisting  = "already_imported"
+	ImportSkipNotBooked = "not_booked"
+)
+
+func (r *ImportResult) AddSkip(line int, externalID, reason string) {
+	r.Skipped = append(r.Skipped, &ImportSkip{Line: line, ExternalID: externalID, Reason: reason})
+}
+
+func (r *ImportResult) AddError(line int, err error) {
+	r.Failed++
+	r.Errors = append(r.Errors, &ImportRowError{Line: line, Error: err.Error()})
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"fmt"
+	customErr "sales-tracker/internal/domain/errors"
+	"time"
+)
+
+// Типы счетов плана счетов. Активы и расходы имеют дебетовое сальдо,
+// обязательства, капитал и доходы — кредитовое.
+const (
+	LedgerAsset     = "asset"
+	LedgerLiability = "liability"
+	LedgerEquity    = "equity"
+	LedgerIncome    = "income"
+	LedgerExpense   = "expense"
+)
+
+// Источники проводок: item и opening ведёт БД по операциям и начальным
+// остаткам счетов, manual вводит бухгалтер.
+const (
+	JournalSourceItem    = "item"
+	JournalSourceOpening = "opening"
+	JournalSourceManual  = "manual"
+)
+
+// LedgerAccount — счёт плана счетов. System — роль счёта по умолчанию
+// (cash, income, expense, equity), AccountID — связанный денежный счёт,
+// Category — категория операций, доходы или расходы которой учитываются
+// на этом счёте вместо системного.
+type LedgerAccount struct {
+	ID        int64
+	Code      string `validate:"required,max=20"`
+	Name      string `validate:"required,max=255"`
+	Type      string `validate:"required,oneof=asset liability equity income expense"`
+	System    string
+	AccountID *int64
+	Category  string `validate:"max=100"`
+	CreatedAt time.Time
+}
+
+// DebitNormal сообщает, растёт ли сальдо счёта по дебету.
+func (a *LedgerAccount) DebitNormal() bool {
+	return a.Type == LedgerAsset || a.Type == LedgerExpense
+}
+
+// Posting — строка проводки: сумма по дебету или по кредиту счёта.
+type Posting struct {
+	LedgerAccountID int64 `validate:"gt=0"`
+	Debit           Money `validate:"gte=0"`
+	Credit          Money `validate:"gte=0"`
+}
+
+// JournalEntry — проводка журнала. Все её строки в одной валюте, а суммы
+// по дебету и кредиту равны.
+type JournalEntry struct {
+	ID          int64
+	Date        time.Time `validate:"required"`
+	Currency    string    `validate:"required,iso4217"`
+	Description string
+	Source      string
+	ItemID      *int64
+	AccountID   *int64
+	CreatedAt   time.Time
+	Postings    []*Posting `validate:"min=2,dive,required"`
+}
+
+// ValidateBalance проверяет, что каждая строка задаёт ровно одну сумму
+// и что дебет проводки равен кредиту.
+func (e *JournalEntry) ValidateBalance() error {
+	var debit, credit Money
+	for i, p := range e.Postings {
+		if (p.Debit > 0) == (p.Credit > 0) {
+			return fmt.Errorf("%w: posting #%d must have either debit or credit", customErr.ErrInvalidInput, i)
+		}
+		debit += p.Debit
+		credit += p.Credit
+	}
+	if debit != credit {
+		return fmt.Errorf("%w: debit %s does not equal credit %s", customErr.ErrInvalidInput, debit, credit)
+	}
+	return nil
+}
+
+// JournalFilter — период и страница журнала. Пустые From и To не
+// ограничивают период.
+type JournalFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Offset int `validate:"gte=0"`
+	Limit  int `validate:"gte=1,lte=1000"`
+}
+
+// LedgerLine — оборот или сальдо счёта в отчёте.
+type LedgerLine struct {
+	Account *LedgerAccount
+	Amount  Money
+}
+
+// TrialBalanceRow — обороты счёта в одной валюте.
+type TrialBalanceRow struct {
+	Account  *LedgerAccount
+	Currency string
+	Debit    Money
+	Credit   Money
+}
+
+// TrialBalance — оборотно-сальдовая ведомость в одной валюте. Проводки
+// не пересчитываются между валютами, поэтому ведомость строится по каждой
+// валюте отдельно и в каждой сходится.
+type TrialBalance struct {
+	Currency    string
+	Rows        []*TrialBalanceRow
+	TotalDebit  Money
+	TotalCredit Money
+}
+
+func (t *TrialBalance) Balanced() bool {
+	return t.TotalDebit == t.TotalCredit
+}
+
+// BalanceSheet — баланс в одной валюте на дату. RetainedEarnings — доходы
+// минус расходы за всё время: пока период не закрыт, они не перенесены
+// на счёт капитала.
+type BalanceSheet struct {
+	Currency         string
+	Assets           []*LedgerLine
+	Liabilities      []*LedgerLine
+	Equity           []*LedgerLine
+	RetainedEarnings Money
+	TotalAssets      Money
+	TotalLiabilities Money
+	TotalEquity      Money
+}
+
+// Balanced проверяет уравнение баланса: активы = обязательства + капитал.
+func (b *BalanceSheet) Balanced() bool {
+	return b.TotalAssets == b.TotalLiabilities+b.TotalEquity
+}
+
+// ProfitAndLossParams — период и валюта отчёта о прибылях и убытках; правила
+// те же, что у аналитики.
+type ProfitAndLossParams struct {
+	From     time.Time
+	To       time.Time
+	Currency string `validate:"required,iso4217"`
+}
+
+// ProfitAndLoss — доходы и расходы за период по счетам, пересчитанные
+// в Currency по курсу на дату проводки, как в аналитике.
+type ProfitAndLoss struct {
+	Currency       string
+	From           time.Time
+	To             time.Time
+	Income         []*LedgerLine
+	Expense        []*LedgerLine
+	TotalIncome    Money
+	TotalExpense   Money
+	Reconciliation *LedgerReconciliation
+}
+
+func (p *ProfitAndLoss) NetIncome() Money {
+	return p.TotalIncome - p.TotalExpense
+}
+
+// LedgerReconciliation сверяет отчёт с аналитикой операций: доходы и
+// расходы учёта без ручных проводок должны совпасть с ней до копейки.
+// IncomeDifference и ExpenseDifference — расхождения после вычета ручных проводок.
+type LedgerReconciliation struct {
+	AnalyticsIncome   Money
+	AnalyticsExpense  Money
+	ManualIncome      Money
+	ManualExpense     Money
+	IncomeDifference  Money
+	ExpenseDifference Money
+}
+
+func (r *LedgerReconciliation) Reconciled() bool {
+	return r.IncomeDifference == 0 && r.ExpenseDifference == 0
+}
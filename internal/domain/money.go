@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Money — денежная сумма в минимальных единицах (копейках).
+// Целочисленное хранение исключает ошибки округления float64:
+// колонка items.amount имеет тип DECIMAL(10,2) и читается без потерь.
+//
+// Правило округления: значения с точностью больше двух знаков
+// округляются до копейки half away from zero — так же, как ROUND(numeric, 2)
+// в PostgreSQL.
+type Money int64
+
+const moneyScale = 100
+
+// decimalPattern — запись суммы, которую принимает ParseMoney. big.Rat сам по
+// себе понимает и дроби ("1/3"), и шестнадцатеричные и двоичные литералы ("0x10").
+var decimalPattern = regexp.MustCompile(`^[+-]?\d+(\.\d+)?([eE][+-]?\d+)?$`)
+
+// ParseMoney разбирает десятичную запись суммы ("1234.5", "-0.01", "1e3")
+// без промежуточного перевода во float64.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty money value")
+	}
+	if !decimalPattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid money value %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid money value %q", s)
+	}
+	return moneyFromRat(r)
+}
+
+func moneyFromRat(r *big.Rat) (Money, error) {
+	scaled := new(big.Rat).Mul(r, big.NewRat(moneyScale, 1))
+	num := new(big.Int).Abs(scaled.Num())
+	den := scaled.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if scaled.Sign() < 0 {
+		q.Neg(q)
+	}
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("money value %s out of range", r.FloatString(2))
+	}
+	return Money(q.Int64()), nil
+}
+
+// String форматирует сумму с двумя знаками после точки: "1234.50".
+func (m Money) String() string {
+	v := int64(m)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	u := uint64(v)
+	if v < 0 {
+		u = uint64(-v)
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, u/moneyScale, u%moneyScale)
+}
+
+// Scan реализует sql.Scanner для NUMERIC/DECIMAL колонок.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case int64:
+		if v > math.MaxInt64/moneyScale || v < math.MinInt64/moneyScale {
+			return fmt.Errorf("money value %d out of range", v)
+		}
+		*m = Money(v * moneyScale)
+		return nil
+	case float64:
+		parsed, err := ParseMoney(strconv.FormatFloat(v, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+}
+
+// Value реализует driver.Valuer: сумма передаётся в БД десятичной строкой.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// MarshalJSON кодирует сумму строкой, чтобы клиенты не теряли точность.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON принимает как строку ("10.50"), так и число (10.5).
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{"0", 0},
+		{"10", 1000},
+		{"10.5", 1050},
+		{"-0.01", -1},
+		{"+3.10", 310},
+		{" 7.25 ", 725},
+		{"1e3", 100000},
+		{"1.5E-1", 15},
+		// Округление до копейки half away from zero, как ROUND(numeric, 2).
+		{"0.005", 1},
+		{"-0.005", -1},
+		{"0.004", 0},
+		{"-0.004", 0},
+		{"1.235", 124},
+		{"1.2349999", 123},
+		{"92233720368547758.07", math.MaxInt64},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in)
+		if err != nil {
+			t.Errorf("ParseMoney(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoneyRejects(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"   ",
+		"abc",
+		"1/3",
+		"0x10",
+		"0X1p4",
+		"0b101",
+		"0o17",
+		"1_000",
+		".5",
+		"5.",
+		"1,50",
+		"1e",
+		"Inf",
+		"NaN",
+		"92233720368547758.08",
+		"-92233720368547758.09",
+		"1e30",
+	} {
+		if got, err := ParseMoney(in); err == nil {
+			t.Errorf("ParseMoney(%q) = %d, want error", in, got)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{123456, "1234.56"},
+		{-100, "-1.00"},
+		{math.MinInt64, "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(tt.in), got, tt.want)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want Money
+	}{
+		{nil, 0},
+		{[]byte("12.34"), 1234},
+		{"0.005", 1},
+		{int64(42), 4200},
+		{int64(-42), -4200},
+		{int64(math.MaxInt64 / 100), math.MaxInt64 / 100 * 100},
+		{float64(0.1), 10},
+	}
+	for _, tt := range tests {
+		var m Money
+		if err := m.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%v): %v", tt.src, err)
+			continue
+		}
+		if m != tt.want {
+			t.Errorf("Scan(%v) = %d, want %d", tt.src, m, tt.want)
+		}
+	}
+	for _, src := range []any{int64(math.MaxInt64/100 + 1), int64(math.MinInt64/100 - 1), "0x10", true} {
+		var m Money
+		if err := m.Scan(src); err == nil {
+			t.Errorf("Scan(%v) = %d, want error", src, m)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var item struct {
+		Amount Money `json:"amount"`
+	}
+	for in, want := range map[string]Money{
+		`{"amount":"10.50"}`: 1050,
+		`{"amount":10.5}`:    1050,
+		`{"amount":0.005}`:   1,
+	} {
+		if err := json.Unmarshal([]byte(in), &item); err != nil {
+			t.Errorf("Unmarshal(%s): %v", in, err)
+			continue
+		}
+		if item.Amount != want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", in, item.Amount, want)
+		}
+	}
+	for _, in := range []string{`{"amount":"0x10"}`, `{"amount":"1/3"}`, `{"amount":true}`} {
+		if err := json.Unmarshal([]byte(in), &item); err == nil {
+			t.Errorf("Unmarshal(%s): want error", in)
+		}
+	}
+	data, err := json.Marshal(Money(-1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"-0.01"` {
+		t.Errorf("Marshal(-1) = %s, want \"-0.01\"", data)
+	}
+}
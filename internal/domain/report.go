@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+const (
+	ReportKindAnalytics = "analytics"
+	ReportKindExport    = "export"
+)
+
+const (
+	ReportStatusQueued  = "queued"
+	ReportStatusRunning = "running"
+	ReportStatusDone    = "done"
+	ReportStatusFailed  = "failed"
+	ReportStatusExpired = "expired"
+)
+
+// ReportJob — фоновая задача построения аналитики или экспорта.
+// Готовый результат хранится в файле ArtifactPath до ExpiresAt.
+// Отчёт строится по данным пространства WorkspaceID.
+type ReportJob struct {
+	ID           string
+	WorkspaceID  int64
+	Kind         string `validate:"required,oneof=analytics export"`
+	Params       *AnalyticsParams
+	Status       string
+	Progress     int
+	Error        string
+	ArtifactPath string
+	ArtifactName string
+	ContentType  string
+	CreatedAt    time.Time
+	StartedAt    *time.Time
+	FinishedAt   *time.Time
+	ExpiresAt    *time.Time
+}
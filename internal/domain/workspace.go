@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultWorkspaceID — пространство, в которое перенесены данные, созданные
+// до появления пространств; в нём же работает API без аутентификации.
+const DefaultWorkspaceID int64 = 1
+
+// DefaultWorkspaceSlug — slug пространства DefaultWorkspaceID.
+const DefaultWorkspaceSlug = "default"
+
+// Workspace — рабочее пространство команды. Операции, история, ключи и
+// фоновые отчёты принадлежат ровно одному пространству.
+type Workspace struct {
+	ID        int64
+	Slug      string `validate:"required,max=64,hostname_rfc1123"`
+	Name      string `validate:"required,max=255"`
+	CreatedAt time.Time
+}
+
+type workspaceKey struct{}
+
+type allWorkspacesKey struct{}
+
+// WithWorkspace задаёт пространство, к которому относятся запросы к данным.
+func WithWorkspace(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, id)
+}
+
+func WorkspaceFrom(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(workspaceKey{}).(int64)
+	return id, ok
+}
+
+// WithAllWorkspaces открывает доступ ко всем пространствам. Используется
+// только фоновыми задачами, которые обслуживают все пространства сразу
+// (очистка корзины, очередь отчётов), и поиском API-ключа до того, как
+// пространство известно. Пространство из WithWorkspace имеет приоритет.
+func WithAllWorkspaces(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allWorkspacesKey{}, true)
+}
+
+func IsAllWorkspaces(ctx context.Context) bool {
+	all, _ := ctx.Value(allWorkspacesKey{}).(bool)
+	return all
+}
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+type analyticsRecord struct {
+	From       time.Time         `json:"from"`
+	To         time.Time         `json:"to"`
+	Currency   string            `json:"currency"`
+	Income     *statsRecord      `json:"income"`
+	Expense    *statsRecord      `json:"expense"`
+	Comparison *comparisonRecord `json:"comparison,omitempty"`
+}
+
+type statsRecord struct {
+	Sum         domain.Money            `json:"sum"`
+	Avg         domain.Money            `json:"avg"`
+	Count       int64                   `json:"count"`
+	Median      domain.Money            `json:"median"`
+	Percent90   domain.Money            `json:"percent90"`
+	Percentiles map[string]domain.Money `json:"percentiles,omitempty"`
+	Histogram   []*histogramBinRecord   `json:"histogram,omitempty"`
+}
+
+type histogramBinRecord struct {
+	From  domain.Money `json:"from"`
+	To    domain.Money `json:"to"`
+	Count int64        `json:"count"`
+}
+
+type comparisonRecord struct {
+	Mode    string       `json:"mode"`
+	From    time.Time    `json:"from"`
+	To      time.Time    `json:"to"`
+	Income  *statsRecord `json:"income"`
+	Expense *statsRecord `json:"expense"`
+}
+
+// WriteAnalyticsJSON пишет сводную аналитику без детализации операций.
+func WriteAnalyticsJSON(w io.Writer, analytics *domain.Analytics, from, to time.Time) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(newAnalyticsRecord(analytics, from, to))
+}
+
+func newAnalyticsRecord(analytics *domain.Analytics, from, to time.Time) analyticsRecord {
+	record := analyticsRecord{
+		From:     from,
+		To:       to,
+		Currency: analytics.Currency,
+		Income:   toStatsRecord(analytics.Income),
+		Expense:  toStatsRecord(analytics.Expense),
+	}
+	if c := analytics.Comparison; c != nil {
+		record.Comparison = &comparisonRecord{
+			Mode:    c.Mode,
+			From:    c.From,
+			To:      c.To,
+			Income:  toStatsRecord(c.Previous.Income),
+			Expense: toStatsRecord(c.Previous.Expense),
+		}
+	}
+	return record
+}
+
+func toStatsRecord(stats *domain.ItemAnalytics) *statsRecord {
+	record := &statsRecord{
+		Sum:       stats.Sum,
+		Avg:       stats.Avg,
+		Count:     stats.Count,
+		Median:    stats.Median,
+		Percent90: stats.Percent90,
+	}
+	if len(stats.Percentiles) > 0 {
+		record.Percentiles = make(map[string]domain.Money, len(stats.Percentiles))
+		for p, value := range stats.Percentiles {
+			record.Percentiles[strconv.FormatFloat(p, 'f', -1, 64)] = value
+		}
+	}
+	for _, bin := range stats.Histogram {
+		record.Histogram = append(record.Histogram, &histogramBinRecord{
+			From:  bin.From,
+			To:    bin.To,
+			Count: bin.Count,
+		})
+	}
+	return record
+}
@@ -0,0 +1,170 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+// CSVReport пишет отчёт SalesTracker в CSV для Excel: UTF-8 с BOM,
+// разделитель — точка с запятой. Сначала записывается сводка,
+// затем операции по одной.
+type CSVReport struct {
+	w        *csv.Writer
+	currency string
+	header   bool
+}
+
+func NewCSVReport(w io.Writer) (*CSVReport, error) {
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return nil, err
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+	writer.UseCRLF = true
+	return &CSVReport{w: writer}, nil
+}
+
+func (r *CSVReport) WriteSummary(analytics *domain.Analytics, from, to time.Time) error {
+	r.currency = analytics.Currency
+	rows := [][]string{
+		{"ОТЧЁТ SalesTracker"},
+		{fmt.Sprintf("Период: %s - %s",
+			from.Format("02.01.2006 15:04"),
+			to.Format("02.01.2006 15:04"))},
+		{fmt.Sprintf("Валюта: %s", analytics.Currency)},
+		{""},
+	}
+	rows = append(rows, r.statsRows("АНАЛИТИКА ДОХОДОВ", analytics.Income)...)
+	rows = append(rows, r.statsRows("АНАЛИТИКА РАСХОДОВ", analytics.Expense)...)
+	if analytics.Comparison != nil {
+		rows = append(rows, r.comparisonRows(analytics)...)
+		rows = append(rows, []string{""})
+	}
+	return r.w.WriteAll(rows)
+}
+
+func (r *CSVReport) writeItemsHeader() {
+	if r.header {
+		return
+	}
+	r.header = true
+	r.w.Write([]string{"ОПЕРАЦИИ"})
+	r.w.Write([]string{"ID", "Тип", "Сумма", "Валюта", "Дата", "Категория", "Описание", "Создано", "Обновлено"})
+}
+
+func (r *CSVReport) WriteItem(item *domain.Item) error {
+	r.writeItemsHeader()
+	return r.w.Write([]string{
+		strconv.FormatInt(item.ID, 10),
+		TypeLabel(item.Type),
+		item.Amount.String(),
+		item.Currency,
+		item.Date.Format("02.01.2006 15:04"),
+		item.Category,
+		item.Description,
+		item.CreatedAt.Format("02.01.2006 15:04"),
+		item.UpdatedAt.Format("02.01.2006 15:04"),
+	})
+}
+
+// Close дописывает заголовок таблицы операций, если операций не было, и сбрасывает буфер.
+func (r *CSVReport) Close() error {
+	r.writeItemsHeader()
+	return r.Flush()
+}
+
+func (r *CSVReport) Flush() error {
+	r.w.Flush()
+	return r.w.Error()
+}
+
+func (r *CSVReport) money(m domain.Money) string {
+	return m.String() + " " + CurrencyLabel(r.currency)
+}
+
+func (r *CSVReport) statsRows(title string, stats *domain.ItemAnalytics) [][]string {
+	return [][]string{
+		{title},
+		{"Сумма", "Среднее", "Количество", "Медиана", "90-й перцентиль"},
+		{
+			r.money(stats.Sum),
+			r.money(stats.Avg),
+			strconv.FormatInt(stats.Count, 10),
+			r.money(stats.Median),
+			r.money(stats.Percent90),
+		},
+		{""},
+	}
+}
+
+func (r *CSVReport) comparisonRows(analytics *domain.Analytics) [][]string {
+	c := analytics.Comparison
+	title := "СРАВНЕНИЕ С ПРЕДЫДУЩИМ ПЕРИОДОМ"
+	if c.Mode == domain.ComparePreviousYear {
+		title = "СРАВНЕНИЕ С ПРОШЛЫМ ГОДОМ"
+	}
+	pct := func(p *float64) string {
+		if p == nil {
+			return "—"
+		}
+		return strconv.FormatFloat(*p, 'f', 2, 64) + "%"
+	}
+
+	rows := [][]string{
+		{title},
+		{fmt.Sprintf("Период сравнения: %s - %s",
+			c.From.Format("02.01.2006 15:04"),
+			c.To.Format("02.01.2006 15:04"))},
+		{"Показатель", "Текущий период", "Период сравнения", "Изменение", "Изменение, %"},
+	}
+	for _, block := range []struct {
+		name      string
+		cur, prev *domain.ItemAnalytics
+		delta     *domain.ItemAnalyticsDelta
+	}{
+		{"Доходы", analytics.Income, c.Previous.Income, c.Income},
+		{"Расходы", analytics.Expense, c.Previous.Expense, c.Expense},
+	} {
+		rows = append(rows,
+			[]string{block.name + ": сумма", r.money(block.cur.Sum), r.money(block.prev.Sum), r.money(block.delta.Sum.Abs), pct(block.delta.Sum.Percent)},
+			[]string{block.name + ": среднее", r.money(block.cur.Avg), r.money(block.prev.Avg), r.money(block.delta.Avg.Abs), pct(block.delta.Avg.Percent)},
+			[]string{
+				block.name + ": количество",
+				strconv.FormatInt(block.cur.Count, 10),
+				strconv.FormatInt(block.prev.Count, 10),
+				strconv.FormatInt(block.delta.Count.Abs, 10),
+				pct(block.delta.Count.Percent),
+			},
+			[]string{block.name + ": медиана", r.money(block.cur.Median), r.money(block.prev.Median), r.money(block.delta.Median.Abs), pct(block.delta.Median.Percent)},
+			[]string{block.name + ": 90-й перцентиль", r.money(block.cur.Percent90), r.money(block.prev.Percent90), r.money(block.delta.Percent90.Abs), pct(block.delta.Percent90.Percent)},
+		)
+	}
+	return rows
+}
+
+func TypeLabel(typeStr string) string {
+	switch typeStr {
+	case "income":
+		return "Доход"
+	case "transfer":
+		return "Перевод"
+	}
+	return "Расход"
+}
+
+func CurrencyLabel(currency string) string {
+	switch currency {
+	case "RUB":
+		return "₽"
+	case "USD":
+		return "$"
+	case "EUR":
+		return "€"
+	}
+	return currency
+}
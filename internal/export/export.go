@@ -0,0 +1,105 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatXLSX  = "xlsx"
+	FormatJSONL = "jsonl"
+)
+
+const (
+	// LayoutReport — отчёт для людей: русские подписи, сводка над операциями.
+	LayoutReport = "report"
+	// LayoutFlat — выгрузка для машин: одна строка заголовка, ISO-даты, суммы без валюты.
+	LayoutFlat = "flat"
+)
+
+// Writer пишет экспорт: сначала сводку, затем операции по одной.
+// Flush отправляет накопленные данные, если формат это допускает.
+type Writer interface {
+	WriteSummary(analytics *domain.Analytics, from, to time.Time) error
+	WriteItem(item *domain.Item) error
+	Flush() error
+	Close() error
+}
+
+// Validate проверяет формат и раскладку до того, как начнётся запись ответа.
+func Validate(format, layout string) error {
+	switch format {
+	case FormatCSV, FormatXLSX, FormatJSONL:
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+	switch layout {
+	case LayoutReport, LayoutFlat:
+	default:
+		return fmt.Errorf("unsupported export layout %q", layout)
+	}
+	return nil
+}
+
+// New создаёт Writer для сочетания формата и раскладки.
+func New(w io.Writer, format, layout string) (Writer, error) {
+	if err := Validate(format, layout); err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatCSV:
+		if layout == LayoutFlat {
+			return NewFlatCSV(w), nil
+		}
+		return NewCSVReport(w)
+	case FormatJSONL:
+		return NewJSONLines(w, layout), nil
+	case FormatXLSX:
+		return NewXLSX(w, layout)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// ContentType возвращает MIME-тип и расширение файла для формата.
+func ContentType(format string) (string, string) {
+	switch format {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"
+	case FormatJSONL:
+		return "application/x-ndjson", "jsonl"
+	}
+	return "text/csv; charset=utf-8", "csv"
+}
+
+// operationRecord — операция в машиночитаемых форматах.
+type operationRecord struct {
+	ID          int64        `json:"id"`
+	Type        string       `json:"type"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	Date        time.Time    `json:"date"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func toOperationRecord(item *domain.Item) *operationRecord {
+	return &operationRecord{
+		ID:          item.ID,
+		Type:        item.Type,
+		Amount:      item.Amount,
+		Currency:    item.Currency,
+		Date:        item.Date,
+		Category:    item.Category,
+		Description: item.Description,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
+
+var flatOperationHeader = []string{"id", "type", "amount", "currency", "date", "category", "description", "created_at", "updated_at"}
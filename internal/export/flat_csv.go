@@ -0,0 +1,63 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+// FlatCSV — машиночитаемый CSV: запятая, одна строка заголовка,
+// даты в RFC3339, суммы десятичной строкой без символа валюты.
+// Сводка в этот формат не попадает.
+type FlatCSV struct {
+	w      *csv.Writer
+	header bool
+}
+
+func NewFlatCSV(w io.Writer) *FlatCSV {
+	return &FlatCSV{w: csv.NewWriter(w)}
+}
+
+func (f *FlatCSV) WriteSummary(*domain.Analytics, time.Time, time.Time) error {
+	return nil
+}
+
+func (f *FlatCSV) writeHeader() error {
+	if f.header {
+		return nil
+	}
+	f.header = true
+	return f.w.Write(flatOperationHeader)
+}
+
+func (f *FlatCSV) WriteItem(item *domain.Item) error {
+	if err := f.writeHeader(); err != nil {
+		return err
+	}
+	return f.w.Write([]string{
+		strconv.FormatInt(item.ID, 10),
+		item.Type,
+		item.Amount.String(),
+		item.Currency,
+		item.Date.Format(time.RFC3339),
+		item.Category,
+		item.Description,
+		item.CreatedAt.Format(time.RFC3339),
+		item.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (f *FlatCSV) Flush() error {
+	f.w.Flush()
+	return f.w.Error()
+}
+
+func (f *FlatCSV) Close() error {
+	if err := f.writeHeader(); err != nil {
+		return err
+	}
+	return f.Flush()
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+// JSONLines пишет по одному JSON-объекту на строку. В раскладке report
+// первой строкой идёт сводка; строки различаются полем record.
+type JSONLines struct {
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	layout string
+}
+
+type summaryLine struct {
+	Record string `json:"record"`
+	analyticsRecord
+}
+
+type operationLine struct {
+	Record string `json:"record,omitempty"`
+	operationRecord
+}
+
+func NewJSONLines(w io.Writer, layout string) *JSONLines {
+	buf := bufio.NewWriter(w)
+	return &JSONLines{buf: buf, enc: json.NewEncoder(buf), layout: layout}
+}
+
+func (j *JSONLines) WriteSummary(analytics *domain.Analytics, from, to time.Time) error {
+	if j.layout != LayoutReport {
+		return nil
+	}
+	return j.enc.Encode(summaryLine{Record: "summary", analyticsRecord: newAnalyticsRecord(analytics, from, to)})
+}
+
+func (j *JSONLines) WriteItem(item *domain.Item) error {
+	line := operationLine{operationRecord: *toOperationRecord(item)}
+	if j.layout == LayoutReport {
+		line.Record = "operation"
+	}
+	return j.enc.Encode(line)
+}
+
+func (j *JSONLines) Flush() error {
+	return j.buf.Flush()
+}
+
+func (j *JSONLines) Close() error {
+	return j.buf.Flush()
+}
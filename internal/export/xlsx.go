@@ -0,0 +1,233 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	summarySheet    = "summary"
+	operationsSheet = "operations"
+)
+
+// XLSX пишет книгу Excel с листами summary и operations. Операции
+// пишутся потоково (excelize сбрасывает большие листы во временные файлы),
+// но сама книга — zip-архив, поэтому клиенту она уходит целиком в Close.
+type XLSX struct {
+	out        io.Writer
+	layout     string
+	file       *excelize.File
+	operations *excelize.StreamWriter
+	row        int
+	moneyStyle int
+	dateStyle  int
+}
+
+func NewXLSX(w io.Writer, layout string) (*XLSX, error) {
+	file := excelize.NewFile()
+	x := &XLSX{out: w, layout: layout, file: file, row: 1}
+	if err := x.init(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *XLSX) init() error {
+	if err := x.file.SetSheetName("Sheet1", summarySheet); err != nil {
+		return err
+	}
+	if _, err := x.file.NewSheet(operationsSheet); err != nil {
+		return err
+	}
+	var err error
+	if x.moneyStyle, err = x.file.NewStyle(&excelize.Style{NumFmt: 4}); err != nil {
+		return err
+	}
+	dateFormat := "dd.mm.yyyy hh:mm"
+	if x.dateStyle, err = x.file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return err
+	}
+	x.operations, err = x.file.NewStreamWriter(operationsSheet)
+	return err
+}
+
+func (x *XLSX) WriteSummary(analytics *domain.Analytics, from, to time.Time) error {
+	var rows [][]any
+	if x.layout == LayoutFlat {
+		rows = x.flatSummary(analytics, from, to)
+	} else {
+		rows = x.reportSummary(analytics, from, to)
+	}
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := x.file.SetSheetRow(summarySheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *XLSX) flatSummary(analytics *domain.Analytics, from, to time.Time) [][]any {
+	rows := [][]any{
+		{"period", "from", "to", "currency", "type", "sum", "avg", "count", "median", "percent90"},
+	}
+	add := func(period string, from, to time.Time, itemType string, stats *domain.ItemAnalytics) {
+		rows = append(rows, []any{
+			period,
+			from.Format(time.RFC3339),
+			to.Format(time.RFC3339),
+			analytics.Currency,
+			itemType,
+			x.money(stats.Sum),
+			x.money(stats.Avg),
+			stats.Count,
+			x.money(stats.Median),
+			x.money(stats.Percent90),
+		})
+	}
+	add("current", from, to, "income", analytics.Income)
+	add("current", from, to, "expense", analytics.Expense)
+	if c := analytics.Comparison; c != nil {
+		add(c.Mode, c.From, c.To, "income", c.Previous.Income)
+		add(c.Mode, c.From, c.To, "expense", c.Previous.Expense)
+	}
+	return rows
+}
+
+func (x *XLSX) reportSummary(analytics *domain.Analytics, from, to time.Time) [][]any {
+	rows := [][]any{
+		{"ОТЧЁТ SalesTracker"},
+		{"Период", fmt.Sprintf("%s - %s", from.Format("02.01.2006 15:04"), to.Format("02.01.2006 15:04"))},
+		{"Валюта", analytics.Currency},
+		{},
+		{"Показатель", "Доходы", "Расходы"},
+	}
+	rows = append(rows, statsTable(x, analytics.Income, analytics.Expense)...)
+	if c := analytics.Comparison; c != nil {
+		title := "Сравнение с предыдущим периодом"
+		if c.Mode == domain.ComparePreviousYear {
+			title = "Сравнение с прошлым годом"
+		}
+		rows = append(rows,
+			[]any{},
+			[]any{title},
+			[]any{"Период сравнения", fmt.Sprintf("%s - %s", c.From.Format("02.01.2006 15:04"), c.To.Format("02.01.2006 15:04"))},
+			[]any{"Показатель", "Доходы", "Расходы"},
+		)
+		rows = append(rows, statsTable(x, c.Previous.Income, c.Previous.Expense)...)
+		rows = append(rows,
+			[]any{},
+			[]any{"Изменение, %", "Доходы", "Расходы"},
+			[]any{"Сумма", percent(c.Income.Sum.Percent), percent(c.Expense.Sum.Percent)},
+			[]any{"Количество", percent(c.Income.Count.Percent), percent(c.Expense.Count.Percent)},
+		)
+	}
+	return rows
+}
+
+func statsTable(x *XLSX, income, expense *domain.ItemAnalytics) [][]any {
+	return [][]any{
+		{"Сумма", x.money(income.Sum), x.money(expense.Sum)},
+		{"Среднее", x.money(income.Avg), x.money(expense.Avg)},
+		{"Количество", income.Count, expense.Count},
+		{"Медиана", x.money(income.Median), x.money(expense.Median)},
+		{"90-й перцентиль", x.money(income.Percent90), x.money(expense.Percent90)},
+	}
+}
+
+func percent(p *float64) any {
+	if p == nil {
+		return "—"
+	}
+	return *p
+}
+
+// money переводит сумму в число ячейки: Excel хранит числа как float64.
+func (x *XLSX) money(m domain.Money) excelize.Cell {
+	v, _ := strconv.ParseFloat(m.String(), 64)
+	return excelize.Cell{StyleID: x.moneyStyle, Value: v}
+}
+
+func (x *XLSX) writeHeader() error {
+	if x.row > 1 {
+		return nil
+	}
+	header := []any{"ID", "Тип", "Сумма", "Валюта", "Дата", "Категория", "Описание", "Создано", "Обновлено"}
+	if x.layout == LayoutFlat {
+		header = make([]any, len(flatOperationHeader))
+		for i, name := range flatOperationHeader {
+			header[i] = name
+		}
+	}
+	return x.setRow(header)
+}
+
+func (x *XLSX) setRow(values []any) error {
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	x.row++
+	return x.operations.SetRow(cell, values)
+}
+
+func (x *XLSX) WriteItem(item *domain.Item) error {
+	if err := x.writeHeader(); err != nil {
+		return err
+	}
+	if x.layout == LayoutFlat {
+		return x.setRow([]any{
+			item.ID,
+			item.Type,
+			x.money(item.Amount),
+			item.Currency,
+			item.Date.Format(time.RFC3339),
+			item.Category,
+			item.Description,
+			item.CreatedAt.Format(time.RFC3339),
+			item.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	return x.setRow([]any{
+		item.ID,
+		TypeLabel(item.Type),
+		x.money(item.Amount),
+		item.Currency,
+		x.date(item.Date),
+		item.Category,
+		item.Description,
+		x.date(item.CreatedAt),
+		x.date(item.UpdatedAt),
+	})
+}
+
+func (x *XLSX) date(t time.Time) excelize.Cell {
+	return excelize.Cell{StyleID: x.dateStyle, Value: t}
+}
+
+// Flush ничего не делает: книга отдаётся целиком при Close.
+func (x *XLSX) Flush() error {
+	return nil
+}
+
+func (x *XLSX) Close() error {
+	defer x.file.Close()
+	if err := x.writeHeader(); err != nil {
+		return err
+	}
+	if err := x.operations.Flush(); err != nil {
+		return err
+	}
+	_, err := x.file.WriteTo(x.out)
+	return err
+}
@@ -0,0 +1,245 @@
+package accounts_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/accounts/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type AccountsHandler struct {
+	accountsUsecase accountsUsecase
+	logger          *zlog.Zerolog
+}
+
+func NewHandler(accountsUsecase accountsUsecase, logger *zlog.Zerolog) *AccountsHandler {
+	return &AccountsHandler{
+		accountsUsecase: accountsUsecase,
+		logger:          logger,
+	}
+}
+
+func (h *AccountsHandler) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	s := "internal"
+	switch {
+	case errors.Is(err, customErr.ErrInvalidInput),
+		errors.Is(err, customErr.ErrInvalidDateRange),
+		errors.Is(err, customErr.ErrMissingParameter),
+		errors.Is(err, customErr.ErrUnsupportedFormat):
+		code = http.StatusBadRequest
+		s = "bad_request"
+	case errors.Is(err, customErr.ErrAccountNotFound):
+		code = http.StatusNotFound
+		s = "not_found"
+	case errors.Is(err, customErr.ErrAccountExists):
+		code = http.StatusConflict
+		s = "already_exists"
+	case errors.Is(err, customErr.ErrAccountInUse):
+		code = http.StatusConflict
+		s = "account_in_use"
+	case errors.Is(err, customErr.ErrUnauthorized):
+		code = http.StatusUnauthorized
+		s = "unauthorized"
+	case errors.Is(err, customErr.ErrForbidden):
+		code = http.StatusForbidden
+		s = "forbidden"
+	case errors.Is(err, customErr.ErrDatabase):
+		code = http.StatusInternalServerError
+		s = "database_error"
+	}
+	http.Error(w, s, code)
+}
+
+func toAccountResponse(account *domain.Account) *dto.AccountResponse {
+	return &dto.AccountResponse{
+		ID:             account.ID,
+		Name:           account.Name,
+		Kind:           account.Kind,
+		Currency:       account.Currency,
+		OpeningBalance: account.OpeningBalance,
+		CreatedAt:      account.CreatedAt,
+	}
+}
+
+func (h *AccountsHandler) accountID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		h.logger.Warn().Str("id", idStr).Msg("Invalid account ID")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *AccountsHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode request")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	account := &domain.Account{
+		Name:           req.Name,
+		Kind:           req.Kind,
+		Currency:       req.Currency,
+		OpeningBalance: req.OpeningBalance,
+	}
+	if err := h.accountsUsecase.CreateAccount(r.Context(), account); err != nil {
+		h.logger.Error().Err(err).Msg("CreateAccount failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toAccountResponse(account))
+}
+
+// ListAccounts возвращает счета с текущими остатками.
+func (h *AccountsHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	balances, err := h.accountsUsecase.ListAccounts(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("ListAccounts failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.AccountsResponse{Accounts: make([]*dto.AccountResponse, len(balances))}
+	for i, b := range balances {
+		resp.Accounts[i] = toAccountResponse(b.Account)
+		resp.Accounts[i].Balance = &b.Balance
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *AccountsHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.accountID(w, r)
+	if !ok {
+		return
+	}
+	account, err := h.accountsUsecase.GetAccount(r.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("GetAccount failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAccountResponse(account))
+}
+
+func (h *AccountsHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.accountID(w, r)
+	if !ok {
+		return
+	}
+	var req dto.UpdateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode request")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	patch := &domain.AccountPatch{Name: req.Name, Kind: req.Kind, OpeningBalance: req.OpeningBalance}
+	account, err := h.accountsUsecase.UpdateAccount(r.Context(), id, patch)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("UpdateAccount failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAccountResponse(account))
+}
+
+func (h *AccountsHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.accountID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.accountsUsecase.DeleteAccount(r.Context(), id); err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("DeleteAccount failed")
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStatement возвращает выписку счёта: from и to (RFC3339) ограничивают
+// период, page и limit — страницу операций.
+func (h *AccountsHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.accountID(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	params := &domain.AccountStatementParams{}
+	for name, dst := range map[string]**time.Time{"from": &params.From, "to": &params.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.logger.Warn().Err(err).Str(name, value).Msg("Invalid date format")
+			h.writeError(w, customErr.ErrUnsupportedFormat)
+			return
+		}
+		*dst = &parsed
+	}
+	page, limit := 1, 100
+	if v := query.Get("page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p <= 0 {
+			h.logger.Warn().Str("page", v).Msg("Invalid page parameter")
+			h.writeError(w, customErr.ErrInvalidInput)
+			return
+		}
+		page = p
+	}
+	if v := query.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			h.logger.Warn().Str("limit", v).Msg("Invalid limit parameter")
+			h.writeError(w, customErr.ErrInvalidInput)
+			return
+		}
+		limit = l
+	}
+	params.Offset, params.Limit = (page-1)*limit, limit
+
+	st, err := h.accountsUsecase.GetStatement(r.Context(), id, params)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("GetStatement failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.StatementResponse{
+		Account:        toAccountResponse(st.Account),
+		OpeningBalance: st.OpeningBalance,
+		ClosingBalance: st.ClosingBalance,
+		Total:          st.Total,
+		Page:           page,
+		Limit:          limit,
+		Entries:        make([]*dto.StatementEntry, len(st.Entries)),
+	}
+	for i, e := range st.Entries {
+		resp.Entries[i] = &dto.StatementEntry{
+			ItemID:      e.Item.ID,
+			Type:        e.Item.Type,
+			Date:        e.Item.Date,
+			Category:    e.Item.Category,
+			Description: e.Item.Description,
+			Amount:      e.Amount,
+			Balance:     e.Balance,
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
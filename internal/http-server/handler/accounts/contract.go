@@ -0,0 +1,15 @@
+package accounts_handler
+
+import (
+	"context"
+	"sales-tracker/internal/domain"
+)
+
+type accountsUsecase interface {
+	CreateAccount(ctx context.Context, account *domain.Account) error
+	ListAccounts(ctx context.Context) ([]*domain.AccountBalance, error)
+	GetAccount(ctx context.Context, id int64) (*domain.Account, error)
+	UpdateAccount(ctx context.Context, id int64, patch *domain.AccountPatch) (*domain.Account, error)
+	DeleteAccount(ctx context.Context, id int64) error
+	GetStatement(ctx context.Context, id int64, params *domain.AccountStatementParams) (*domain.AccountStatement, error)
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"sales-tracker/internal/domain"
+	"time"
+)
+
+type CreateAccountRequest struct {
+	Name           string       `json:"name"`
+	Kind           string       `json:"kind"`
+	Currency       string       `json:"currency"`
+	OpeningBalance domain.Money `json:"opening_balance"`
+}
+
+// UpdateAccountRequest — изменяемые поля счёта; отсутствующие не меняются.
+type UpdateAccountRequest struct {
+	Name           *string       `json:"name"`
+	Kind           *string       `json:"kind"`
+	OpeningBalance *domain.Money `json:"opening_balance"`
+}
+
+type AccountResponse struct {
+	ID             int64         `json:"id"`
+	Name           string        `json:"name"`
+	Kind           string        `json:"kind"`
+	Currency       string        `json:"currency"`
+	OpeningBalance domain.Money  `json:"opening_balance"`
+	Balance        *domain.Money `json:"balance,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type AccountsResponse struct {
+	Accounts []*AccountResponse `json:"accounts"`
+}
+
+// StatementEntry — операция выписки: amount — изменение остатка со знаком,
+// balance — остаток после операции.
+type StatementEntry struct {
+	ItemID      int64        `json:"item_id"`
+	Type        string       `json:"type"`
+	Date        time.Time    `json:"date"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	Amount      domain.Money `json:"amount"`
+	Balance     domain.Money `json:"balance"`
+}
+
+type StatementResponse struct {
+	Account        *AccountResponse  `json:"account"`
+	OpeningBalance domain.Money      `json:"opening_balance"`
+	ClosingBalance domain.Money      `json:"closing_balance"`
+	Total          int64             `json:"total"`
+	Page           int               `json:"page"`
+	Limit          int               `json:"limit"`
+	Entries        []*StatementEntry `json:"entries"`
+}
@@ -3,9 +3,13 @@ package analytics_handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"sales-tracker/internal/domain"
 	customErr "sales-tracker/internal/domain/errors"
 	"sales-tracker/internal/http-server/handler/analytics/dto"
 
@@ -42,6 +46,12 @@ func (h *AnalyticsHandler) writeError(w http.ResponseWriter, err error, statusCo
 		if statusCode == 0 {
 			statusCode = http.StatusNotFound
 		}
+	case errors.Is(err, customErr.ErrRateNotFound):
+		resp["error"] = "exchange_rate_not_found"
+	case errors.Is(err, customErr.ErrUnauthorized):
+		resp["error"] = "unauthorized"
+	case errors.Is(err, customErr.ErrForbidden):
+		resp["error"] = "forbidden"
 	case errors.Is(err, customErr.ErrDatabase):
 		resp["error"] = "database_error"
 		if statusCode == 0 {
@@ -53,6 +63,45 @@ func (h *AnalyticsHandler) writeError(w http.ResponseWriter, err error, statusCo
 	json.NewEncoder(w).Encode(resp)
 }
 
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, customErr.ErrInvalidDateRange),
+		errors.Is(err, customErr.ErrMissingParameter),
+		errors.Is(err, customErr.ErrUnsupportedFormat),
+		errors.Is(err, customErr.ErrInvalidInput),
+		errors.Is(err, customErr.ErrPeriodTooLarge):
+		return http.StatusBadRequest
+	case errors.Is(err, customErr.ErrRateNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, customErr.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, customErr.ErrForbidden):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// parsePeriod читает обязательные параметры from и to в формате RFC3339.
+func parsePeriod(r *http.Request) (time.Time, time.Time, error) {
+	fromStr := r.URL.Query().Get("from")
+	toStr := r.URL.Query().Get("to")
+	if fromStr == "" || toStr == "" {
+		return time.Time{}, time.Time{}, customErr.ErrMissingParameter
+	}
+	from, err := time.Parse(time.RFC3339, fromStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from: %v", customErr.ErrUnsupportedFormat, err)
+	}
+	to, err := time.Parse(time.RFC3339, toStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to: %v", customErr.ErrUnsupportedFormat, err)
+	}
+	if from.After(to) {
+		return time.Time{}, time.Time{}, customErr.ErrInvalidDateRange
+	}
+	return from, to, nil
+}
+
 func (h *AnalyticsHandler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
 	fromStr := r.URL.Query().Get("from")
 	toStr := r.URL.Query().Get("to")
@@ -90,16 +139,21 @@ func (h *AnalyticsHandler) GetAnalytics(w http.ResponseWriter, r *http.Request)
 		Time("from_parsed", from).
 		Time("to_parsed", to).
 		Msg("Analytics request received")
-	an, err := h.analyticsUsecase.GetAnalytics(r.Context(), from, to)
+	params := &domain.AnalyticsParams{
+		From:     from,
+		To:       to,
+		Currency: r.URL.Query().Get("currency"),
+		Compare:  r.URL.Query().Get("compare"),
+	}
+	if err := parseDistribution(r, params); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid distribution parameters")
+		h.writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	an, err := h.analyticsUsecase.GetAnalytics(r.Context(), params)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to get analytics")
-		if errors.Is(err, customErr.ErrInvalidDateRange) ||
-			errors.Is(err, customErr.ErrMissingParameter) ||
-			errors.Is(err, customErr.ErrUnsupportedFormat) {
-			h.writeError(w, err, http.StatusBadRequest)
-		} else {
-			h.writeError(w, err, http.StatusInternalServerError)
-		}
+		h.writeError(w, err, errorStatus(err))
 		return
 	}
 
@@ -109,6 +163,7 @@ func (h *AnalyticsHandler) GetAnalytics(w http.ResponseWriter, r *http.Request)
 			ID:          item.ID,
 			Type:        item.Type,
 			Amount:      item.Amount,
+			Currency:    item.Currency,
 			Date:        item.Date,
 			Category:    item.Category,
 			Description: item.Description,
@@ -118,24 +173,192 @@ func (h *AnalyticsHandler) GetAnalytics(w http.ResponseWriter, r *http.Request)
 	}
 
 	resp := dto.AnalyticsResponse{
-		Income: &dto.ItemAnalytics{
-			Sum:       an.Income.Sum,
-			Avg:       an.Income.Avg,
-			Count:     an.Income.Count,
-			Median:    an.Income.Median,
-			Percent90: an.Income.Percent90,
-		},
-		Expense: &dto.ItemAnalytics{
-			Sum:       an.Expense.Sum,
-			Avg:       an.Expense.Avg,
-			Count:     an.Expense.Count,
-			Median:    an.Expense.Median,
-			Percent90: an.Expense.Percent90,
-		},
-		Details: details,
+		Currency: an.Currency,
+		Income:   toItemAnalyticsResponse(an.Income),
+		Expense:  toItemAnalyticsResponse(an.Expense),
+		Details:  details,
+	}
+	if c := an.Comparison; c != nil {
+		resp.Comparison = &dto.ComparisonResponse{
+			Mode:    c.Mode,
+			From:    c.From,
+			To:      c.To,
+			Income:  toItemAnalyticsResponse(c.Previous.Income),
+			Expense: toItemAnalyticsResponse(c.Previous.Expense),
+			Delta: &dto.AnalyticsDelta{
+				Income:  toItemAnalyticsDeltaResponse(c.Income),
+				Expense: toItemAnalyticsDeltaResponse(c.Expense),
+			},
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(resp)
 }
+
+func (h *AnalyticsHandler) GetTimeSeries(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid period parameters")
+		h.writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	params := &domain.TimeSeriesParams{
+		From:     from,
+		To:       to,
+		Interval: r.URL.Query().Get("interval"),
+		TimeZone: r.URL.Query().Get("tz"),
+		Currency: r.URL.Query().Get("currency"),
+	}
+	series, err := h.analyticsUsecase.GetTimeSeries(r.Context(), params)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get analytics time series")
+		h.writeError(w, err, errorStatus(err))
+		return
+	}
+
+	resp := dto.TimeSeriesResponse{
+		Interval: series.Interval,
+		TimeZone: series.TimeZone,
+		Currency: series.Currency,
+		Buckets:  make([]*dto.TimeSeriesBucket, len(series.Buckets)),
+	}
+	for i, bucket := range series.Buckets {
+		resp.Buckets[i] = &dto.TimeSeriesBucket{
+			Start: bucket.Start,
+			Income: &dto.TimeSeriesStats{
+				Sum:   bucket.Income.Sum,
+				Count: bucket.Income.Count,
+				Avg:   bucket.Income.Avg,
+			},
+			Expense: &dto.TimeSeriesStats{
+				Sum:   bucket.Expense.Sum,
+				Count: bucket.Expense.Count,
+				Avg:   bucket.Expense.Avg,
+			},
+			Net: bucket.Net,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *AnalyticsHandler) GetBreakdown(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid period parameters")
+		h.writeError(w, err, http.StatusBadRequest)
+		return
+	}
+	params := &domain.BreakdownParams{
+		From:     from,
+		To:       to,
+		TimeZone: r.URL.Query().Get("tz"),
+		Currency: r.URL.Query().Get("currency"),
+	}
+	if groupBy := r.URL.Query().Get("group_by"); groupBy != "" {
+		for _, dim := range strings.Split(groupBy, ",") {
+			params.GroupBy = append(params.GroupBy, strings.TrimSpace(dim))
+		}
+	}
+	if topStr := r.URL.Query().Get("top"); topStr != "" {
+		top, err := strconv.Atoi(topStr)
+		if err != nil {
+			h.logger.Warn().Str("top", topStr).Msg("Invalid top parameter")
+			h.writeError(w, customErr.ErrInvalidInput, http.StatusBadRequest)
+			return
+		}
+		params.Top = top
+	}
+
+	breakdown, err := h.analyticsUsecase.GetBreakdown(r.Context(), params)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get analytics breakdown")
+		h.writeError(w, err, errorStatus(err))
+		return
+	}
+
+	resp := dto.BreakdownResponse{
+		GroupBy:  breakdown.GroupBy,
+		Currency: breakdown.Currency,
+		Groups:   make([]*dto.BreakdownGroup, len(breakdown.Groups)),
+	}
+	for i, group := range breakdown.Groups {
+		resp.Groups[i] = &dto.BreakdownGroup{
+			Keys:  group.Keys,
+			Other: group.Other,
+			Stats: toItemAnalyticsResponse(group.Stats),
+			Share: group.Share,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func toItemAnalyticsResponse(stats *domain.ItemAnalytics) *dto.ItemAnalytics {
+	resp := &dto.ItemAnalytics{
+		Sum:       stats.Sum,
+		Avg:       stats.Avg,
+		Count:     stats.Count,
+		Median:    stats.Median,
+		Percent90: stats.Percent90,
+	}
+	if len(stats.Percentiles) > 0 {
+		resp.Percentiles = make(map[string]domain.Money, len(stats.Percentiles))
+		for p, value := range stats.Percentiles {
+			resp.Percentiles[strconv.FormatFloat(p, 'f', -1, 64)] = value
+		}
+	}
+	if stats.Histogram != nil {
+		resp.Histogram = make([]*dto.HistogramBin, len(stats.Histogram))
+		for i, bin := range stats.Histogram {
+			resp.Histogram[i] = &dto.HistogramBin{
+				From:  bin.From,
+				To:    bin.To,
+				Count: bin.Count,
+			}
+		}
+	}
+	return resp
+}
+
+// parseDistribution читает параметры percentiles (дроби через запятую),
+// histogram (fixed|log) и bins.
+func parseDistribution(r *http.Request, params *domain.AnalyticsParams) error {
+	if percentiles := r.URL.Query().Get("percentiles"); percentiles != "" {
+		for _, value := range strings.Split(percentiles, ",") {
+			p, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return fmt.Errorf("%w: percentiles: %v", customErr.ErrInvalidInput, err)
+			}
+			params.Percentiles = append(params.Percentiles, p)
+		}
+	}
+	params.Histogram = r.URL.Query().Get("histogram")
+	if binsStr := r.URL.Query().Get("bins"); binsStr != "" {
+		bins, err := strconv.Atoi(binsStr)
+		if err != nil {
+			return fmt.Errorf("%w: bins: %v", customErr.ErrInvalidInput, err)
+		}
+		params.Bins = bins
+	}
+	return nil
+}
+
+func toItemAnalyticsDeltaResponse(delta *domain.ItemAnalyticsDelta) *dto.ItemAnalyticsDelta {
+	money := func(d domain.MoneyDelta) dto.MoneyDelta {
+		return dto.MoneyDelta{Abs: d.Abs, Percent: d.Percent}
+	}
+	return &dto.ItemAnalyticsDelta{
+		Sum:       money(delta.Sum),
+		Avg:       money(delta.Avg),
+		Count:     dto.CountDelta{Abs: delta.Count.Abs, Percent: delta.Count.Percent},
+		Median:    money(delta.Median),
+		Percent90: money(delta.Percent90),
+	}
+}
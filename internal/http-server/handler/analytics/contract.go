@@ -3,9 +3,10 @@ package analytics_handler
 import (
 	"context"
 	"sales-tracker/internal/domain"
-	"time"
 )
 
 type analyticsUsecase interface {
-	GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error)
+	GetAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error)
+	GetTimeSeries(ctx context.Context, params *domain.TimeSeriesParams) (*domain.TimeSeries, error)
+	GetBreakdown(ctx context.Context, params *domain.BreakdownParams) (*domain.Breakdown, error)
 }
@@ -1,30 +1,107 @@
 package dto
 
 import (
+	"sales-tracker/internal/domain"
 	"time"
 )
 
 type AnalyticsResponse struct {
-	Income  *ItemAnalytics          `json:"income"`
-	Expense *ItemAnalytics          `json:"expense"`
-	Details []AnalyticsItemResponse `json:"details"`
+	Currency   string                  `json:"currency"`
+	Income     *ItemAnalytics          `json:"income"`
+	Expense    *ItemAnalytics          `json:"expense"`
+	Details    []AnalyticsItemResponse `json:"details"`
+	Comparison *ComparisonResponse     `json:"comparison,omitempty"`
+}
+
+type ComparisonResponse struct {
+	Mode    string          `json:"mode"`
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Income  *ItemAnalytics  `json:"income"`
+	Expense *ItemAnalytics  `json:"expense"`
+	Delta   *AnalyticsDelta `json:"delta"`
+}
+
+type AnalyticsDelta struct {
+	Income  *ItemAnalyticsDelta `json:"income"`
+	Expense *ItemAnalyticsDelta `json:"expense"`
+}
+
+type ItemAnalyticsDelta struct {
+	Sum       MoneyDelta `json:"sum"`
+	Avg       MoneyDelta `json:"avg"`
+	Count     CountDelta `json:"count"`
+	Median    MoneyDelta `json:"median"`
+	Percent90 MoneyDelta `json:"percent90"`
+}
+
+type MoneyDelta struct {
+	Abs     domain.Money `json:"abs"`
+	Percent *float64     `json:"pct"`
+}
+
+type CountDelta struct {
+	Abs     int64    `json:"abs"`
+	Percent *float64 `json:"pct"`
 }
 
 type ItemAnalytics struct {
-	Sum       float64 `json:"sum"`
-	Avg       float64 `json:"avg"`
-	Count     int64   `json:"count"`
-	Median    float64 `json:"median"`
-	Percent90 float64 `json:"percent90"`
+	Sum         domain.Money            `json:"sum"`
+	Avg         domain.Money            `json:"avg"`
+	Count       int64                   `json:"count"`
+	Median      domain.Money            `json:"median"`
+	Percent90   domain.Money            `json:"percent90"`
+	Percentiles map[string]domain.Money `json:"percentiles,omitempty"`
+	Histogram   []*HistogramBin         `json:"histogram,omitempty"`
+}
+
+type HistogramBin struct {
+	From  domain.Money `json:"from"`
+	To    domain.Money `json:"to"`
+	Count int64        `json:"count"`
 }
 
 type AnalyticsItemResponse struct {
-	ID          int64     `json:"id"`
-	Type        string    `json:"type"`
-	Amount      float64   `json:"amount"`
-	Date        time.Time `json:"date"`
-	Category    string    `json:"category"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64        `json:"id"`
+	Type        string       `json:"type"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	Date        time.Time    `json:"date"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+type TimeSeriesResponse struct {
+	Interval string              `json:"interval"`
+	TimeZone string              `json:"tz"`
+	Currency string              `json:"currency"`
+	Buckets  []*TimeSeriesBucket `json:"buckets"`
+}
+
+type TimeSeriesBucket struct {
+	Start   time.Time        `json:"start"`
+	Income  *TimeSeriesStats `json:"income"`
+	Expense *TimeSeriesStats `json:"expense"`
+	Net     domain.Money     `json:"net"`
+}
+
+type TimeSeriesStats struct {
+	Sum   domain.Money `json:"sum"`
+	Count int64        `json:"count"`
+	Avg   domain.Money `json:"avg"`
+}
+
+type BreakdownResponse struct {
+	GroupBy  []string          `json:"group_by"`
+	Currency string            `json:"currency"`
+	Groups   []*BreakdownGroup `json:"groups"`
+}
+
+type BreakdownGroup struct {
+	Keys  map[string]string `json:"keys"`
+	Other bool              `json:"other,omitempty"`
+	Stats *ItemAnalytics    `json:"stats"`
+	Share float64           `json:"share"`
 }
@@ -0,0 +1,181 @@
+package audit_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/audit/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type AuditHandler struct {
+	auditUsecase auditUsecase
+	logger       *zlog.Zerolog
+}
+
+func NewHandler(auditUsecase auditUsecase, logger *zlog.Zerolog) *AuditHandler {
+	return &AuditHandler{
+		auditUsecase: auditUsecase,
+		logger:       logger,
+	}
+}
+
+func (h *AuditHandler) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	s := "internal"
+	switch {
+	case errors.Is(err, customErr.ErrInvalidInput),
+		errors.Is(err, customErr.ErrInvalidDateRange),
+		errors.Is(err, customErr.ErrUnsupportedFormat):
+		code = http.StatusBadRequest
+		s = "bad_request"
+	case errors.Is(err, customErr.ErrItemNotFound):
+		code = http.StatusNotFound
+		s = "not_found"
+	case errors.Is(err, customErr.ErrUnauthorized):
+		code = http.StatusUnauthorized
+		s = "unauthorized"
+	case errors.Is(err, customErr.ErrForbidden):
+		code = http.StatusForbidden
+		s = "forbidden"
+	case errors.Is(err, customErr.ErrDatabase):
+		code = http.StatusInternalServerError
+		s = "database_error"
+	}
+	http.Error(w, s, code)
+}
+
+// GetItemHistory возвращает историю изменений записи, в том числе удалённой.
+func (h *AuditHandler) GetItemHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		h.logger.Error().Err(err).Str("id", idStr).Msg("Invalid ID")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	entries, err := h.auditUsecase.GetItemHistory(r.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("GetItemHistory failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.HistoryResponse{ItemID: id, Entries: toAuditEntries(entries)})
+	h.logger.Info().Int64("id", id).Int("count", len(entries)).Msg("Item history retrieved")
+}
+
+// GetAudit отдаёт журнал изменений всех записей. Параметры: from, to (RFC3339),
+// actor, after (seq последней полученной записи), limit (до 1000).
+func (h *AuditHandler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &domain.AuditFilter{Actor: query.Get("actor")}
+	for name, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.logger.Warn().Err(err).Str(name, value).Msg("Invalid date format")
+			h.writeError(w, fmt.Errorf("%w: %s: %v", customErr.ErrUnsupportedFormat, name, err))
+			return
+		}
+		*dst = &parsed
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			h.writeError(w, fmt.Errorf("%w: limit: %v", customErr.ErrInvalidInput, err))
+			return
+		}
+		filter.Limit = limit
+	}
+	if value := query.Get("after"); value != "" {
+		after, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			h.writeError(w, fmt.Errorf("%w: after: %v", customErr.ErrInvalidInput, err))
+			return
+		}
+		filter.After = after
+	}
+
+	entries, err := h.auditUsecase.GetAudit(r.Context(), filter)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("GetAudit failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.AuditResponse{Entries: toAuditEntries(entries)}
+	if len(entries) == filter.Limit {
+		resp.NextAfter = entries[len(entries)-1].Seq
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+	h.logger.Info().Int("count", len(entries)).Msg("Audit log retrieved")
+}
+
+// VerifyAudit проверяет цепочку хешей журнала и сообщает первое нарушенное звено.
+// Параметр expect_head (<seq>:<hash>) сверяет цепочку с сохранённой головой.
+func (h *AuditHandler) VerifyAudit(w http.ResponseWriter, r *http.Request) {
+	var anchor *domain.AuditAnchor
+	if v := r.URL.Query().Get("expect_head"); v != "" {
+		a, err := domain.ParseAuditAnchor(v)
+		if err != nil {
+			h.logger.Error().Err(err).Str("expect_head", v).Msg("Invalid expect_head")
+			h.writeError(w, customErr.ErrInvalidInput)
+			return
+		}
+		anchor = a
+	}
+	result, err := h.auditUsecase.VerifyAudit(r.Context(), anchor)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("VerifyAudit failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toVerifyResponse(result))
+	h.logger.Info().Bool("valid", result.Valid).Int64("checked", result.Checked).Msg("Audit chain verified")
+}
+
+func toVerifyResponse(result *domain.AuditVerification) *dto.VerifyResponse {
+	resp := &dto.VerifyResponse{
+		Valid:    result.Valid,
+		Checked:  result.Checked,
+		HeadSeq:  result.HeadSeq,
+		HeadHash: result.HeadHash,
+	}
+	if b := result.Broken; b != nil {
+		resp.Broken = &dto.AuditBreak{Seq: b.Seq, EntryID: b.EntryID, Reason: b.Reason}
+	}
+	return resp
+}
+
+func toAuditEntries(entries []*domain.AuditEntry) []*dto.AuditEntry {
+	resp := make([]*dto.AuditEntry, len(entries))
+	for i, e := range entries {
+		resp[i] = &dto.AuditEntry{
+			ID:        e.ID,
+			Seq:       e.Seq,
+			ItemID:    e.ItemID,
+			Action:    e.Action,
+			Before:    e.Before,
+			After:     e.After,
+			Actor:     e.Actor,
+			RequestID: e.RequestID,
+			CreatedAt: e.CreatedAt,
+			PrevHash:  e.PrevHash,
+			Hash:      e.Hash,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,12 @@
+package audit_handler
+
+import (
+	"context"
+	"sales-tracker/internal/domain"
+)
+
+type auditUsecase interface {
+	GetItemHistory(ctx context.Context, id int64) ([]*domain.AuditEntry, error)
+	GetAudit(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEntry, error)
+	VerifyAudit(ctx context.Context, anchor *domain.AuditAnchor) (*domain.AuditVerification, error)
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	Seq       int64           `json:"seq"`
+	ItemID    int64           `json:"item_id"`
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+}
+
+type HistoryResponse struct {
+	ItemID  int64         `json:"item_id"`
+	Entries []*AuditEntry `json:"entries"`
+}
+
+type AuditResponse struct {
+	Entries []*AuditEntry `json:"entries"`
+	// NextAfter — значение параметра after (seq) для следующей страницы.
+	NextAfter int64 `json:"next_after,omitempty"`
+}
+
+type AuditBreak struct {
+	Seq     int64  `json:"seq"`
+	EntryID int64  `json:"entry_id,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+type VerifyResponse struct {
+	Valid    bool        `json:"valid"`
+	Checked  int64       `json:"checked"`
+	HeadSeq  int64       `json:"head_seq"`
+	HeadHash string      `json:"head_hash"`
+	Broken   *AuditBreak `json:"broken,omitempty"`
+}
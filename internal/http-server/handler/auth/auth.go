@@ -0,0 +1,132 @@
+package auth_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/auth/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type AuthHandler struct {
+	authUsecase authUsecase
+	logger      *zlog.Zerolog
+}
+
+func NewHandler(authUsecase authUsecase, logger *zlog.Zerolog) *AuthHandler {
+	return &AuthHandler{
+		authUsecase: authUsecase,
+		logger:      logger,
+	}
+}
+
+func (h *AuthHandler) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	s := "internal"
+	switch {
+	case errors.Is(err, customErr.ErrInvalidInput):
+		code = http.StatusBadRequest
+		s = "bad_request"
+	case errors.Is(err, customErr.ErrUnauthorized):
+		code = http.StatusUnauthorized
+		s = "unauthorized"
+	case errors.Is(err, customErr.ErrForbidden):
+		code = http.StatusForbidden
+		s = "forbidden"
+	case errors.Is(err, customErr.ErrAPIKeyNotFound):
+		code = http.StatusNotFound
+		s = "not_found"
+	case errors.Is(err, customErr.ErrDatabase):
+		code = http.StatusInternalServerError
+		s = "database_error"
+	}
+	http.Error(w, s, code)
+}
+
+// Me возвращает субъект текущего запроса.
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	principal := domain.PrincipalFrom(r.Context())
+	if principal == nil {
+		h.writeError(w, customErr.ErrUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.PrincipalResponse{
+		Subject:     principal.Subject,
+		Name:        principal.Name,
+		Method:      principal.Method,
+		Roles:       principal.Roles,
+		WorkspaceID: principal.WorkspaceID,
+	})
+}
+
+func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn().Err(err).Msg("Failed to decode request")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	key := &domain.APIKey{Name: req.Name, Roles: req.Roles, ExpiresAt: req.ExpiresAt}
+	secret, err := h.authUsecase.CreateAPIKey(r.Context(), key)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("CreateAPIKey failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.CreateAPIKeyResponse{APIKey: *toAPIKey(key), Key: secret})
+}
+
+func (h *AuthHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.authUsecase.ListAPIKeys(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("ListAPIKeys failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.APIKeysResponse{Keys: make([]*dto.APIKey, len(keys))}
+	for i, key := range keys {
+		resp.Keys[i] = toAPIKey(key)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *AuthHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		h.logger.Warn().Err(err).Str("id", idStr).Msg("Invalid ID")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	key, err := h.authUsecase.RevokeAPIKey(r.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("RevokeAPIKey failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAPIKey(key))
+}
+
+func toAPIKey(key *domain.APIKey) *dto.APIKey {
+	return &dto.APIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Roles:      key.Roles,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,12 @@
+package auth_handler
+
+import (
+	"context"
+	"sales-tracker/internal/domain"
+)
+
+type authUsecase interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) (string, error)
+	ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error)
+}
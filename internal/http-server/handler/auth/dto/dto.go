@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type PrincipalResponse struct {
+	Subject     string   `json:"subject"`
+	Name        string   `json:"name"`
+	Method      string   `json:"method"`
+	Roles       []string `json:"roles"`
+	WorkspaceID int64    `json:"workspace_id"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Roles     []string   `json:"roles"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Roles      []string   `json:"roles"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyResponse содержит сам ключ; повторно получить его нельзя.
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeysResponse struct {
+	Keys []*APIKey `json:"keys"`
+}
@@ -0,0 +1,159 @@
+package items_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/items/dto"
+)
+
+const maxBulkSize = 8 << 20
+
+// BulkItems выполняет пакет операций create/update/delete. При atomic=true
+// пакет применяется одной транзакцией; если он отклонён, ответ — 422.
+func (h *ItemsHandler) BulkItems(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkSize)
+	var req dto.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode request")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	ops := make([]*domain.BulkOperation, len(req.Operations))
+	for i, op := range req.Operations {
+		if op == nil {
+			op = &dto.BulkOperation{}
+		}
+		ops[i] = &domain.BulkOperation{Op: op.Op, ID: op.ID, Version: op.Version}
+		if op.Item != nil {
+			// Некорректная дата остаётся нулевой и отклоняется проверкой операции.
+			date, _ := time.Parse(time.RFC3339, op.Item.Date)
+			ops[i].Item = &domain.Item{
+				Type:        op.Item.Type,
+				Amount:      op.Item.Amount,
+				Currency:    op.Item.Currency,
+				Date:        date,
+				Category:    op.Item.Category,
+				Description: op.Item.Description,
+				AccountID:   op.Item.AccountID,
+				ToAccountID: op.Item.ToAccountID,
+			}
+		}
+	}
+
+	result, err := h.itemsUsecase.BulkItems(r.Context(), ops, &domain.BulkOptions{Atomic: req.Atomic})
+	if err != nil {
+		h.logger.Error().Err(err).Msg("BulkItems failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.BulkResponse{
+		Atomic:    result.Atomic,
+		Succeeded: result.Succeeded,
+		Failed:    result.Failed,
+		Rejected:  result.Rejected,
+		Results:   make([]*dto.BulkOpResult, len(result.Results)),
+	}
+	for i, res := range result.Results {
+		resp.Results[i] = bulkOpResponse(res)
+	}
+	status := http.StatusOK
+	if result.Rejected {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+	h.logger.Info().
+		Int("succeeded", result.Succeeded).
+		Int("failed", result.Failed).
+		Bool("atomic", result.Atomic).
+		Msg("Bulk item operations finished")
+}
+
+func bulkOpResponse(res *domain.BulkOpResult) *dto.BulkOpResult {
+	out := &dto.BulkOpResult{Index: res.Index, Op: res.Op, ID: res.ID, Version: res.Version}
+	switch {
+	case res.Err != nil:
+		out.Status, out.Error = errorStatus(res.Err)
+		if out.Status < http.StatusInternalServerError {
+			out.Detail = res.Err.Error()
+		}
+	case res.RolledBack:
+		out.Status, out.Error = http.StatusFailedDependency, "rolled_back"
+	case res.NotExecuted:
+		out.Status, out.Error = http.StatusFailedDependency, "not_executed"
+	case res.Op == domain.BulkOpCreate:
+		out.Status = http.StatusCreated
+	case res.Op == domain.BulkOpDelete:
+		out.Status = http.StatusNoContent
+	default:
+		out.Status = http.StatusOK
+	}
+	return out
+}
+
+// UpdateItemsByFilter применяет JSON Merge Patch из тела ко всем записям,
+// подходящим под фильтр из query (параметры те же, что у GET /items).
+// dry_run=true только считает такие записи.
+func (h *ItemsHandler) UpdateItemsByFilter(w http.ResponseWriter, r *http.Request) {
+	filter, dryRun, err := parseBulkFilter(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid bulk filter")
+		h.writeError(w, err)
+		return
+	}
+	patch, err := decodeMergePatch(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid merge patch")
+		h.writeError(w, err)
+		return
+	}
+	n, err := h.itemsUsecase.UpdateItemsByFilter(r.Context(), filter, patch, dryRun)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("UpdateItemsByFilter failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.BulkFilterResponse{Matched: n, DryRun: dryRun})
+	h.logger.Info().Int64("matched", n).Bool("dry_run", dryRun).Msg("Items updated by filter")
+}
+
+// DeleteItemsByFilter удаляет все записи, подходящие под фильтр из query.
+// dry_run=true только считает такие записи.
+func (h *ItemsHandler) DeleteItemsByFilter(w http.ResponseWriter, r *http.Request) {
+	filter, dryRun, err := parseBulkFilter(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid bulk filter")
+		h.writeError(w, err)
+		return
+	}
+	n, err := h.itemsUsecase.DeleteItemsByFilter(r.Context(), filter, dryRun)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("DeleteItemsByFilter failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.BulkFilterResponse{Matched: n, DryRun: dryRun})
+	h.logger.Info().Int64("matched", n).Bool("dry_run", dryRun).Msg("Items deleted by filter")
+}
+
+func parseBulkFilter(r *http.Request) (*domain.ItemsFilter, bool, error) {
+	filter, err := parseItemsFilter(r.URL.Query())
+	if err != nil {
+		return nil, false, err
+	}
+	var dryRun bool
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			return nil, false, customErr.ErrInvalidInput
+		}
+	}
+	return filter, dryRun, nil
+}
@@ -3,18 +3,27 @@ package items_handler
 import (
 	"context"
 	"sales-tracker/internal/domain"
-	"time"
 )
 
 type itemsUsecase interface {
 	CreateItem(ctx context.Context, item *domain.Item) (int64, error)
+	CreateItemIdempotent(ctx context.Context, key string, item *domain.Item) (int64, bool, error)
 	GetItems(ctx context.Context) ([]*domain.Item, error)
-	GetItemsWithPagination(ctx context.Context, offset, limit int) ([]*domain.Item, int64, error)
+	GetItemsWithPagination(ctx context.Context, filter *domain.ItemsFilter, offset, limit int) ([]*domain.Item, int64, error)
+	GetItemsByCursor(ctx context.Context, filter *domain.ItemsFilter, cursor *domain.ItemsCursor, limit int, withTotal bool) (*domain.ItemsPage, error)
 	GetItemByID(ctx context.Context, id int64) (*domain.Item, error)
-	UpdateItem(ctx context.Context, id int64, item *domain.Item) error
-	DeleteItem(ctx context.Context, id int64) error
+	UpdateItem(ctx context.Context, id int64, item *domain.Item, version int64) error
+	PatchItem(ctx context.Context, id int64, patch *domain.ItemPatch, version int64) (*domain.Item, error)
+	DeleteItem(ctx context.Context, id int64, version int64) error
+	BulkItems(ctx context.Context, ops []*domain.BulkOperation, opts *domain.BulkOptions) (*domain.BulkResult, error)
+	UpdateItemsByFilter(ctx context.Context, filter *domain.ItemsFilter, patch *domain.ItemPatch, dryRun bool) (int64, error)
+	DeleteItemsByFilter(ctx context.Context, filter *domain.ItemsFilter, dryRun bool) (int64, error)
+	GetTrash(ctx context.Context, offset, limit int) ([]*domain.Item, int64, error)
+	RestoreItem(ctx context.Context, id int64) (*domain.Item, error)
+	ImportItems(ctx context.Context, rows []*domain.ImportRow, opts *domain.ImportOptions) (*domain.ImportResult, error)
 }
 
 type analyticsUsecase interface {
-	GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error)
+	GetExportAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error)
+	StreamDetails(ctx context.Context, params *domain.AnalyticsParams, fn func(*domain.Item) error) error
 }
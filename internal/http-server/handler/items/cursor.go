@@ -0,0 +1,50 @@
+package items_handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+)
+
+// cursorToken — содержимое непрозрачного курсора. Клиент получает его в base64url
+// и не должен полагаться на формат.
+type cursorToken struct {
+	Date     time.Time `json:"d"`
+	ID       int64     `json:"i"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+func encodeCursor(cursor *domain.ItemsCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	data, _ := json.Marshal(cursorToken{
+		Date:     cursor.Date,
+		ID:       cursor.ID,
+		Backward: cursor.Backward,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*domain.ItemsCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", customErr.ErrInvalidInput)
+	}
+	var token cursorToken
+	if err := json.Unmarshal(data, &token); err != nil || token.ID <= 0 || token.Date.IsZero() {
+		return nil, fmt.Errorf("%w: malformed cursor", customErr.ErrInvalidInput)
+	}
+	return &domain.ItemsCursor{
+		Date:     token.Date,
+		ID:       token.ID,
+		Backward: token.Backward,
+	}, nil
+}
@@ -1,37 +1,118 @@
 package dto
 
-import "time"
+import (
+	"sales-tracker/internal/domain"
+	"time"
+)
 
 type CreateItemRequest struct {
-	Type        string  `json:"type" validate:"required,oneof=income expense"`
-	Amount      float64 `json:"amount" validate:"gte=0"`
-	Date        string  `json:"date" validate:"required"`
-	Category    string  `json:"category"`
-	Description string  `json:"description"`
+	Type        string       `json:"type" validate:"required,oneof=income expense transfer"`
+	Amount      domain.Money `json:"amount" validate:"gte=0"`
+	Currency    string       `json:"currency" validate:"omitempty,iso4217"`
+	Date        string       `json:"date" validate:"required"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	AccountID   *int64       `json:"account_id,omitempty"`
+	ToAccountID *int64       `json:"to_account_id,omitempty"`
 }
 
+// UpdateItemRequest — полное представление записи для PUT: отсутствующие
+// поля сбрасываются, валюта по умолчанию — RUB.
 type UpdateItemRequest struct {
-	Type        string   `json:"type,omitempty" validate:"omitempty,oneof=income expense"`
-	Amount      *float64 `json:"amount,omitempty" validate:"omitempty,gte=0"`
-	Date        string   `json:"date,omitempty"`
-	Category    string   `json:"category,omitempty"`
-	Description string   `json:"description,omitempty"`
+	Type        string       `json:"type"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	Date        string       `json:"date"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	AccountID   *int64       `json:"account_id"`
+	ToAccountID *int64       `json:"to_account_id"`
 }
 
 type ItemResponse struct {
-	ID          int64     `json:"id"`
-	Type        string    `json:"type"`
-	Amount      float64   `json:"amount"`
-	Date        time.Time `json:"date"`
-	Category    string    `json:"category"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64        `json:"id"`
+	Type        string       `json:"type"`
+	Amount      domain.Money `json:"amount"`
+	Currency    string       `json:"currency"`
+	Date        time.Time    `json:"date"`
+	Category    string       `json:"category"`
+	Description string       `json:"description"`
+	ExternalID  string       `json:"external_id,omitempty"`
+	AccountID   *int64       `json:"account_id,omitempty"`
+	ToAccountID *int64       `json:"to_account_id,omitempty"`
+	Version     int64        `json:"version"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty"`
 }
 
 type ItemsResponse struct {
-	Items []*ItemResponse `json:"items"`
-	Total int64           `json:"total"`
-	Page  int             `json:"page"`
-	Limit int             `json:"limit"`
+	Items      []*ItemResponse `json:"items"`
+	Total      *int64          `json:"total,omitempty"`
+	Page       int             `json:"page,omitempty"`
+	Limit      int             `json:"limit"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	PrevCursor string          `json:"prev_cursor,omitempty"`
+}
+
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportSkip struct {
+	Line       int    `json:"line"`
+	ExternalID string `json:"external_id"`
+	Reason     string `json:"reason"`
+}
+
+type ImportResponse struct {
+	Total    int               `json:"total"`
+	Valid    int               `json:"valid"`
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	DryRun   bool              `json:"dry_run"`
+	Rejected bool              `json:"rejected"`
+	Errors   []*ImportRowError `json:"errors"`
+	Skipped  []*ImportSkip     `json:"skipped"`
+}
+
+// BulkOperation — операция пакета: op = create|update|delete. item — полное
+// представление записи для create и update, version — проверка версии.
+type BulkOperation struct {
+	Op      string             `json:"op"`
+	ID      int64              `json:"id,omitempty"`
+	Version int64              `json:"version,omitempty"`
+	Item    *UpdateItemRequest `json:"item,omitempty"`
+}
+
+type BulkRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []*BulkOperation `json:"operations"`
+}
+
+// BulkOpResult — итог операции: status и error совпадают с тем, что вернул бы
+// одиночный запрос; 424 rolled_back — операция отменена вместе с пакетом,
+// 424 not_executed — пакет прерван до этой операции.
+type BulkOpResult struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	ID      int64  `json:"id,omitempty"`
+	Version int64  `json:"version,omitempty"`
+	Status  int    `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+type BulkResponse struct {
+	Atomic    bool            `json:"atomic"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Rejected  bool            `json:"rejected"`
+	Results   []*BulkOpResult `json:"results"`
+}
+
+type BulkFilterResponse struct {
+	Matched int64 `json:"matched"`
+	DryRun  bool  `json:"dry_run"`
 }
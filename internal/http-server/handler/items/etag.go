@@ -0,0 +1,45 @@
+package items_handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	customErr "sales-tracker/internal/domain/errors"
+)
+
+// itemETag — сильный ETag операции, построенный по её версии.
+func itemETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch возвращает версию из заголовка If-Match. Отсутствующий заголовок
+// и "*" дают 0 — запись без проверки версии. Слабые и чужие ETag не могут
+// совпасть с версией операции, поэтому для них сразу возвращается
+// ErrVersionMismatch.
+func parseIfMatch(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, fmt.Errorf("%w: If-Match must contain a single ETag", customErr.ErrInvalidInput)
+	}
+	if strings.HasPrefix(header, "W/") {
+		return 0, customErr.ErrVersionMismatch
+	}
+	tag, ok := strings.CutPrefix(header, `"`)
+	if !ok {
+		return 0, fmt.Errorf("%w: malformed If-Match", customErr.ErrInvalidInput)
+	}
+	tag, ok = strings.CutSuffix(tag, `"`)
+	if !ok {
+		return 0, fmt.Errorf("%w: malformed If-Match", customErr.ErrInvalidInput)
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, customErr.ErrVersionMismatch
+	}
+	return version, nil
+}
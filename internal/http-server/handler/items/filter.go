@@ -0,0 +1,67 @@
+package items_handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+)
+
+// parseItemsFilter читает параметры фильтрации списка:
+// type, category (повторяемый или через запятую), from, to (RFC3339),
+// min_amount, max_amount, q (полнотекстовый поиск), account_id (операции
+// счёта, включая переводы на него), sort и order (asc|desc).
+func parseItemsFilter(query url.Values) (*domain.ItemsFilter, error) {
+	filter := &domain.ItemsFilter{
+		Type:      query.Get("type"),
+		Search:    query.Get("q"),
+		SortBy:    query.Get("sort"),
+		SortOrder: strings.ToLower(query.Get("order")),
+	}
+
+	for _, value := range query["category"] {
+		for _, category := range strings.Split(value, ",") {
+			if category = strings.TrimSpace(category); category != "" {
+				filter.Categories = append(filter.Categories, category)
+			}
+		}
+	}
+
+	for name, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", customErr.ErrUnsupportedFormat, name, err)
+		}
+		*dst = &parsed
+	}
+
+	for name, dst := range map[string]**domain.Money{"min_amount": &filter.MinAmount, "max_amount": &filter.MaxAmount} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := domain.ParseMoney(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", customErr.ErrInvalidAmount, name, err)
+		}
+		*dst = &parsed
+	}
+
+	if value := query.Get("account_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%w: account_id must be a positive integer", customErr.ErrInvalidInput)
+		}
+		filter.AccountID = id
+	}
+
+	return filter, nil
+}
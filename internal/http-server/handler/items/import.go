@@ -0,0 +1,177 @@
+package items_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/items/dto"
+	"sales-tracker/internal/importer"
+)
+
+const maxImportSize = 32 << 20
+
+// ImportItems принимает CSV, XLSX или банковскую выписку файлом в поле "file"
+// multipart-формы либо телом запроса. Параметры передаются в query или полях
+// формы: format (csv|xlsx|ofx|qfx|qif|camt053|1c), delimiter, encoding,
+// mapping (JSON «поле → колонка»), sheet, dry_run, atomic, batch_size,
+// account_id (счёт для всех загружаемых операций).
+func (h *ItemsHandler) ImportItems(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var body io.Reader = r.Body
+	var filename string
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			h.logger.Warn().Err(err).Msg("Missing file in form")
+			h.writeError(w, customErr.ErrMissingParameter)
+			return
+		}
+		defer file.Close()
+		body = file
+		filename = header.Filename
+	}
+	param := func(name string) string {
+		if v := r.URL.Query().Get(name); v != "" {
+			return v
+		}
+		return r.FormValue(name)
+	}
+
+	opts, err := parseImportOptions(param)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid import options")
+		h.writeError(w, err)
+		return
+	}
+	var mapping map[string]string
+	if raw := param("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			h.logger.Warn().Err(err).Msg("Invalid column mapping")
+			h.writeError(w, fmt.Errorf("%w: mapping: %v", customErr.ErrInvalidInput, err))
+			return
+		}
+	}
+
+	format := strings.ToLower(param("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+		if format == "xml" {
+			format = importer.FormatCAMT053
+		}
+	}
+	delimiter, err := parseDelimiter(param("delimiter"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	var rows []*domain.ImportRow
+	switch {
+	case format == "" || format == "csv":
+		rows, err = importer.ParseCSV(body, importer.CSVOptions{
+			Delimiter: delimiter,
+			Encoding:  param("encoding"),
+			Mapping:   mapping,
+		})
+	case format == "xlsx":
+		rows, err = importer.ParseXLSX(body, importer.XLSXOptions{
+			Sheet:   param("sheet"),
+			Mapping: mapping,
+		})
+	case importer.IsStatementFormat(format):
+		rows, err = importer.ParseStatement(body, format, param("encoding"))
+	default:
+		err = fmt.Errorf("unsupported import format %q", format)
+	}
+	if err != nil {
+		h.logger.Warn().Err(err).Str("format", format).Msg("Failed to parse import file")
+		h.writeError(w, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err))
+		return
+	}
+
+	result, err := h.itemsUsecase.ImportItems(r.Context(), rows, opts)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("ImportItems failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.ImportResponse{
+		Total:    result.Total,
+		Valid:    result.Valid,
+		Imported: result.Imported,
+		Failed:   result.Failed,
+		DryRun:   result.DryRun,
+		Rejected: result.Rejected,
+		Errors:   make([]*dto.ImportRowError, len(result.Errors)),
+		Skipped:  make([]*dto.ImportSkip, len(result.Skipped)),
+	}
+	for i, e := range result.Errors {
+		resp.Errors[i] = &dto.ImportRowError{Line: e.Line, Error: e.Error}
+	}
+	for i, skip := range result.Skipped {
+		resp.Skipped[i] = &dto.ImportSkip{Line: skip.Line, ExternalID: skip.ExternalID, Reason: skip.Reason}
+	}
+	status := http.StatusOK
+	if result.Rejected {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+	h.logger.Info().
+		Int("imported", result.Imported).
+		Int("failed", result.Failed).
+		Int("skipped", len(result.Skipped)).
+		Bool("dry_run", result.DryRun).
+		Msg("Items import finished")
+}
+
+func parseImportOptions(param func(string) string) (*domain.ImportOptions, error) {
+	opts := &domain.ImportOptions{}
+	for name, dst := range map[string]*bool{"dry_run": &opts.DryRun, "atomic": &opts.Atomic} {
+		if v := param(name); v != "" {
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", customErr.ErrInvalidInput, name, err)
+			}
+			*dst = parsed
+		}
+	}
+	if v := param("batch_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: batch_size: %v", customErr.ErrInvalidInput, err)
+		}
+		opts.BatchSize = size
+	}
+	if v := param("account_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: account_id: %v", customErr.ErrInvalidInput, err)
+		}
+		opts.AccountID = id
+	}
+	return opts, nil
+}
+
+// parseDelimiter принимает один символ либо "tab"; пустое значение — автоопределение.
+func parseDelimiter(s string) (rune, error) {
+	switch s {
+	case "":
+		return 0, nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fmt.Errorf("%w: delimiter must be a single character", customErr.ErrInvalidInput)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return r, nil
+}
@@ -1,7 +1,6 @@
 package items_handler
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,10 +10,10 @@ import (
 
 	"sales-tracker/internal/domain"
 	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/export"
 	"sales-tracker/internal/http-server/handler/items/dto"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
 	"github.com/wb-go/wbf/zlog"
 )
 
@@ -33,6 +32,12 @@ func NewHandler(itemsUsecase itemsUsecase, analyticsUsecase analyticsUsecase, lo
 }
 
 func (h *ItemsHandler) writeError(w http.ResponseWriter, err error) {
+	code, s := errorStatus(err)
+	http.Error(w, s, code)
+}
+
+// errorStatus сопоставляет ошибку HTTP-статусу и коду ошибки в ответе.
+func errorStatus(err error) (int, string) {
 	code := http.StatusInternalServerError
 	s := "internal"
 	switch {
@@ -40,17 +45,33 @@ func (h *ItemsHandler) writeError(w http.ResponseWriter, err error) {
 		errors.Is(err, customErr.ErrInvalidAmount),
 		errors.Is(err, customErr.ErrInvalidItemType),
 		errors.Is(err, customErr.ErrInvalidDateRange),
-		errors.Is(err, customErr.ErrMissingParameter):
+		errors.Is(err, customErr.ErrMissingParameter),
+		errors.Is(err, customErr.ErrUnsupportedFormat):
 		code = http.StatusBadRequest
 		s = "bad_request"
+	case errors.Is(err, customErr.ErrRateNotFound):
+		code = http.StatusUnprocessableEntity
+		s = "exchange_rate_not_found"
+	case errors.Is(err, customErr.ErrIdempotencyKey):
+		code = http.StatusUnprocessableEntity
+		s = "idempotency_key_reused"
 	case errors.Is(err, customErr.ErrItemNotFound):
 		code = http.StatusNotFound
 		s = "not_found"
+	case errors.Is(err, customErr.ErrVersionMismatch):
+		code = http.StatusPreconditionFailed
+		s = "precondition_failed"
+	case errors.Is(err, customErr.ErrUnauthorized):
+		code = http.StatusUnauthorized
+		s = "unauthorized"
+	case errors.Is(err, customErr.ErrForbidden):
+		code = http.StatusForbidden
+		s = "forbidden"
 	case errors.Is(err, customErr.ErrDatabase):
 		code = http.StatusInternalServerError
 		s = "database_error"
 	}
-	http.Error(w, s, code)
+	return code, s
 }
 
 func (h *ItemsHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
@@ -66,7 +87,7 @@ func (h *ItemsHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if req.Amount <= 0 {
-		h.logger.Warn().Float64("amount", req.Amount).Msg("Invalid amount")
+		h.logger.Warn().Stringer("amount", req.Amount).Msg("Invalid amount")
 		h.writeError(w, customErr.ErrInvalidAmount)
 		return
 	}
@@ -79,11 +100,23 @@ func (h *ItemsHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
 	item := &domain.Item{
 		Type:        req.Type,
 		Amount:      req.Amount,
+		Currency:    req.Currency,
 		Date:        date,
 		Category:    req.Category,
 		Description: req.Description,
+		AccountID:   req.AccountID,
+		ToAccountID: req.ToAccountID,
+	}
+	var id int64
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		var replayed bool
+		id, replayed, err = h.itemsUsecase.CreateItemIdempotent(r.Context(), key, item)
+		if replayed {
+			w.Header().Set("Idempotent-Replayed", "true")
+		}
+	} else {
+		id, err = h.itemsUsecase.CreateItem(r.Context(), item)
 	}
-	id, err := h.itemsUsecase.CreateItem(r.Context(), item)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("CreateItem failed")
 		h.writeError(w, err)
@@ -118,34 +151,83 @@ func (h *ItemsHandler) GetItems(w http.ResponseWriter, r *http.Request) {
 			limit = l
 		}
 	}
+	filter, err := parseItemsFilter(r.URL.Query())
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid filter parameters")
+		h.writeError(w, err)
+		return
+	}
+	if r.URL.Query().Has("cursor") {
+		h.getItemsByCursor(w, r, filter, limit)
+		return
+	}
 	offset := (page - 1) * limit
-	items, total, err := h.itemsUsecase.GetItemsWithPagination(r.Context(), offset, limit)
+	items, total, err := h.itemsUsecase.GetItemsWithPagination(r.Context(), filter, offset, limit)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("GetItems failed")
 		h.writeError(w, err)
 		return
 	}
 	resp := dto.ItemsResponse{
-		Items: make([]*dto.ItemResponse, len(items)),
-		Total: total,
+		Items: toItemResponses(items),
+		Total: &total,
 		Page:  page,
 		Limit: limit,
 	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+	h.logger.Info().Int("count", len(items)).Int64("total", total).Msg("Items retrieved with pagination")
+}
+
+// getItemsByCursor обслуживает режим ?cursor=…: пустой курсор означает первую страницу,
+// total считается только при ?total=true.
+func (h *ItemsHandler) getItemsByCursor(w http.ResponseWriter, r *http.Request, filter *domain.ItemsFilter, limit int) {
+	cursor, err := decodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid cursor")
+		h.writeError(w, err)
+		return
+	}
+	withTotal, _ := strconv.ParseBool(r.URL.Query().Get("total"))
+	page, err := h.itemsUsecase.GetItemsByCursor(r.Context(), filter, cursor, limit, withTotal)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("GetItemsByCursor failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.ItemsResponse{
+		Items:      toItemResponses(page.Items),
+		Total:      page.Total,
+		Limit:      limit,
+		NextCursor: encodeCursor(page.NextCursor),
+		PrevCursor: encodeCursor(page.PrevCursor),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+	h.logger.Info().Int("count", len(page.Items)).Msg("Items retrieved by cursor")
+}
+
+func toItemResponses(items []*domain.Item) []*dto.ItemResponse {
+	resp := make([]*dto.ItemResponse, len(items))
 	for i, it := range items {
-		resp.Items[i] = &dto.ItemResponse{
+		resp[i] = &dto.ItemResponse{
 			ID:          it.ID,
 			Type:        it.Type,
 			Amount:      it.Amount,
+			Currency:    it.Currency,
 			Date:        it.Date,
 			Category:    it.Category,
 			Description: it.Description,
+			ExternalID:  it.ExternalID,
+			AccountID:   it.AccountID,
+			ToAccountID: it.ToAccountID,
+			Version:     it.Version,
 			CreatedAt:   it.CreatedAt,
 			UpdatedAt:   it.UpdatedAt,
+			DeletedAt:   it.DeletedAt,
 		}
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resp)
-	h.logger.Info().Int("count", len(items)).Int64("total", total).Msg("Items retrieved with pagination")
+	return resp
 }
 
 func (h *ItemsHandler) GetItemByID(w http.ResponseWriter, r *http.Request) {
@@ -166,13 +248,19 @@ func (h *ItemsHandler) GetItemByID(w http.ResponseWriter, r *http.Request) {
 		ID:          item.ID,
 		Type:        item.Type,
 		Amount:      item.Amount,
+		Currency:    item.Currency,
 		Date:        item.Date,
 		Category:    item.Category,
 		Description: item.Description,
+		ExternalID:  item.ExternalID,
+		AccountID:   item.AccountID,
+		ToAccountID: item.ToAccountID,
+		Version:     item.Version,
 		CreatedAt:   item.CreatedAt,
 		UpdatedAt:   item.UpdatedAt,
 	}
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", itemETag(item.Version))
 	json.NewEncoder(w).Encode(resp)
 	h.logger.Info().Int64("id", id).Msg("Item retrieved")
 }
@@ -185,50 +273,41 @@ func (h *ItemsHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, customErr.ErrInvalidInput)
 		return
 	}
+	expected, err := parseIfMatch(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Str("if_match", r.Header.Get("If-Match")).Msg("Invalid If-Match")
+		h.writeError(w, err)
+		return
+	}
 	var req dto.UpdateItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to decode request")
 		h.writeError(w, customErr.ErrInvalidInput)
 		return
 	}
-	validate := validator.New()
-	if err := validate.Struct(&req); err != nil {
-		h.logger.Error().Err(err).Msg("Validation failed")
-		h.writeError(w, customErr.ErrInvalidInput)
-		return
-	}
-	item, err := h.itemsUsecase.GetItemByID(r.Context(), id)
+	date, err := time.Parse(time.RFC3339, req.Date)
 	if err != nil {
-		h.logger.Error().Err(err).Msg("GetItemByID failed")
-		h.writeError(w, err)
+		h.logger.Warn().Err(err).Str("date", req.Date).Msg("Invalid date format")
+		h.writeError(w, customErr.ErrInvalidInput)
 		return
 	}
-	if req.Type != "" {
-		item.Type = req.Type
-	}
-	if req.Amount != nil {
-		item.Amount = *req.Amount
-	}
-	if req.Date != "" {
-		date, err := time.Parse(time.RFC3339, req.Date)
-		if err != nil {
-			h.writeError(w, customErr.ErrInvalidInput)
-			return
-		}
-		item.Date = date
-	}
-	if req.Category != "" {
-		item.Category = req.Category
-	}
-	if req.Description != "" {
-		item.Description = req.Description
+	item := &domain.Item{
+		Type:        req.Type,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Date:        date,
+		Category:    req.Category,
+		Description: req.Description,
+		AccountID:   req.AccountID,
+		ToAccountID: req.ToAccountID,
 	}
-	err = h.itemsUsecase.UpdateItem(r.Context(), id, item)
+	err = h.itemsUsecase.UpdateItem(r.Context(), id, item, expected)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("UpdateItem failed")
 		h.writeError(w, err)
 		return
 	}
+	w.Header().Set("ETag", itemETag(item.Version))
 	w.WriteHeader(http.StatusOK)
 	h.logger.Info().Int64("id", id).Msg("Item updated")
 }
@@ -241,7 +320,13 @@ func (h *ItemsHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, customErr.ErrInvalidInput)
 		return
 	}
-	err = h.itemsUsecase.DeleteItem(r.Context(), id)
+	expected, err := parseIfMatch(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Str("if_match", r.Header.Get("If-Match")).Msg("Invalid If-Match")
+		h.writeError(w, err)
+		return
+	}
+	err = h.itemsUsecase.DeleteItem(r.Context(), id, expected)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("DeleteItem failed")
 		h.writeError(w, err)
@@ -251,8 +336,8 @@ func (h *ItemsHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info().Int64("id", id).Msg("Item deleted")
 }
 
-func (h *ItemsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info().Msg("Exporting items to CSV")
+func (h *ItemsHandler) Export(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info().Msg("Exporting items")
 
 	fromStr := r.URL.Query().Get("from")
 	toStr := r.URL.Query().Get("to")
@@ -260,7 +345,8 @@ func (h *ItemsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	var from, to time.Time
 	var err error
 
-	if fromStr != "" && toStr != "" {
+	switch {
+	case fromStr != "" && toStr != "":
 		from, err = time.Parse(time.RFC3339, fromStr)
 		if err != nil {
 			h.logger.Warn().Err(err).Str("from", fromStr).Msg("Invalid from date format")
@@ -273,94 +359,91 @@ func (h *ItemsHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 			h.writeError(w, customErr.ErrUnsupportedFormat)
 			return
 		}
-	} else {
+	case fromStr != "" || toStr != "":
+		h.logger.Warn().Msg("Export period requires both from and to")
+		h.writeError(w, customErr.ErrMissingParameter)
+		return
+	default:
 		from = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 		to = time.Date(2100, 12, 31, 23, 59, 59, 0, time.UTC)
 	}
 
-	analytics, err := h.analyticsUsecase.GetAnalytics(r.Context(), from, to)
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	layout := r.URL.Query().Get("layout")
+	if layout == "" {
+		layout = export.LayoutReport
+	}
+	if err := export.Validate(format, layout); err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid export format")
+		h.writeError(w, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err))
+		return
+	}
+
+	ctx := r.Context()
+	params := &domain.AnalyticsParams{
+		From:        from,
+		To:          to,
+		Currency:    r.URL.Query().Get("currency"),
+		Compare:     r.URL.Query().Get("compare"),
+		SkipDetails: true,
+	}
+	analytics, err := h.analyticsUsecase.GetExportAnalytics(ctx, params)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to get analytics for export")
 		h.writeError(w, err)
 		return
 	}
 
-	items := analytics.Details
-
-	filename := fmt.Sprintf("sales_tracker_%s_%s.csv",
+	contentType, ext := export.ContentType(format)
+	filename := fmt.Sprintf("sales_tracker_%s_%s.%s",
 		from.Format("2006-01-02"),
-		to.Format("2006-01-02"))
-	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		to.Format("2006-01-02"),
+		ext)
+	w.Header().Set("Content-Type", contentType)
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 
-	w.Write([]byte("\xef\xbb\xbf"))
-
-	writer := csv.NewWriter(w)
-	writer.Comma = ';'
-	writer.UseCRLF = true
-
-	writer.Write([]string{"ОТЧЁТ SalesTracker"})
-	writer.Write([]string{fmt.Sprintf("Период: %s - %s",
-		from.Format("02.01.2006 15:04"),
-		to.Format("02.01.2006 15:04"))})
-	writer.Write([]string{""})
-
-	writer.Write([]string{"АНАЛИТИКА ДОХОДОВ"})
-	writer.Write([]string{"Сумма", "Среднее", "Количество", "Медиана", "90-й перцентиль"})
-	writer.Write([]string{
-		fmt.Sprintf("%.2f ₽", analytics.Income.Sum),
-		fmt.Sprintf("%.2f ₽", analytics.Income.Avg),
-		strconv.FormatInt(analytics.Income.Count, 10),
-		fmt.Sprintf("%.2f ₽", analytics.Income.Median),
-		fmt.Sprintf("%.2f ₽", analytics.Income.Percent90),
-	})
-	writer.Write([]string{""})
-
-	writer.Write([]string{"АНАЛИТИКА РАСХОДОВ"})
-	writer.Write([]string{"Сумма", "Среднее", "Количество", "Медиана", "90-й перцентиль"})
-	writer.Write([]string{
-		fmt.Sprintf("%.2f ₽", analytics.Expense.Sum),
-		fmt.Sprintf("%.2f ₽", analytics.Expense.Avg),
-		strconv.FormatInt(analytics.Expense.Count, 10),
-		fmt.Sprintf("%.2f ₽", analytics.Expense.Median),
-		fmt.Sprintf("%.2f ₽", analytics.Expense.Percent90),
-	})
-	writer.Write([]string{""})
-
-	writer.Write([]string{"ОПЕРАЦИИ"})
-	headers := []string{"ID", "Тип", "Сумма", "Дата", "Категория", "Описание", "Создано", "Обновлено"}
-	writer.Write(headers)
-
-	for _, item := range items {
-		row := []string{
-			strconv.FormatInt(item.ID, 10),
-			h.getTypeLabel(item.Type),
-			fmt.Sprintf("%.2f", item.Amount),
-			item.Date.Format("02.01.2006 15:04"),
-			item.Category,
-			item.Description,
-			item.CreatedAt.Format("02.01.2006 15:04"),
-			item.UpdatedAt.Format("02.01.2006 15:04"),
+	stream := newExportStream(w)
+	report, err := export.New(w, format, layout)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to start export")
+		return
+	}
+	if err := report.WriteSummary(analytics, from, to); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to write export summary")
+		return
+	}
+	var count int
+	err = h.analyticsUsecase.StreamDetails(ctx, params, func(item *domain.Item) error {
+		if err := report.WriteItem(item); err != nil {
+			return err
 		}
-		writer.Write(row)
+		count++
+		if count%exportFlushRows == 0 {
+			if err := report.Flush(); err != nil {
+				return err
+			}
+			return stream.flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Заголовки уже отправлены: клиент получит оборванный файл.
+		h.logger.Error().Err(err).Int("count", count).Msg("Export interrupted")
+		return
 	}
-
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		h.logger.Error().Err(err).Msg("Failed to flush CSV")
+	if err := report.Close(); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to finish export")
 		return
 	}
 
 	h.logger.Info().
-		Int("count", len(items)).
+		Int("count", count).
 		Time("from", from).
 		Time("to", to).
-		Msg("Report exported to CSV")
-}
-
-func (h *ItemsHandler) getTypeLabel(typeStr string) string {
-	if typeStr == "income" {
-		return "Доход"
-	}
-	return "Расход"
+		Str("format", format).
+		Str("layout", layout).
+		Msg("Items exported")
 }
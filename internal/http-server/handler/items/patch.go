@@ -0,0 +1,133 @@
+package items_handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// PatchItem применяет к записи JSON Merge Patch (RFC 7396): переданные поля
+// заменяются, null очищает category и description и сбрасывает currency
+// к базовой валюте. Отвечает итоговым состоянием записи и её новым ETag.
+func (h *ItemsHandler) PatchItem(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		h.logger.Error().Err(err).Str("id", idStr).Msg("Invalid ID")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, _ := mime.ParseMediaType(ct)
+		if mediaType != mergePatchContentType && mediaType != "application/json" {
+			h.logger.Warn().Str("content_type", ct).Msg("Unsupported patch media type")
+			w.Header().Set("Accept-Patch", mergePatchContentType)
+			http.Error(w, "unsupported_media_type", http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+	expected, err := parseIfMatch(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Str("if_match", r.Header.Get("If-Match")).Msg("Invalid If-Match")
+		h.writeError(w, err)
+		return
+	}
+	patch, err := decodeMergePatch(r)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Invalid merge patch")
+		h.writeError(w, err)
+		return
+	}
+	item, err := h.itemsUsecase.PatchItem(r.Context(), id, patch, expected)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("PatchItem failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", itemETag(item.Version))
+	json.NewEncoder(w).Encode(toItemResponses([]*domain.Item{item})[0])
+	h.logger.Info().Int64("id", id).Int64("version", item.Version).Msg("Item patched")
+}
+
+// decodeMergePatch разбирает документ патча. Неизвестные и служебные поля
+// отклоняются, как и null для обязательных полей.
+func decodeMergePatch(r *http.Request) (*domain.ItemPatch, error) {
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: patch must be a JSON object: %v", customErr.ErrInvalidInput, err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("%w: patch must be a JSON object", customErr.ErrInvalidInput)
+	}
+	patch := &domain.ItemPatch{}
+	for field, raw := range doc {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		var err error
+		switch field {
+		case "type":
+			if isNull {
+				return nil, fmt.Errorf("%w: type cannot be null", customErr.ErrInvalidInput)
+			}
+			patch.Type = new(string)
+			err = json.Unmarshal(raw, patch.Type)
+		case "amount":
+			if isNull {
+				return nil, fmt.Errorf("%w: amount cannot be null", customErr.ErrInvalidInput)
+			}
+			patch.Amount = new(domain.Money)
+			err = json.Unmarshal(raw, patch.Amount)
+		case "date":
+			if isNull {
+				return nil, fmt.Errorf("%w: date cannot be null", customErr.ErrInvalidInput)
+			}
+			var s string
+			if err = json.Unmarshal(raw, &s); err == nil {
+				var date time.Time
+				date, err = time.Parse(time.RFC3339, s)
+				patch.Date = &date
+			}
+		case "currency", "category", "description":
+			value := new(string)
+			if !isNull {
+				err = json.Unmarshal(raw, value)
+			}
+			switch field {
+			case "currency":
+				patch.Currency = value
+			case "category":
+				patch.Category = value
+			case "description":
+				patch.Description = value
+			}
+		case "account_id", "to_account_id":
+			// null снимает привязку к счёту; в патче она выражается нулём.
+			value := new(int64)
+			if !isNull {
+				err = json.Unmarshal(raw, value)
+			}
+			if field == "account_id" {
+				patch.AccountID = value
+			} else {
+				patch.ToAccountID = value
+			}
+		default:
+			return nil, fmt.Errorf("%w: field %q cannot be patched", customErr.ErrInvalidInput, field)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", customErr.ErrInvalidInput, field, err)
+		}
+	}
+	return patch, nil
+}
@@ -0,0 +1,39 @@
+package items_handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// exportFlushRows — сколько строк экспорта буферизуется перед отправкой клиенту.
+	exportFlushRows = 500
+	// exportChunkTimeout — запас времени на запись очередной порции экспорта.
+	// Дедлайн сдвигается после каждой порции, поэтому длинный экспорт
+	// не упирается в SERVER_WRITE_TIMEOUT, пока клиент читает данные.
+	exportChunkTimeout = 30 * time.Second
+)
+
+// exportStream отправляет накопленные данные ответа и продлевает дедлайн записи.
+type exportStream struct {
+	rc *http.ResponseController
+}
+
+func newExportStream(w http.ResponseWriter) *exportStream {
+	s := &exportStream{rc: http.NewResponseController(w)}
+	s.extendDeadline()
+	return s
+}
+
+func (s *exportStream) flush() error {
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	s.extendDeadline()
+	return nil
+}
+
+func (s *exportStream) extendDeadline() {
+	s.rc.SetWriteDeadline(time.Now().Add(exportChunkTimeout))
+}
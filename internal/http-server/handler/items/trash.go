@@ -0,0 +1,73 @@
+package items_handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/items/dto"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetTrash возвращает удалённые записи, начиная с удалённых последними.
+// Параметры page и limit — как у GET /items.
+func (h *ItemsHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	page, limit := 1, 25
+	if v := r.URL.Query().Get("page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p <= 0 {
+			h.logger.Warn().Str("page", v).Msg("Invalid page parameter")
+			h.writeError(w, customErr.ErrInvalidInput)
+			return
+		}
+		page = p
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 || l > 100 {
+			h.logger.Warn().Str("limit", v).Msg("Invalid limit parameter")
+			h.writeError(w, customErr.ErrInvalidInput)
+			return
+		}
+		limit = l
+	}
+	items, total, err := h.itemsUsecase.GetTrash(r.Context(), (page-1)*limit, limit)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("GetTrash failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.ItemsResponse{
+		Items: toItemResponses(items),
+		Total: &total,
+		Page:  page,
+		Limit: limit,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+	h.logger.Info().Int("count", len(items)).Int64("total", total).Msg("Trash retrieved")
+}
+
+// RestoreItem возвращает запись из корзины и отвечает её новым состоянием.
+func (h *ItemsHandler) RestoreItem(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		h.logger.Error().Err(err).Str("id", idStr).Msg("Invalid ID")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	item, err := h.itemsUsecase.RestoreItem(r.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("RestoreItem failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", itemETag(item.Version))
+	json.NewEncoder(w).Encode(toItemResponses([]*domain.Item{item})[0])
+	h.logger.Info().Int64("id", id).Msg("Item restored")
+}
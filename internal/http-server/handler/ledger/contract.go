@@ -0,0 +1,19 @@
+package ledger_handler
+
+import (
+	"context"
+	"sales-tracker/internal/domain"
+	"time"
+)
+
+type ledgerUsecase interface {
+	ListAccounts(ctx context.Context) ([]*domain.LedgerAccount, error)
+	CreateAccount(ctx context.Context, account *domain.LedgerAccount) error
+	DeleteAccount(ctx context.Context, id int64) error
+	CreateEntry(ctx context.Context, entry *domain.JournalEntry) error
+	ListEntries(ctx context.Context, filter *domain.JournalFilter) ([]*domain.JournalEntry, int64, error)
+	DeleteEntry(ctx context.Context, id int64) error
+	GetTrialBalance(ctx context.Context, to *time.Time) ([]*domain.TrialBalance, error)
+	GetBalanceSheet(ctx context.Context, to *time.Time) ([]*domain.BalanceSheet, error)
+	GetProfitAndLoss(ctx context.Context, params *domain.ProfitAndLossParams) (*domain.ProfitAndLoss, error)
+}
@@ -0,0 +1,125 @@
+package dto
+
+import (
+	"sales-tracker/internal/domain"
+	"time"
+)
+
+type CreateLedgerAccountRequest struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Category string `json:"category"`
+}
+
+type LedgerAccountResponse struct {
+	ID        int64     `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	System    string    `json:"system,omitempty"`
+	AccountID *int64    `json:"account_id,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LedgerAccountsResponse struct {
+	Accounts []*LedgerAccountResponse `json:"accounts"`
+}
+
+// PostingDTO — строка проводки: задаётся либо debit, либо credit.
+type PostingDTO struct {
+	LedgerAccountID int64        `json:"ledger_account_id"`
+	Debit           domain.Money `json:"debit"`
+	Credit          domain.Money `json:"credit"`
+}
+
+type CreateJournalEntryRequest struct {
+	Date        time.Time     `json:"date"`
+	Currency    string        `json:"currency"`
+	Description string        `json:"description"`
+	Postings    []*PostingDTO `json:"postings"`
+}
+
+type JournalEntryResponse struct {
+	ID          int64         `json:"id"`
+	Date        time.Time     `json:"date"`
+	Currency    string        `json:"currency"`
+	Description string        `json:"description"`
+	Source      string        `json:"source"`
+	ItemID      *int64        `json:"item_id,omitempty"`
+	AccountID   *int64        `json:"account_id,omitempty"`
+	Postings    []*PostingDTO `json:"postings"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type JournalResponse struct {
+	Entries []*JournalEntryResponse `json:"entries"`
+	Total   int64                   `json:"total"`
+	Page    int                     `json:"page"`
+	Limit   int                     `json:"limit"`
+}
+
+type TrialBalanceRow struct {
+	Account *LedgerAccountResponse `json:"account"`
+	Debit   domain.Money           `json:"debit"`
+	Credit  domain.Money           `json:"credit"`
+}
+
+type TrialBalance struct {
+	Currency    string             `json:"currency"`
+	Rows        []*TrialBalanceRow `json:"rows"`
+	TotalDebit  domain.Money       `json:"total_debit"`
+	TotalCredit domain.Money       `json:"total_credit"`
+	Balanced    bool               `json:"balanced"`
+}
+
+type TrialBalanceResponse struct {
+	Balances []*TrialBalance `json:"balances"`
+}
+
+// LedgerLine — сальдо или оборот счёта в отчёте.
+type LedgerLine struct {
+	Account *LedgerAccountResponse `json:"account"`
+	Amount  domain.Money           `json:"amount"`
+}
+
+type BalanceSheet struct {
+	Currency         string        `json:"currency"`
+	Assets           []*LedgerLine `json:"assets"`
+	Liabilities      []*LedgerLine `json:"liabilities"`
+	Equity           []*LedgerLine `json:"equity"`
+	RetainedEarnings domain.Money  `json:"retained_earnings"`
+	TotalAssets      domain.Money  `json:"total_assets"`
+	TotalLiabilities domain.Money  `json:"total_liabilities"`
+	TotalEquity      domain.Money  `json:"total_equity"`
+	Balanced         bool          `json:"balanced"`
+}
+
+type BalanceSheetResponse struct {
+	Sheets []*BalanceSheet `json:"sheets"`
+}
+
+// Reconciliation — сверка с /analytics: *_difference равны нулю, если
+// доходы и расходы учёта без ручных проводок совпадают с аналитикой.
+type Reconciliation struct {
+	AnalyticsIncome   domain.Money `json:"analytics_income"`
+	AnalyticsExpense  domain.Money `json:"analytics_expense"`
+	ManualIncome      domain.Money `json:"manual_income"`
+	ManualExpense     domain.Money `json:"manual_expense"`
+	IncomeDifference  domain.Money `json:"income_difference"`
+	ExpenseDifference domain.Money `json:"expense_difference"`
+	Reconciled        bool         `json:"reconciled"`
+}
+
+type ProfitAndLossResponse struct {
+	Currency       string          `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	Income         []*LedgerLine   `json:"income"`
+	Expense        []*LedgerLine   `json:"expense"`
+	TotalIncome    domain.Money    `json:"total_income"`
+	TotalExpense   domain.Money    `json:"total_expense"`
+	NetIncome      domain.Money    `json:"net_income"`
+	Reconciliation *Reconciliation `json:"reconciliation"`
+}
@@ -0,0 +1,381 @@
+package ledger_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/ledger/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type LedgerHandler struct {
+	ledgerUsecase ledgerUsecase
+	logger        *zlog.Zerolog
+}
+
+func NewHandler(ledgerUsecase ledgerUsecase, logger *zlog.Zerolog) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerUsecase: ledgerUsecase,
+		logger:        logger,
+	}
+}
+
+func (h *LedgerHandler) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	s := "internal"
+	switch {
+	case errors.Is(err, customErr.ErrInvalidInput),
+		errors.Is(err, customErr.ErrInvalidDateRange),
+		errors.Is(err, customErr.ErrMissingParameter),
+		errors.Is(err, customErr.ErrUnsupportedFormat),
+		errors.Is(err, customErr.ErrPeriodTooLarge):
+		code = http.StatusBadRequest
+		s = "bad_request"
+	case errors.Is(err, customErr.ErrLedgerAccountNotFound),
+		errors.Is(err, customErr.ErrJournalEntryNotFound):
+		code = http.StatusNotFound
+		s = "not_found"
+	case errors.Is(err, customErr.ErrLedgerAccountExists):
+		code = http.StatusConflict
+		s = "already_exists"
+	case errors.Is(err, customErr.ErrLedgerAccountInUse):
+		code = http.StatusConflict
+		s = "account_in_use"
+	case errors.Is(err, customErr.ErrLedgerDisabled):
+		code = http.StatusConflict
+		s = "ledger_disabled"
+	case errors.Is(err, customErr.ErrRateNotFound):
+		code = http.StatusUnprocessableEntity
+		s = "exchange_rate_not_found"
+	case errors.Is(err, customErr.ErrUnauthorized):
+		code = http.StatusUnauthorized
+		s = "unauthorized"
+	case errors.Is(err, customErr.ErrForbidden):
+		code = http.StatusForbidden
+		s = "forbidden"
+	case errors.Is(err, customErr.ErrDatabase):
+		code = http.StatusInternalServerError
+		s = "database_error"
+	}
+	http.Error(w, s, code)
+}
+
+func (h *LedgerHandler) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func toAccountResponse(account *domain.LedgerAccount) *dto.LedgerAccountResponse {
+	return &dto.LedgerAccountResponse{
+		ID:        account.ID,
+		Code:      account.Code,
+		Name:      account.Name,
+		Type:      account.Type,
+		System:    account.System,
+		AccountID: account.AccountID,
+		Category:  account.Category,
+		CreatedAt: account.CreatedAt,
+	}
+}
+
+func toLines(lines []*domain.LedgerLine) []*dto.LedgerLine {
+	resp := make([]*dto.LedgerLine, len(lines))
+	for i, l := range lines {
+		resp[i] = &dto.LedgerLine{Account: toAccountResponse(l.Account), Amount: l.Amount}
+	}
+	return resp
+}
+
+func (h *LedgerHandler) pathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		h.logger.Warn().Str("id", idStr).Msg("Invalid ID")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return 0, false
+	}
+	return id, true
+}
+
+// parseDates читает необязательные даты names в формате RFC3339.
+func (h *LedgerHandler) parseDates(w http.ResponseWriter, r *http.Request, dates map[string]**time.Time) bool {
+	for name, dst := range dates {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.logger.Warn().Err(err).Str(name, value).Msg("Invalid date format")
+			h.writeError(w, customErr.ErrUnsupportedFormat)
+			return false
+		}
+		*dst = &parsed
+	}
+	return true
+}
+
+func (h *LedgerHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.ledgerUsecase.ListAccounts(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("ListAccounts failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.LedgerAccountsResponse{Accounts: make([]*dto.LedgerAccountResponse, len(accounts))}
+	for i, a := range accounts {
+		resp.Accounts[i] = toAccountResponse(a)
+	}
+	h.writeJSON(w, resp)
+}
+
+func (h *LedgerHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateLedgerAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode request")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	account := &domain.LedgerAccount{
+		Code:     req.Code,
+		Name:     req.Name,
+		Type:     req.Type,
+		Category: req.Category,
+	}
+	if err := h.ledgerUsecase.CreateAccount(r.Context(), account); err != nil {
+		h.logger.Error().Err(err).Msg("CreateAccount failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toAccountResponse(account))
+}
+
+func (h *LedgerHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.ledgerUsecase.DeleteAccount(r.Context(), id); err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("DeleteAccount failed")
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *LedgerHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateJournalEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode request")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	entry := &domain.JournalEntry{
+		Date:        req.Date,
+		Currency:    req.Currency,
+		Description: req.Description,
+		Postings:    make([]*domain.Posting, len(req.Postings)),
+	}
+	for i, p := range req.Postings {
+		if p == nil {
+			h.writeError(w, customErr.ErrInvalidInput)
+			return
+		}
+		entry.Postings[i] = &domain.Posting{LedgerAccountID: p.LedgerAccountID, Debit: p.Debit, Credit: p.Credit}
+	}
+	if err := h.ledgerUsecase.CreateEntry(r.Context(), entry); err != nil {
+		h.logger.Error().Err(err).Msg("CreateEntry failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toEntryResponse(entry))
+}
+
+func toEntryResponse(e *domain.JournalEntry) *dto.JournalEntryResponse {
+	resp := &dto.JournalEntryResponse{
+		ID:          e.ID,
+		Date:        e.Date,
+		Currency:    e.Currency,
+		Description: e.Description,
+		Source:      e.Source,
+		ItemID:      e.ItemID,
+		AccountID:   e.AccountID,
+		CreatedAt:   e.CreatedAt,
+		Postings:    make([]*dto.PostingDTO, len(e.Postings)),
+	}
+	for i, p := range e.Postings {
+		resp.Postings[i] = &dto.PostingDTO{LedgerAccountID: p.LedgerAccountID, Debit: p.Debit, Credit: p.Credit}
+	}
+	return resp
+}
+
+// ListEntries возвращает журнал проводок: from и to (RFC3339) ограничивают
+// период, page и limit — страницу.
+func (h *LedgerHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	filter := &domain.JournalFilter{}
+	if !h.parseDates(w, r, map[string]**time.Time{"from": &filter.From, "to": &filter.To}) {
+		return
+	}
+	query := r.URL.Query()
+	page, limit := 1, 100
+	if v := query.Get("page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p <= 0 {
+			h.logger.Warn().Str("page", v).Msg("Invalid page parameter")
+			h.writeError(w, customErr.ErrInvalidInput)
+			return
+		}
+		page = p
+	}
+	if v := query.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			h.logger.Warn().Str("limit", v).Msg("Invalid limit parameter")
+			h.writeError(w, customErr.ErrInvalidInput)
+			return
+		}
+		limit = l
+	}
+	filter.Offset, filter.Limit = (page-1)*limit, limit
+
+	entries, total, err := h.ledgerUsecase.ListEntries(r.Context(), filter)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("ListEntries failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.JournalResponse{
+		Entries: make([]*dto.JournalEntryResponse, len(entries)),
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}
+	for i, e := range entries {
+		resp.Entries[i] = toEntryResponse(e)
+	}
+	h.writeJSON(w, resp)
+}
+
+func (h *LedgerHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.ledgerUsecase.DeleteEntry(r.Context(), id); err != nil {
+		h.logger.Error().Err(err).Int64("id", id).Msg("DeleteEntry failed")
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTrialBalance возвращает оборотно-сальдовые ведомости на дату to
+// (RFC3339, по умолчанию — на текущий момент).
+func (h *LedgerHandler) GetTrialBalance(w http.ResponseWriter, r *http.Request) {
+	var to *time.Time
+	if !h.parseDates(w, r, map[string]**time.Time{"to": &to}) {
+		return
+	}
+	balances, err := h.ledgerUsecase.GetTrialBalance(r.Context(), to)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("GetTrialBalance failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.TrialBalanceResponse{Balances: make([]*dto.TrialBalance, len(balances))}
+	for i, tb := range balances {
+		out := &dto.TrialBalance{
+			Currency:    tb.Currency,
+			Rows:        make([]*dto.TrialBalanceRow, len(tb.Rows)),
+			TotalDebit:  tb.TotalDebit,
+			TotalCredit: tb.TotalCredit,
+			Balanced:    tb.Balanced(),
+		}
+		for j, row := range tb.Rows {
+			out.Rows[j] = &dto.TrialBalanceRow{Account: toAccountResponse(row.Account), Debit: row.Debit, Credit: row.Credit}
+		}
+		resp.Balances[i] = out
+	}
+	h.writeJSON(w, resp)
+}
+
+// GetBalanceSheet возвращает балансы по валютам на дату to (RFC3339).
+func (h *LedgerHandler) GetBalanceSheet(w http.ResponseWriter, r *http.Request) {
+	var to *time.Time
+	if !h.parseDates(w, r, map[string]**time.Time{"to": &to}) {
+		return
+	}
+	sheets, err := h.ledgerUsecase.GetBalanceSheet(r.Context(), to)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("GetBalanceSheet failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.BalanceSheetResponse{Sheets: make([]*dto.BalanceSheet, len(sheets))}
+	for i, s := range sheets {
+		resp.Sheets[i] = &dto.BalanceSheet{
+			Currency:         s.Currency,
+			Assets:           toLines(s.Assets),
+			Liabilities:      toLines(s.Liabilities),
+			Equity:           toLines(s.Equity),
+			RetainedEarnings: s.RetainedEarnings,
+			TotalAssets:      s.TotalAssets,
+			TotalLiabilities: s.TotalLiabilities,
+			TotalEquity:      s.TotalEquity,
+			Balanced:         s.Balanced(),
+		}
+	}
+	h.writeJSON(w, resp)
+}
+
+// GetProfitAndLoss возвращает отчёт о прибылях и убытках: from и to
+// (RFC3339) обязательны, currency — валюта отчёта, по умолчанию базовая.
+func (h *LedgerHandler) GetProfitAndLoss(w http.ResponseWriter, r *http.Request) {
+	var from, to *time.Time
+	if !h.parseDates(w, r, map[string]**time.Time{"from": &from, "to": &to}) {
+		return
+	}
+	if from == nil || to == nil {
+		h.logger.Warn().Msg("Missing required parameters")
+		h.writeError(w, customErr.ErrMissingParameter)
+		return
+	}
+	params := &domain.ProfitAndLossParams{From: *from, To: *to, Currency: r.URL.Query().Get("currency")}
+	pl, err := h.ledgerUsecase.GetProfitAndLoss(r.Context(), params)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("GetProfitAndLoss failed")
+		h.writeError(w, err)
+		return
+	}
+	rec := pl.Reconciliation
+	h.writeJSON(w, dto.ProfitAndLossResponse{
+		Currency:     pl.Currency,
+		From:         pl.From,
+		To:           pl.To,
+		Income:       toLines(pl.Income),
+		Expense:      toLines(pl.Expense),
+		TotalIncome:  pl.TotalIncome,
+		TotalExpense: pl.TotalExpense,
+		NetIncome:    pl.NetIncome(),
+		Reconciliation: &dto.Reconciliation{
+			AnalyticsIncome:   rec.AnalyticsIncome,
+			AnalyticsExpense:  rec.AnalyticsExpense,
+			ManualIncome:      rec.ManualIncome,
+			ManualExpense:     rec.ManualExpense,
+			IncomeDifference:  rec.IncomeDifference,
+			ExpenseDifference: rec.ExpenseDifference,
+			Reconciled:        rec.Reconciled(),
+		},
+	})
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "encoding/json"
+
+type RateRequest struct {
+	Currency string      `json:"currency" validate:"required"`
+	Date     string      `json:"date" validate:"required"`
+	Rate     json.Number `json:"rate" validate:"required"`
+}
+
+type RateResponse struct {
+	Currency string `json:"currency"`
+	Date     string `json:"date"`
+	Rate     string `json:"rate"`
+}
+
+type RatesResponse struct {
+	Base  string          `json:"base"`
+	Rates []*RateResponse `json:"rates"`
+}
+
+type ImportResponse struct {
+	Imported int `json:"imported"`
+}
@@ -0,0 +1,214 @@
+package rates_handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/rates/dto"
+
+	"github.com/wb-go/wbf/zlog"
+)
+
+const maxImportSize = 10 << 20
+
+type RatesHandler struct {
+	ratesUsecase ratesUsecase
+	logger       *zlog.Zerolog
+}
+
+func NewHandler(ratesUsecase ratesUsecase, logger *zlog.Zerolog) *RatesHandler {
+	return &RatesHandler{
+		ratesUsecase: ratesUsecase,
+		logger:       logger,
+	}
+}
+
+func (h *RatesHandler) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	s := "internal"
+	switch {
+	case errors.Is(err, customErr.ErrInvalidInput),
+		errors.Is(err, customErr.ErrInvalidDateRange),
+		errors.Is(err, customErr.ErrMissingParameter),
+		errors.Is(err, customErr.ErrUnsupportedFormat):
+		code = http.StatusBadRequest
+		s = "bad_request"
+	case errors.Is(err, customErr.ErrUnauthorized):
+		code = http.StatusUnauthorized
+		s = "unauthorized"
+	case errors.Is(err, customErr.ErrForbidden):
+		code = http.StatusForbidden
+		s = "forbidden"
+	case errors.Is(err, customErr.ErrDatabase):
+		code = http.StatusInternalServerError
+		s = "database_error"
+	}
+	http.Error(w, s, code)
+}
+
+func (h *RatesHandler) GetRates(w http.ResponseWriter, r *http.Request) {
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2100, 12, 31, 0, 0, 0, 0, time.UTC)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		parsed, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			h.logger.Warn().Err(err).Str("from", fromStr).Msg("Invalid from date format")
+			h.writeError(w, customErr.ErrUnsupportedFormat)
+			return
+		}
+		from = parsed
+	}
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		parsed, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			h.logger.Warn().Err(err).Str("to", toStr).Msg("Invalid to date format")
+			h.writeError(w, customErr.ErrUnsupportedFormat)
+			return
+		}
+		to = parsed
+	}
+	rates, err := h.ratesUsecase.GetRates(r.Context(), r.URL.Query().Get("currency"), from, to)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("GetRates failed")
+		h.writeError(w, err)
+		return
+	}
+	resp := dto.RatesResponse{
+		Base:  domain.BaseCurrency,
+		Rates: make([]*dto.RateResponse, len(rates)),
+	}
+	for i, rate := range rates {
+		resp.Rates[i] = &dto.RateResponse{
+			Currency: rate.Currency,
+			Date:     rate.Date.Format(time.DateOnly),
+			Rate:     rate.Rate,
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+	h.logger.Info().Int("count", len(rates)).Msg("Exchange rates retrieved")
+}
+
+func (h *RatesHandler) UpsertRates(w http.ResponseWriter, r *http.Request) {
+	var req []dto.RateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode request")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	rates := make([]*domain.ExchangeRate, 0, len(req))
+	for _, rr := range req {
+		rate, err := parseRate(rr.Currency, rr.Date, rr.Rate.String())
+		if err != nil {
+			h.logger.Warn().Err(err).Msg("Invalid exchange rate")
+			h.writeError(w, err)
+			return
+		}
+		rates = append(rates, rate)
+	}
+	if err := h.ratesUsecase.UpsertRates(r.Context(), rates); err != nil {
+		h.logger.Error().Err(err).Msg("UpsertRates failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ImportResponse{Imported: len(rates)})
+	h.logger.Info().Int("count", len(rates)).Msg("Exchange rates upserted")
+}
+
+// ImportRates принимает CSV с колонками currency, date, rate — файлом в поле
+// "file" multipart-формы или телом запроса. Разделитель: запятая или точка с запятой.
+func (h *RatesHandler) ImportRates(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			h.logger.Warn().Err(err).Msg("Missing file in form")
+			h.writeError(w, customErr.ErrMissingParameter)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+	rates, err := parseRatesCSV(body)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Failed to parse exchange rates file")
+		h.writeError(w, err)
+		return
+	}
+	if err := h.ratesUsecase.UpsertRates(r.Context(), rates); err != nil {
+		h.logger.Error().Err(err).Msg("ImportRates failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.ImportResponse{Imported: len(rates)})
+	h.logger.Info().Int("count", len(rates)).Msg("Exchange rates imported")
+}
+
+func parseRatesCSV(r io.Reader) ([]*domain.ExchangeRate, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	text := strings.TrimPrefix(string(data), "\xef\xbb\xbf")
+	firstLine, _, _ := strings.Cut(text, "\n")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	if strings.Contains(firstLine, ";") {
+		reader.Comma = ';'
+	}
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrUnsupportedFormat, err)
+	}
+	if len(records) < 2 {
+		return nil, customErr.ErrMissingParameter
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"currency", "date", "rate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", customErr.ErrUnsupportedFormat, name)
+		}
+	}
+
+	rates := make([]*domain.ExchangeRate, 0, len(records)-1)
+	for line, record := range records[1:] {
+		rate, err := parseRate(
+			record[columns["currency"]],
+			record[columns["date"]],
+			strings.Replace(record[columns["rate"]], ",", ".", 1),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func parseRate(currency, date, rate string) (*domain.ExchangeRate, error) {
+	parsed, err := time.Parse(time.DateOnly, strings.TrimSpace(date))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrUnsupportedFormat, err)
+	}
+	return &domain.ExchangeRate{
+		Currency: strings.TrimSpace(currency),
+		Date:     parsed,
+		Rate:     strings.TrimSpace(rate),
+	}, nil
+}
@@ -0,0 +1,13 @@
+package reports_handler
+
+import (
+	"context"
+	"io"
+	"sales-tracker/internal/domain"
+)
+
+type reportsUsecase interface {
+	CreateReport(ctx context.Context, job *domain.ReportJob) error
+	GetReport(ctx context.Context, id string) (*domain.ReportJob, error)
+	OpenArtifact(ctx context.Context, id string) (*domain.ReportJob, io.ReadSeekCloser, error)
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type CreateReportRequest struct {
+	Kind        string    `json:"kind"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Currency    string    `json:"currency"`
+	Compare     string    `json:"compare"`
+	Percentiles []float64 `json:"percentiles"`
+	Histogram   string    `json:"histogram"`
+	Bins        int       `json:"bins"`
+}
+
+type ReportResponse struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Status      string     `json:"status"`
+	Progress    int        `json:"progress"`
+	Error       string     `json:"error,omitempty"`
+	From        time.Time  `json:"from"`
+	To          time.Time  `json:"to"`
+	Currency    string     `json:"currency"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+}
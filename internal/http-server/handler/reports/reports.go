@@ -0,0 +1,163 @@
+package reports_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/http-server/handler/reports/dto"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type ReportsHandler struct {
+	reportsUsecase reportsUsecase
+	logger         *zlog.Zerolog
+}
+
+func NewHandler(reportsUsecase reportsUsecase, logger *zlog.Zerolog) *ReportsHandler {
+	return &ReportsHandler{
+		reportsUsecase: reportsUsecase,
+		logger:         logger,
+	}
+}
+
+func (h *ReportsHandler) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	s := "internal"
+	switch {
+	case errors.Is(err, customErr.ErrInvalidInput),
+		errors.Is(err, customErr.ErrInvalidDateRange),
+		errors.Is(err, customErr.ErrMissingParameter),
+		errors.Is(err, customErr.ErrUnsupportedFormat):
+		code = http.StatusBadRequest
+		s = "bad_request"
+	case errors.Is(err, customErr.ErrReportNotFound):
+		code = http.StatusNotFound
+		s = "not_found"
+	case errors.Is(err, customErr.ErrReportNotReady):
+		code = http.StatusConflict
+		s = "not_ready"
+	case errors.Is(err, customErr.ErrReportExpired):
+		code = http.StatusGone
+		s = "expired"
+	case errors.Is(err, customErr.ErrUnauthorized):
+		code = http.StatusUnauthorized
+		s = "unauthorized"
+	case errors.Is(err, customErr.ErrForbidden):
+		code = http.StatusForbidden
+		s = "forbidden"
+	case errors.Is(err, customErr.ErrDatabase):
+		code = http.StatusInternalServerError
+		s = "database_error"
+	}
+	http.Error(w, s, code)
+}
+
+func (h *ReportsHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode request")
+		h.writeError(w, customErr.ErrInvalidInput)
+		return
+	}
+	if req.From == "" || req.To == "" {
+		h.logger.Warn().Str("from", req.From).Str("to", req.To).Msg("Missing required parameters")
+		h.writeError(w, customErr.ErrMissingParameter)
+		return
+	}
+	from, err := time.Parse(time.RFC3339, req.From)
+	if err != nil {
+		h.logger.Warn().Err(err).Str("from", req.From).Msg("Invalid from date format")
+		h.writeError(w, customErr.ErrUnsupportedFormat)
+		return
+	}
+	to, err := time.Parse(time.RFC3339, req.To)
+	if err != nil {
+		h.logger.Warn().Err(err).Str("to", req.To).Msg("Invalid to date format")
+		h.writeError(w, customErr.ErrUnsupportedFormat)
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = domain.ReportKindAnalytics
+	}
+	job := &domain.ReportJob{
+		Kind: req.Kind,
+		Params: &domain.AnalyticsParams{
+			From:        from,
+			To:          to,
+			Currency:    req.Currency,
+			Compare:     req.Compare,
+			Percentiles: req.Percentiles,
+			Histogram:   req.Histogram,
+			Bins:        req.Bins,
+		},
+	}
+	if err := h.reportsUsecase.CreateReport(r.Context(), job); err != nil {
+		h.logger.Error().Err(err).Msg("CreateReport failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/reports/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(toReportResponse(job))
+	h.logger.Info().Str("id", job.ID).Msg("Report job queued")
+}
+
+func (h *ReportsHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	job, err := h.reportsUsecase.GetReport(r.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Str("id", id).Msg("GetReport failed")
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toReportResponse(job))
+}
+
+func (h *ReportsHandler) DownloadReport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	job, f, err := h.reportsUsecase.OpenArtifact(r.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Str("id", id).Msg("DownloadReport failed")
+		h.writeError(w, err)
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", job.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", job.ArtifactName))
+	var modtime time.Time
+	if job.FinishedAt != nil {
+		modtime = *job.FinishedAt
+	}
+	http.ServeContent(w, r, job.ArtifactName, modtime, f)
+	h.logger.Info().Str("id", id).Msg("Report downloaded")
+}
+
+func toReportResponse(job *domain.ReportJob) *dto.ReportResponse {
+	resp := &dto.ReportResponse{
+		ID:         job.ID,
+		Kind:       job.Kind,
+		Status:     job.Status,
+		Progress:   job.Progress,
+		Error:      job.Error,
+		From:       job.Params.From,
+		To:         job.Params.To,
+		Currency:   job.Params.Currency,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+		ExpiresAt:  job.ExpiresAt,
+	}
+	if job.Status == domain.ReportStatusDone {
+		resp.DownloadURL = "/reports/" + job.ID + "/download"
+	}
+	return resp
+}
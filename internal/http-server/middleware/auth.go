@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+
+	"github.com/wb-go/wbf/zlog"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type authenticator interface {
+	Authenticate(ctx context.Context, token string) (*domain.Principal, error)
+}
+
+// AuthMiddleware требует API-ключ или JWT в заголовке Authorization: Bearer
+// (ключ можно передать и в X-API-Key). Субъект кладётся в контекст и
+// становится автором изменений в журнале; все запросы к данным
+// ограничиваются его пространством.
+func AuthMiddleware(auth authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := auth.Authenticate(r.Context(), credentials(r))
+			if err != nil {
+				zlog.Logger.Warn().
+					Err(err).
+					Str("request_id", domain.AuditMetaFrom(r.Context()).RequestID).
+					Str("method", r.Method).
+					Str("path", r.URL.Path).
+					Msg("Authentication failed")
+				if errors.Is(err, customErr.ErrUnauthorized) {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="sales-tracker"`)
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "internal", http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// AnonymousMiddleware используется при выключенной аутентификации: все
+// запросы выполняются от субъекта anonymous с заданными ролями в
+// пространстве default, и он же записывается автором изменений.
+func AnonymousMiddleware(roles []string) func(http.Handler) http.Handler {
+	principal := &domain.Principal{
+		Subject:     domain.AnonymousActor,
+		Name:        domain.AnonymousActor,
+		Method:      domain.AuthMethodAnonymous,
+		Roles:       roles,
+		WorkspaceID: domain.DefaultWorkspaceID,
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func withPrincipal(ctx context.Context, principal *domain.Principal) context.Context {
+	meta := domain.AuditMetaFrom(ctx)
+	meta.Actor = principal.Subject
+	ctx = domain.WithAuditMeta(ctx, meta)
+	ctx = domain.WithWorkspace(ctx, principal.WorkspaceID)
+	return domain.WithPrincipal(ctx, principal)
+}
+
+func credentials(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get(APIKeyHeader)
+}
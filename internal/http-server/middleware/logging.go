@@ -4,13 +4,25 @@ import (
 	"net/http"
 	"time"
 
+	"sales-tracker/internal/domain"
+
 	"github.com/wb-go/wbf/zlog"
 )
 
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		requestID := domain.AuditMetaFrom(r.Context()).RequestID
+		var subject string
+		var workspaceID int64
+		if principal := domain.PrincipalFrom(r.Context()); principal != nil {
+			subject = principal.Subject
+			workspaceID = principal.WorkspaceID
+		}
 		zlog.Logger.Info().
+			Str("request_id", requestID).
+			Str("principal", subject).
+			Int64("workspace_id", workspaceID).
 			Str("method", r.Method).
 			Str("path", r.URL.Path).
 			Str("query", r.URL.RawQuery).
@@ -18,6 +30,9 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 		duration := time.Since(start)
 		zlog.Logger.Info().
+			Str("request_id", requestID).
+			Str("principal", subject).
+			Int64("workspace_id", workspaceID).
 			Str("method", r.Method).
 			Str("path", r.URL.Path).
 			Dur("duration", duration).
@@ -6,54 +6,124 @@ import (
 	"path/filepath"
 	"strings"
 
+	accountsH "sales-tracker/internal/http-server/handler/accounts"
 	analyticsH "sales-tracker/internal/http-server/handler/analytics"
+	auditH "sales-tracker/internal/http-server/handler/audit"
+	authH "sales-tracker/internal/http-server/handler/auth"
 	itemsH "sales-tracker/internal/http-server/handler/items"
+	ledgerH "sales-tracker/internal/http-server/handler/ledger"
+	ratesH "sales-tracker/internal/http-server/handler/rates"
+	reportsH "sales-tracker/internal/http-server/handler/reports"
 	"sales-tracker/internal/http-server/middleware"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/wb-go/wbf/zlog"
 )
 
-func NewRouter(itemsH *itemsH.ItemsHandler, analyticsH *analyticsH.AnalyticsHandler, logger *zlog.Zerolog) http.Handler {
+// NewRouter регистрирует маршруты. Статика и страница приложения открыты,
+// API защищено authMiddleware.
+func NewRouter(itemsH *itemsH.ItemsHandler, analyticsH *analyticsH.AnalyticsHandler, ratesH *ratesH.RatesHandler, reportsH *reportsH.ReportsHandler, auditH *auditH.AuditHandler, authH *authH.AuthHandler, accountsH *accountsH.AccountsHandler, ledgerH *ledgerH.LedgerHandler, authMiddleware func(http.Handler) http.Handler, logger *zlog.Zerolog) http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.RecoveryMiddleware)
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if !strings.HasPrefix(r.URL.Path, "/static/") {
-				middleware.LoggingMiddleware(next).ServeHTTP(w, r)
+	r.Use(middleware.RequestMetaMiddleware)
+	workDir, _ := os.Getwd()
+	staticDir := http.Dir(filepath.Join(workDir, "static"))
+	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(staticDir)))
+	r.Group(func(r chi.Router) {
+		r.Use(authMiddleware)
+		r.Use(middleware.LoggingMiddleware)
+		registerAPI(r, itemsH, analyticsH, ratesH, reportsH, auditH, authH, accountsH, ledgerH)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.LoggingMiddleware)
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			serveHTML(w, r, workDir)
+		})
+		r.Get("/*", func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, "/static/") &&
+				!strings.HasPrefix(r.URL.Path, "/items") &&
+				!strings.HasPrefix(r.URL.Path, "/accounts") &&
+				!strings.HasPrefix(r.URL.Path, "/ledger") &&
+				!strings.HasPrefix(r.URL.Path, "/rates") &&
+				!strings.HasPrefix(r.URL.Path, "/reports") &&
+				!strings.HasPrefix(r.URL.Path, "/audit") &&
+				!strings.HasPrefix(r.URL.Path, "/auth") &&
+				!strings.HasPrefix(r.URL.Path, "/analytics") {
+				serveHTML(w, r, workDir)
 			} else {
-				next.ServeHTTP(w, r)
+				http.NotFound(w, r)
 			}
 		})
 	})
-	workDir, _ := os.Getwd()
-	staticDir := http.Dir(filepath.Join(workDir, "static"))
-	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(staticDir)))
+	logger.Info().Msg("Routes registered")
+	return r
+}
+
+func registerAPI(r chi.Router, itemsH *itemsH.ItemsHandler, analyticsH *analyticsH.AnalyticsHandler, ratesH *ratesH.RatesHandler, reportsH *reportsH.ReportsHandler, auditH *auditH.AuditHandler, authH *authH.AuthHandler, accountsH *accountsH.AccountsHandler, ledgerH *ledgerH.LedgerHandler) {
 	r.Route("/items", func(r chi.Router) {
 		r.Get("/", itemsH.GetItems)
 		r.Post("/", itemsH.CreateItem)
-		r.Get("/export", itemsH.ExportCSV)
+		r.Get("/export", itemsH.Export)
+		r.Post("/import", itemsH.ImportItems)
+		r.Post("/bulk", itemsH.BulkItems)
+		r.Post("/bulk/update", itemsH.UpdateItemsByFilter)
+		r.Post("/bulk/delete", itemsH.DeleteItemsByFilter)
+		r.Get("/trash", itemsH.GetTrash)
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", itemsH.GetItemByID)
 			r.Put("/", itemsH.UpdateItem)
+			r.Patch("/", itemsH.PatchItem)
 			r.Delete("/", itemsH.DeleteItem)
+			r.Post("/restore", itemsH.RestoreItem)
+			r.Get("/history", auditH.GetItemHistory)
 		})
 	})
-	r.Get("/analytics", analyticsH.GetAnalytics)
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		serveHTML(w, r, workDir)
+	r.Route("/accounts", func(r chi.Router) {
+		r.Get("/", accountsH.ListAccounts)
+		r.Post("/", accountsH.CreateAccount)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", accountsH.GetAccount)
+			r.Patch("/", accountsH.UpdateAccount)
+			r.Delete("/", accountsH.DeleteAccount)
+			r.Get("/statement", accountsH.GetStatement)
+		})
 	})
-	r.Get("/*", func(w http.ResponseWriter, r *http.Request) {
-		if !strings.HasPrefix(r.URL.Path, "/static/") &&
-			!strings.HasPrefix(r.URL.Path, "/items") &&
-			r.URL.Path != "/analytics" {
-			serveHTML(w, r, workDir)
-		} else {
-			http.NotFound(w, r)
-		}
+	r.Route("/ledger", func(r chi.Router) {
+		r.Get("/accounts", ledgerH.ListAccounts)
+		r.Post("/accounts", ledgerH.CreateAccount)
+		r.Delete("/accounts/{id}", ledgerH.DeleteAccount)
+		r.Get("/entries", ledgerH.ListEntries)
+		r.Post("/entries", ledgerH.CreateEntry)
+		r.Delete("/entries/{id}", ledgerH.DeleteEntry)
+		r.Get("/trial-balance", ledgerH.GetTrialBalance)
+		r.Get("/balance-sheet", ledgerH.GetBalanceSheet)
+		r.Get("/profit-and-loss", ledgerH.GetProfitAndLoss)
+	})
+	r.Route("/analytics", func(r chi.Router) {
+		r.Get("/", analyticsH.GetAnalytics)
+		r.Get("/timeseries", analyticsH.GetTimeSeries)
+		r.Get("/breakdown", analyticsH.GetBreakdown)
+	})
+	r.Route("/rates", func(r chi.Router) {
+		r.Get("/", ratesH.GetRates)
+		r.Post("/", ratesH.UpsertRates)
+		r.Post("/import", ratesH.ImportRates)
+	})
+	r.Route("/reports", func(r chi.Router) {
+		r.Post("/", reportsH.CreateReport)
+		r.Get("/{id}", reportsH.GetReport)
+		r.Get("/{id}/download", reportsH.DownloadReport)
+	})
+	r.Route("/audit", func(r chi.Router) {
+		r.Get("/", auditH.GetAudit)
+		r.Get("/verify", auditH.VerifyAudit)
+	})
+	r.Route("/auth", func(r chi.Router) {
+		r.Get("/me", authH.Me)
+		r.Get("/keys", authH.ListAPIKeys)
+		r.Post("/keys", authH.CreateAPIKey)
+		r.Delete("/keys/{id}", authH.RevokeAPIKey)
 	})
-	logger.Info().Msg("Routes registered")
-	return r
 }
 
 func serveHTML(w http.ResponseWriter, r *http.Request, workDir string) {
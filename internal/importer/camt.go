@@ -0,0 +1,170 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"sales-tracker/internal/domain"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// Структуры ISO 20022 camt.053 (BankToCustomerStatement). Пространство
+// имён не указано, поэтому подходят все версии схемы (.001.02 — .001.13).
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	IBAN    string      `xml:"Acct>Id>IBAN"`
+	Other   string      `xml:"Acct>Id>Othr>Id"`
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	NtryRef     string `xml:"NtryRef"`
+	AcctSvcrRef string `xml:"AcctSvcrRef"`
+	Amount      struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	Indicator string `xml:"CdtDbtInd"`
+	Status    struct {
+		Text string `xml:",chardata"`
+		Code string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookingDate camtDate        `xml:"BookgDt"`
+	ValueDate   camtDate        `xml:"ValDt"`
+	Info        string          `xml:"AddtlNtryInf"`
+	Details     []camtTxDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtTxDetails struct {
+	AcctSvcrRef  string   `xml:"Refs>AcctSvcrRef"`
+	TxID         string   `xml:"Refs>TxId"`
+	EndToEndID   string   `xml:"Refs>EndToEndId"`
+	Debtor       string   `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty    string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Creditor     string   `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPty  string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Unstructured []string `xml:"RmtInf>Ustrd"`
+}
+
+// parseCAMT053 разбирает выписку camt.053. Номер строки в отчёте —
+// порядковый номер записи Ntry в файле. Незавершённые записи
+// (статус, отличный от BOOK) пропускаются.
+func parseCAMT053(data []byte) ([]*domain.ImportRow, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(input), nil
+	}
+	var doc camtDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("no BkToCstmrStmt/Stmt elements found")
+	}
+
+	var rows []*domain.ImportRow
+	n := 0
+	for _, stmt := range doc.Statements {
+		account := stmt.IBAN
+		if account == "" {
+			account = stmt.Other
+		}
+		for _, entry := range stmt.Entries {
+			n++
+			rows = append(rows, camtRow(n, account, &entry))
+		}
+	}
+	return rows, nil
+}
+
+func camtRow(line int, account string, entry *camtEntry) *domain.ImportRow {
+	row := &domain.ImportRow{Line: line}
+	status := strings.TrimSpace(entry.Status.Code)
+	if status == "" {
+		status = strings.TrimSpace(entry.Status.Text)
+	}
+	if status != "" && status != "BOOK" {
+		row.SkipReason = domain.ImportSkipNotBooked
+		return row
+	}
+	amount, err := parseStatementAmount(entry.Amount.Value)
+	if err != nil {
+		row.Err = fmt.Errorf("Amt: %w", err)
+		return row
+	}
+	date, err := entry.BookingDate.parse()
+	if err != nil {
+		date, err = entry.ValueDate.parse()
+	}
+	if err != nil {
+		row.Err = fmt.Errorf("BookgDt: %w", err)
+		return row
+	}
+
+	item := &domain.Item{Amount: amount, Currency: entry.Amount.Currency, Date: date}
+	var details camtTxDetails
+	if len(entry.Details) > 0 {
+		details = entry.Details[0]
+	}
+	switch strings.TrimSpace(entry.Indicator) {
+	case "CRDT":
+		item.Type = "income"
+		item.Description = joinNonEmpty(" — ", details.Debtor+details.DebtorPty, strings.Join(details.Unstructured, " "), entry.Info)
+	case "DBIT":
+		item.Type = "expense"
+		item.Description = joinNonEmpty(" — ", details.Creditor+details.CreditorPty, strings.Join(details.Unstructured, " "), entry.Info)
+	default:
+		row.Err = fmt.Errorf("CdtDbtInd: unsupported indicator %q", entry.Indicator)
+		return row
+	}
+
+	ref := firstNonEmpty(entry.AcctSvcrRef, details.AcctSvcrRef, entry.NtryRef, details.TxID, details.EndToEndID)
+	if ref == "" {
+		row.Err = fmt.Errorf("AcctSvcrRef: missing entry reference")
+		return row
+	}
+	item.ExternalID = "camt:" + account + ":" + ref
+	row.Item = item
+	return row
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	if d.DateTime != "" {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+			if t, err := time.Parse(layout, d.DateTime); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unsupported date %q", d.DateTime)
+	}
+	if d.Date != "" {
+		return time.Parse(time.DateOnly, strings.TrimSpace(d.Date))
+	}
+	return time.Time{}, fmt.Errorf("missing date")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+func TestParseCAMT053(t *testing.T) {
+	rows := parseFixture(t, FormatCAMT053, "camt053.xml")
+	checkRows(t, rows, "camt:DE89370400440532013000:", []wantRow{
+		{
+			line: 1, typ: "income", amount: "250.00", currency: "EUR",
+			date:        date(2025, 2, 3),
+			description: "Customer GmbH — Invoice 7",
+			externalID:  "camt:DE89370400440532013000:REF-0001",
+		},
+		{
+			line: 2, typ: "expense", amount: "19.99", currency: "EUR",
+			date:        time.Date(2025, 2, 5, 9, 15, 0, 0, time.UTC),
+			description: "Telecom AG",
+			externalID:  "camt:DE89370400440532013000:TX-0002",
+		},
+		{line: 3, skip: domain.ImportSkipNotBooked},
+		{line: 4, err: "missing entry reference"},
+	})
+}
+
+func TestParseCAMT053WithoutStatements(t *testing.T) {
+	doc := []byte(`<Document><BkToCstmrStmt><GrpHdr/></BkToCstmrStmt></Document>`)
+	if _, err := parseCAMT053(doc); err == nil {
+		t.Fatal("expected error")
+	}
+}
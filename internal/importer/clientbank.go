@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"sales-tracker/internal/domain"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// clientBankDOSMarker — строка «Кодировка=DOS» в CP866: по ней
+// распознаются выписки 1С в DOS-кодировке.
+var clientBankDOSMarker, _ = charmap.CodePage866.NewEncoder().Bytes([]byte("Кодировка=DOS"))
+
+// parseClientBank разбирает формат обмена 1С с клиент-банком
+// (1CClientBankExchange). Направление платежа определяется по полям
+// ДатаПоступило/ДатаСписано, а если их нет — по совпадению счёта
+// получателя или плательщика с расчётным счётом выписки.
+func parseClientBank(text string) ([]*domain.ImportRow, error) {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var (
+		rows     []*domain.ImportRow
+		accounts = map[string]bool{}
+		doc      map[string]string
+		start    int
+		line     int
+	)
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if line == 1 {
+			if s != "1CClientBankExchange" {
+				return nil, fmt.Errorf("1CClientBankExchange header not found")
+			}
+			continue
+		}
+		key, value, _ := strings.Cut(s, "=")
+		switch {
+		case key == "СекцияДокумент":
+			doc, start = map[string]string{}, line
+		case key == "КонецДокумента":
+			if doc != nil {
+				rows = append(rows, clientBankRow(start, doc, accounts))
+			}
+			doc = nil
+		case doc != nil:
+			doc[key] = strings.TrimSpace(value)
+		case key == "РасчСчет":
+			accounts[strings.TrimSpace(value)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func clientBankRow(line int, doc map[string]string, accounts map[string]bool) *domain.ImportRow {
+	row := &domain.ImportRow{Line: line}
+	amount, err := parseStatementAmount(doc["Сумма"])
+	if err != nil {
+		row.Err = fmt.Errorf("Сумма: %w", err)
+		return row
+	}
+
+	item := &domain.Item{Amount: amount}
+	var dateStr, counterparty string
+	switch {
+	case doc["ДатаПоступило"] != "":
+		item.Type, dateStr = "income", doc["ДатаПоступило"]
+	case doc["ДатаСписано"] != "":
+		item.Type, dateStr = "expense", doc["ДатаСписано"]
+	case accounts[doc["ПолучательСчет"]]:
+		item.Type = "income"
+	case accounts[doc["ПлательщикСчет"]]:
+		item.Type = "expense"
+	default:
+		row.Err = fmt.Errorf("cannot determine payment direction: account not in statement")
+		return row
+	}
+	if item.Type == "income" {
+		counterparty = firstNonEmpty(doc["Плательщик1"], doc["Плательщик"])
+	} else {
+		counterparty = firstNonEmpty(doc["Получатель1"], doc["Получатель"])
+	}
+	if dateStr == "" {
+		dateStr = doc["Дата"]
+	}
+	item.Date, err = time.Parse("02.01.2006", dateStr)
+	if err != nil {
+		row.Err = fmt.Errorf("Дата: %w", err)
+		return row
+	}
+	if doc["Номер"] == "" {
+		row.Err = fmt.Errorf("Номер: missing document number")
+		return row
+	}
+	item.Description = joinNonEmpty(" — ", counterparty, doc["НазначениеПлатежа"])
+	item.ExternalID = "1c:" + doc["ПлательщикСчет"] + ":" + doc["Дата"] + ":" + doc["Номер"]
+	row.Item = item
+	return row
+}
@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestParseClientBank(t *testing.T) {
+	// Фикстура в Windows-1251, как её выгружают банки.
+	rows := parseFixture(t, FormatClientBank, "clientbank.txt")
+	checkRows(t, rows, "1c:", []wantRow{
+		{
+			line: 14, typ: "income", amount: "12500.50",
+			date:        date(2025, 3, 4),
+			description: `ООО "Ромашка" — Оплата по счёту 12`,
+			externalID:  "1c:40702810500000000777:03.03.2025:15",
+		},
+		{
+			line: 26, typ: "expense", amount: "3000.00",
+			date:        date(2025, 3, 5),
+			description: "ИП Иванов — Аренда за март",
+			externalID:  "1c:40702810900000000001:05.03.2025:101",
+		},
+		{line: 36, err: "cannot determine payment direction"},
+	})
+}
+
+func TestParseClientBankDOS(t *testing.T) {
+	text := strings.Join([]string{
+		"1CClientBankExchange",
+		"Кодировка=DOS",
+		"РасчСчет=40702810900000000001",
+		"СекцияДокумент=Платежное поручение",
+		"Номер=7",
+		"Дата=10.03.2025",
+		"Сумма=150.00",
+		"ПлательщикСчет=40702810900000000001",
+		"ПолучательСчет=40702810100000000555",
+		"Получатель1=Поставщик",
+		"ДатаСписано=10.03.2025",
+		"КонецДокумента",
+		"КонецФайла",
+	}, "\r\n")
+	data, err := charmap.CodePage866.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := ParseStatement(strings.NewReader(string(data)), FormatClientBank, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, rows, "1c:", []wantRow{{
+		line: 4, typ: "expense", amount: "150.00",
+		date:        date(2025, 3, 10),
+		description: "Поставщик",
+		externalID:  "1c:40702810900000000001:10.03.2025:7",
+	}})
+}
+
+func TestParseClientBankWithoutHeader(t *testing.T) {
+	if _, err := parseClientBank("СекцияДокумент=Платежное поручение\n"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"sales-tracker/internal/domain"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingWindows1251 = "windows-1251"
+)
+
+type CSVOptions struct {
+	// Delimiter — разделитель полей; 0 — определить по строке заголовка.
+	Delimiter rune
+	Encoding  string
+	Mapping   map[string]string
+}
+
+// ParseCSV разбирает CSV с заголовком в первой строке. Ошибки отдельных
+// строк возвращаются в ImportRow.Err, ошибка функции означает, что файл
+// не удалось прочитать целиком.
+func ParseCSV(r io.Reader, opts CSVOptions) ([]*domain.ImportRow, error) {
+	switch strings.ToLower(opts.Encoding) {
+	case "", EncodingUTF8, "utf8":
+	case EncodingWindows1251, "cp1251":
+		r = charmap.Windows1251.NewDecoder().Reader(r)
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", opts.Encoding)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\xef\xbb\xbf")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = opts.Delimiter
+	if reader.Comma == 0 {
+		reader.Comma = detectDelimiter(text)
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("empty file")
+		}
+		return nil, err
+	}
+	t, err := newTable(header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*domain.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rows = append(rows, &domain.ImportRow{Line: parseErr.Line, Err: parseErr.Err})
+				continue
+			}
+			return nil, err
+		}
+		if isBlank(record) {
+			continue
+		}
+		rows = append(rows, t.parse(line, record))
+	}
+	return rows, nil
+}
+
+func detectDelimiter(text string) rune {
+	header, _, _ := strings.Cut(text, "\n")
+	best, count := ',', 0
+	for _, d := range []rune{',', ';', '\t', '|'} {
+		if n := strings.Count(header, string(d)); n > count {
+			best, count = d, n
+		}
+	}
+	return best
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
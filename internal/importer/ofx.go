@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+// parseOFX разбирает OFX/QFX: и SGML-вариант 1.x с незакрытыми тегами,
+// и XML-вариант 2.x. Операции — блоки STMTTRN (банковский и карточный счёт).
+func parseOFX(text string) ([]*domain.ImportRow, error) {
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return nil, fmt.Errorf("OFX root element not found")
+	}
+	line := 1 + strings.Count(text[:start], "\n")
+	text = text[start:]
+
+	var (
+		rows     []*domain.ImportRow
+		currency string
+		account  string
+		trn      map[string]string
+		trnLine  int
+	)
+	for len(text) > 0 {
+		open := strings.IndexByte(text, '<')
+		if open < 0 {
+			break
+		}
+		line += strings.Count(text[:open], "\n")
+		closeTag := strings.IndexByte(text[open:], '>')
+		if closeTag < 0 {
+			break
+		}
+		tag := strings.ToUpper(strings.TrimSpace(text[open+1 : open+closeTag]))
+		text = text[open+closeTag+1:]
+		next := strings.IndexByte(text, '<')
+		if next < 0 {
+			next = len(text)
+		}
+		value := strings.TrimSpace(text[:next])
+
+		switch {
+		case tag == "STMTTRN":
+			trn, trnLine = map[string]string{}, line
+		case tag == "/STMTTRN":
+			if trn != nil {
+				rows = append(rows, ofxRow(trnLine, trn, currency, account))
+			}
+			trn = nil
+		case strings.HasPrefix(tag, "/"):
+		case trn != nil:
+			trn[tag] = value
+		case tag == "CURDEF":
+			currency = value
+		case tag == "ACCTID" && account == "":
+			account = value
+		}
+	}
+	return rows, nil
+}
+
+func ofxRow(line int, trn map[string]string, currency, account string) *domain.ImportRow {
+	row := &domain.ImportRow{Line: line}
+	amount, err := parseStatementAmount(trn["TRNAMT"])
+	if err != nil {
+		row.Err = fmt.Errorf("TRNAMT: %w", err)
+		return row
+	}
+	date, err := parseOFXDate(trn["DTPOSTED"])
+	if err != nil {
+		row.Err = fmt.Errorf("DTPOSTED: %w", err)
+		return row
+	}
+	if trn["FITID"] == "" {
+		row.Err = fmt.Errorf("FITID: missing transaction ID")
+		return row
+	}
+	if c := trn["CURRENCY"]; c != "" {
+		currency = c
+	}
+	item := signedItem(amount)
+	item.Currency = currency
+	item.Date = date
+	item.Description = joinNonEmpty(" — ", trn["NAME"], trn["PAYEE"], trn["MEMO"])
+	item.ExternalID = "ofx:" + account + ":" + trn["FITID"]
+	row.Item = item
+	return row
+}
+
+var ofxTZ = regexp.MustCompile(`\[([+-]?\d+(?:\.\d+)?)(?::[^\]]*)?\]`)
+
+// parseOFXDate разбирает даты вида 20250115, 20250115103000.000[-5:EST].
+func parseOFXDate(s string) (time.Time, error) {
+	loc := time.UTC
+	if m := ofxTZ.FindStringSubmatch(s); m != nil {
+		hours, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = time.FixedZone("", int(hours*3600))
+		s = s[:strings.IndexByte(s, '[')]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s = s[:i]
+	}
+	for _, layout := range []string{"20060102150405", "200601021504", "20060102"} {
+		if len(s) == len(layout) {
+			return time.ParseInLocation(layout, s, loc)
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported date %q", s)
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOFXSGML(t *testing.T) {
+	rows := parseFixture(t, FormatOFX, "statement.ofx")
+	checkRows(t, rows, "ofx:000123456789:", []wantRow{
+		{
+			line: 35, typ: "income", amount: "1500.00", currency: "USD",
+			date:        time.Date(2025, 1, 15, 15, 30, 0, 0, time.UTC),
+			description: "ACME CORP — Invoice 42",
+			externalID:  "ofx:000123456789:202501150001",
+		},
+		{
+			line: 43, typ: "expense", amount: "45.99", currency: "USD",
+			date:        date(2025, 1, 20),
+			description: "GROCERY STORE",
+			externalID:  "ofx:000123456789:202501200002",
+		},
+		{line: 50, err: "FITID"},
+	})
+}
+
+func TestParseQFXCreditCard(t *testing.T) {
+	rows := parseFixture(t, FormatQFX, "statement.qfx")
+	checkRows(t, rows, "ofx:4111222233334444:", []wantRow{
+		{
+			line: 15, typ: "expense", amount: "89.90", currency: "EUR",
+			date:        date(2025, 2, 3),
+			description: "Hotel — Berlin",
+			externalID:  "ofx:4111222233334444:CC-1001",
+		},
+		{
+			line: 23, typ: "income", amount: "20.00", currency: "EUR",
+			date:        time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC),
+			description: "Refund",
+			externalID:  "ofx:4111222233334444:CC-1002",
+		},
+	})
+}
+
+func TestParseOFXDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"20250115", date(2025, 1, 15)},
+		{"202501151030", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"20250115103000.000", time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"20250115103000.000[-5:EST]", time.Date(2025, 1, 15, 15, 30, 0, 0, time.UTC)},
+		{"20250115103000[+3]", time.Date(2025, 1, 15, 7, 30, 0, 0, time.UTC)},
+		{"20250115000000[+5.5:IST]", time.Date(2025, 1, 14, 18, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseOFXDate(tt.in)
+		if err != nil {
+			t.Errorf("parseOFXDate(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseOFXDate(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "2025-01-15", "2025011"} {
+		if _, err := parseOFXDate(in); err == nil {
+			t.Errorf("parseOFXDate(%q): expected error", in)
+		}
+	}
+}
+
+func TestParseOFXWithoutRoot(t *testing.T) {
+	if _, err := parseOFX("OFXHEADER:100\n<STMTTRN>"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+// parseQIF разбирает Quicken Interchange Format. В QIF нет идентификатора
+// операции, поэтому внешний ID — отпечаток даты, суммы, получателя и описания
+// с номером повтора внутри файла.
+func parseQIF(text string) ([]*domain.ImportRow, error) {
+	var (
+		rows        []*domain.ImportRow
+		fields      map[byte]string
+		start       int
+		occurrences = map[string]int{}
+	)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	line := 0
+	for scanner.Scan() {
+		line++
+		s := strings.TrimRight(scanner.Text(), "\r")
+		if s == "" || s[0] == '!' {
+			continue
+		}
+		if fields == nil {
+			fields, start = map[byte]string{}, line
+		}
+		if s[0] == '^' {
+			rows = append(rows, qifRow(start, fields, occurrences))
+			fields = nil
+			continue
+		}
+		// Поле может повторяться (например, несколько строк адреса): берём первое.
+		if _, ok := fields[s[0]]; !ok {
+			fields[s[0]] = strings.TrimSpace(s[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if fields != nil {
+		rows = append(rows, qifRow(start, fields, occurrences))
+	}
+	return rows, nil
+}
+
+func qifRow(line int, fields map[byte]string, occurrences map[string]int) *domain.ImportRow {
+	row := &domain.ImportRow{Line: line}
+	rawAmount := fields['T']
+	if rawAmount == "" {
+		rawAmount = fields['U']
+	}
+	amount, err := parseStatementAmount(rawAmount)
+	if err != nil {
+		row.Err = fmt.Errorf("T: %w", err)
+		return row
+	}
+	date, err := parseQIFDate(fields['D'])
+	if err != nil {
+		row.Err = fmt.Errorf("D: %w", err)
+		return row
+	}
+	item := signedItem(amount)
+	item.Date = date
+	item.Category = fields['L']
+	item.Description = joinNonEmpty(" — ", fields['P'], fields['M'])
+
+	key := strings.Join([]string{date.Format(time.DateOnly), amount.String(), fields['P'], fields['M'], fields['N']}, "\x00")
+	item.ExternalID = "qif:" + fingerprint(occurrences[key], key)
+	occurrences[key]++
+	row.Item = item
+	return row
+}
+
+// parseQIFDate понимает американские даты (1/15/2025, 01/15'25),
+// европейские через точку (15.01.2025) и ISO (2025-01-15).
+func parseQIFDate(s string) (time.Time, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	var parts []string
+	var monthFirst bool
+	switch {
+	case strings.ContainsAny(s, "/'"):
+		parts = strings.FieldsFunc(s, func(r rune) bool { return r == '/' || r == '\'' })
+		monthFirst = true
+	case strings.Contains(s, "."):
+		parts = strings.Split(s, ".")
+	}
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("unsupported date %q", s)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unsupported date %q", s)
+		}
+		nums[i] = n
+	}
+	day, month, year := nums[0], nums[1], nums[2]
+	if monthFirst {
+		day, month = month, day
+	}
+	if year < 100 {
+		year += 2000
+		if year > time.Now().Year()+10 {
+			year -= 100
+		}
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day || int(t.Month()) != month {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,52 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQIF(t *testing.T) {
+	rows := parseFixture(t, FormatQIF, "statement.qif")
+	checkRows(t, rows, "qif:", []wantRow{
+		{line: 2, typ: "income", amount: "1500.00", date: date(2025, 1, 15), description: "ACME Corp — Invoice 42"},
+		{line: 8, typ: "expense", amount: "45.99", date: date(2025, 1, 20), description: "Grocery Store"},
+		{line: 13, typ: "expense", amount: "45.99", date: date(2025, 1, 20), description: "Grocery Store"},
+		{line: 18, err: "invalid date"},
+		{line: 22, typ: "expense", amount: "3.50", date: date(2025, 1, 25), description: "Coffee"},
+	})
+	if rows[0].Item.Category != "Sales" {
+		t.Errorf("category = %q, want Sales", rows[0].Item.Category)
+	}
+	// Одинаковые операции в одном файле различаются номером повтора.
+	if rows[1].Item.ExternalID == rows[2].Item.ExternalID {
+		t.Errorf("identical transactions share external ID %q", rows[1].Item.ExternalID)
+	}
+}
+
+func TestParseQIFDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"1/15/2025", date(2025, 1, 15)},
+		{"01/15'25", date(2025, 1, 15)},
+		{"1/5' 25", date(2025, 1, 5)},
+		{"15.01.2025", date(2025, 1, 15)},
+		{"2025-01-15", date(2025, 1, 15)},
+	}
+	for _, tt := range tests {
+		got, err := parseQIFDate(tt.in)
+		if err != nil {
+			t.Errorf("parseQIFDate(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseQIFDate(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "2/30/2025", "15-01-2025", "a/b/c"} {
+		if _, err := parseQIFDate(in); err == nil {
+			t.Errorf("parseQIFDate(%q): expected error", in)
+		}
+	}
+}
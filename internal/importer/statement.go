@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"sales-tracker/internal/domain"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Форматы банковских выписок.
+const (
+	FormatOFX        = "ofx"
+	FormatQFX        = "qfx"
+	FormatQIF        = "qif"
+	FormatCAMT053    = "camt053"
+	FormatClientBank = "1c"
+)
+
+// IsStatementFormat сообщает, относится ли формат к банковским выпискам.
+func IsStatementFormat(format string) bool {
+	switch format {
+	case FormatOFX, FormatQFX, FormatQIF, FormatCAMT053, FormatClientBank:
+		return true
+	}
+	return false
+}
+
+// ParseStatement разбирает банковскую выписку. Поступления становятся
+// доходами, списания — расходами; банковский идентификатор операции
+// сохраняется в Item.ExternalID с префиксом формата и счёта, чтобы
+// повторный импорт той же выписки пропускал уже загруженные операции.
+func ParseStatement(r io.Reader, format, encoding string) ([]*domain.ImportRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatOFX, FormatQFX:
+		text, err := decodeText(data, encoding)
+		if err != nil {
+			return nil, err
+		}
+		return parseOFX(text)
+	case FormatQIF:
+		text, err := decodeText(data, encoding)
+		if err != nil {
+			return nil, err
+		}
+		return parseQIF(text)
+	case FormatCAMT053:
+		// Кодировка XML указана в его прологе.
+		return parseCAMT053(data)
+	case FormatClientBank:
+		if encoding == "" && !utf8.Valid(data) && bytes.Contains(data, clientBankDOSMarker) {
+			encoding = "cp866"
+		}
+		text, err := decodeText(data, encoding)
+		if err != nil {
+			return nil, err
+		}
+		return parseClientBank(text)
+	}
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
+
+// decodeText приводит выписку к UTF-8. Без явной кодировки файл,
+// не являющийся корректным UTF-8, считается Windows-1251 —
+// так выгружают выписки российские банки.
+func decodeText(data []byte, encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case "":
+		if utf8.Valid(data) {
+			break
+		}
+		fallthrough
+	case EncodingWindows1251, "cp1251":
+		decoded, err := charmap.Windows1251.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", err
+		}
+		data = decoded
+	case "cp866":
+		decoded, err := charmap.CodePage866.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", err
+		}
+		data = decoded
+	case EncodingUTF8, "utf8":
+	default:
+		return "", fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	return strings.TrimPrefix(string(data), "\xef\xbb\xbf"), nil
+}
+
+// parseStatementAmount понимает "1,234.56", "1234,56" и "-12.30".
+func parseStatementAmount(s string) (domain.Money, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\'':
+			return -1
+		}
+		return r
+	}, s)
+	if strings.Contains(s, ",") && strings.Contains(s, ".") {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	return domain.ParseMoney(strings.Replace(s, ",", ".", 1))
+}
+
+// signedItem строит операцию по сумме со знаком: плюс — доход, минус — расход.
+func signedItem(amount domain.Money) *domain.Item {
+	item := &domain.Item{Type: "income", Amount: amount}
+	if amount < 0 {
+		item.Type = "expense"
+		item.Amount = -amount
+	}
+	return item
+}
+
+// fingerprint строит устойчивый идентификатор для форматов без ID операции.
+// occurrence различает одинаковые операции внутри одного файла.
+func fingerprint(occurrence int, parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	fmt.Fprintf(h, "%d", occurrence)
+	return hex.EncodeToString(h.Sum(nil))[:24]
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
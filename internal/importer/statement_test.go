@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sales-tracker/internal/domain"
+)
+
+// wantRow — ожидаемая строка выписки. Пустой ExternalID проверяет только
+// префикс externalPrefix; err — подстрока ошибки строки.
+type wantRow struct {
+	line        int
+	typ         string
+	amount      string
+	currency    string
+	date        time.Time
+	description string
+	externalID  string
+	err         string
+	skip        string
+}
+
+func parseFixture(t *testing.T, format, name string) []*domain.ImportRow {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := ParseStatement(f, format, "")
+	if err != nil {
+		t.Fatalf("ParseStatement(%s): %v", name, err)
+	}
+	return rows
+}
+
+func checkRows(t *testing.T, rows []*domain.ImportRow, externalPrefix string, want []wantRow) {
+	t.Helper()
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		row := rows[i]
+		if row.Line != w.line {
+			t.Errorf("row %d: line = %d, want %d", i, row.Line, w.line)
+		}
+		switch {
+		case w.err != "":
+			if row.Err == nil || !strings.Contains(row.Err.Error(), w.err) {
+				t.Errorf("row %d: error = %v, want %q", i, row.Err, w.err)
+			}
+			continue
+		case w.skip != "":
+			if row.SkipReason != w.skip {
+				t.Errorf("row %d: skip reason = %q, want %q", i, row.SkipReason, w.skip)
+			}
+			continue
+		}
+		if row.Err != nil || row.Item == nil {
+			t.Errorf("row %d: unexpected error %v", i, row.Err)
+			continue
+		}
+		item := row.Item
+		if item.Type != w.typ {
+			t.Errorf("row %d: type = %q, want %q", i, item.Type, w.typ)
+		}
+		if item.Amount.String() != w.amount {
+			t.Errorf("row %d: amount = %s, want %s", i, item.Amount, w.amount)
+		}
+		if item.Currency != w.currency {
+			t.Errorf("row %d: currency = %q, want %q", i, item.Currency, w.currency)
+		}
+		if !item.Date.Equal(w.date) {
+			t.Errorf("row %d: date = %s, want %s", i, item.Date, w.date)
+		}
+		if item.Description != w.description {
+			t.Errorf("row %d: description = %q, want %q", i, item.Description, w.description)
+		}
+		if !strings.HasPrefix(item.ExternalID, externalPrefix) {
+			t.Errorf("row %d: external ID %q has no prefix %q", i, item.ExternalID, externalPrefix)
+		}
+		if w.externalID != "" && item.ExternalID != w.externalID {
+			t.Errorf("row %d: external ID = %q, want %q", i, item.ExternalID, w.externalID)
+		}
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Повторный импорт пропускает строки по внешнему ID, поэтому он должен
+// совпадать при каждом разборе файла и не повторяться внутри файла.
+func TestParseStatementStableExternalIDs(t *testing.T) {
+	fixtures := []struct {
+		format string
+		name   string
+	}{
+		{FormatOFX, "statement.ofx"},
+		{FormatQFX, "statement.qfx"},
+		{FormatQIF, "statement.qif"},
+		{FormatCAMT053, "camt053.xml"},
+		{FormatClientBank, "clientbank.txt"},
+	}
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			first := parseFixture(t, f.format, f.name)
+			second := parseFixture(t, f.format, f.name)
+			seen := map[string]bool{}
+			for i, row := range first {
+				if row.Item == nil {
+					continue
+				}
+				id := row.Item.ExternalID
+				if id == "" {
+					t.Errorf("line %d: empty external ID", row.Line)
+				}
+				if seen[id] {
+					t.Errorf("line %d: external ID %q repeats in file", row.Line, id)
+				}
+				seen[id] = true
+				if second[i].Item == nil || second[i].Item.ExternalID != id {
+					t.Errorf("line %d: external ID changed between parses", row.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestParseStatementUnsupportedFormat(t *testing.T) {
+	if _, err := ParseStatement(strings.NewReader(""), "mt940", ""); err == nil {
+		t.Fatal("expected error")
+	}
+}
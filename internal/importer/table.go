@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales-tracker/internal/domain"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Поля операции, которые можно сопоставить с колонками файла.
+const (
+	FieldType        = "type"
+	FieldAmount      = "amount"
+	FieldCurrency    = "currency"
+	FieldDate        = "date"
+	FieldCategory    = "category"
+	FieldDescription = "description"
+	FieldExternalID  = "external_id"
+)
+
+var fields = []string{FieldType, FieldAmount, FieldCurrency, FieldDate, FieldCategory, FieldDescription, FieldExternalID}
+
+// defaultColumns — заголовки, которые распознаются без явного сопоставления.
+// Русские варианты совпадают с заголовками CSV-отчёта SalesTracker.
+var defaultColumns = map[string]string{
+	"type":        FieldType,
+	"тип":         FieldType,
+	"amount":      FieldAmount,
+	"сумма":       FieldAmount,
+	"currency":    FieldCurrency,
+	"валюта":      FieldCurrency,
+	"date":        FieldDate,
+	"дата":        FieldDate,
+	"category":    FieldCategory,
+	"категория":   FieldCategory,
+	"description": FieldDescription,
+	"описание":    FieldDescription,
+	"external_id": FieldExternalID,
+}
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.DateOnly,
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+// table сопоставляет колонки заголовка с полями операции.
+type table struct {
+	columns map[string]int
+}
+
+// newTable разбирает строку заголовка. mapping задаёт соответствие
+// «поле → заголовок колонки» и имеет приоритет над распознаванием по умолчанию.
+func newTable(header []string, mapping map[string]string) (*table, error) {
+	t := &table{columns: map[string]int{}}
+	index := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\xef\xbb\xbf")))
+		index[name] = i
+		if field, ok := defaultColumns[name]; ok {
+			if _, seen := t.columns[field]; !seen {
+				t.columns[field] = i
+			}
+		}
+	}
+	for field, column := range mapping {
+		if !slices.Contains(fields, field) {
+			return nil, fmt.Errorf("unknown field %q in mapping", field)
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			return nil, fmt.Errorf("column %q for field %q not found", column, field)
+		}
+		t.columns[field] = i
+	}
+	for _, field := range []string{FieldAmount, FieldDate} {
+		if _, ok := t.columns[field]; !ok {
+			return nil, fmt.Errorf("missing column for field %q", field)
+		}
+	}
+	return t, nil
+}
+
+func (t *table) value(record []string, field string) string {
+	i, ok := t.columns[field]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// parse превращает строку файла в операцию. Проверка бизнес-правил
+// (допустимый тип, валюта) остаётся за usecase.
+func (t *table) parse(line int, record []string) *domain.ImportRow {
+	row := &domain.ImportRow{Line: line}
+	amount, err := parseAmount(t.value(record, FieldAmount))
+	if err != nil {
+		row.Err = fmt.Errorf("amount: %w", err)
+		return row
+	}
+	date, err := parseDate(t.value(record, FieldDate))
+	if err != nil {
+		row.Err = fmt.Errorf("date: %w", err)
+		return row
+	}
+	itemType := parseType(t.value(record, FieldType))
+	if _, ok := t.columns[FieldType]; !ok {
+		// Без колонки типа знак суммы определяет доход или расход.
+		itemType = "income"
+		if amount < 0 {
+			itemType = "expense"
+			amount = -amount
+		}
+	}
+	row.Item = &domain.Item{
+		Type:        itemType,
+		Amount:      amount,
+		Currency:    t.value(record, FieldCurrency),
+		Date:        date,
+		Category:    t.value(record, FieldCategory),
+		Description: t.value(record, FieldDescription),
+		ExternalID:  t.value(record, FieldExternalID),
+	}
+	return row
+}
+
+func parseType(s string) string {
+	switch strings.ToLower(s) {
+	case "income", "доход", "приход":
+		return "income"
+	case "expense", "расход":
+		return "expense"
+	}
+	return s
+}
+
+// parseAmount принимает суммы в записи таблиц: "1 234,50", "-10.5", "1234.50 ₽".
+func parseAmount(s string) (domain.Money, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '₽', '$', '€':
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Replace(s, ",", ".", 1)
+	return domain.ParseMoney(s)
+}
+
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	// Даты XLSX без форматирования приходят серийным номером дня.
+	if serial, err := strconv.ParseFloat(s, 64); err == nil {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+	return time.Time{}, fmt.Errorf("unsupported date %q", s)
+}
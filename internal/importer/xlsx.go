@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+
+	"sales-tracker/internal/domain"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type XLSXOptions struct {
+	// Sheet — лист с операциями; по умолчанию operations, если он есть, иначе первый.
+	Sheet   string
+	Mapping map[string]string
+}
+
+// ParseXLSX разбирает лист книги Excel с заголовком в первой строке.
+func ParseXLSX(r io.Reader, opts XLSXOptions) ([]*domain.ImportRow, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sheet := opts.Sheet
+	if sheet == "" {
+		// Книги экспорта SalesTracker держат операции на отдельном листе.
+		sheet = file.GetSheetName(0)
+		if index, _ := file.GetSheetIndex("operations"); index >= 0 {
+			sheet = "operations"
+		}
+	}
+	// Сырые значения: даты приходят серийными номерами, суммы — без форматирования.
+	rows, err := file.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var t *table
+	var result []*domain.ImportRow
+	line := 0
+	for rows.Next() {
+		line++
+		record, err := rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			if t, err = newTable(record, opts.Mapping); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if isBlank(record) {
+			continue
+		}
+		result = append(result, t.parse(line, record))
+	}
+	if err := rows.Error(); err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("sheet %q is empty", sheet)
+	}
+	return result, nil
+}
@@ -0,0 +1,286 @@
+package accounts_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/retry"
+)
+
+const accountColumns = `id, name, kind, currency, opening_balance, created_at`
+
+// accountsInWorkspace — счета текущего пространства; подзапрос нужен, чтобы
+// условие tenantdb.InWorkspace не стало неоднозначным в соединении с items.
+const accountsInWorkspace = `(SELECT ` + accountColumns + ` FROM accounts WHERE ` + tenantdb.InWorkspace + `)`
+
+// accountDelta — изменение остатка счета account операцией i: доход
+// увеличивает остаток, расход уменьшает, перевод списывает со счета
+// account_id и зачисляет на to_account_id.
+func accountDelta(account string) string {
+	return `CASE
+            WHEN i.type = 'income' THEN i.amount
+            WHEN i.type = 'expense' THEN -i.amount
+            WHEN i.account_id = ` + account + ` THEN -i.amount
+            ELSE i.amount
+        END`
+}
+
+// accountItems — условие соединения действующих операций со счетом account.
+func accountItems(account string) string {
+	return `(i.account_id = ` + account + ` OR i.to_account_id = ` + account + `) AND i.deleted_at IS NULL`
+}
+
+type AccountsPostgresRepository struct {
+	db      *tenantdb.DB
+	retries retry.Strategy
+}
+
+func NewAccountsPostgresRepository(db *tenantdb.DB, retries retry.Strategy) *AccountsPostgresRepository {
+	return &AccountsPostgresRepository{
+		db:      db,
+		retries: retries,
+	}
+}
+
+func (r *AccountsPostgresRepository) CreateAccount(ctx context.Context, account *domain.Account) error {
+	query := `
+		INSERT INTO accounts (workspace_id, name, kind, currency, opening_balance)
+		VALUES (current_workspace_id(), $1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query,
+		account.Name, account.Kind, account.Currency, account.OpeningBalance,
+	)
+	if err == nil {
+		err = row.Scan(&account.ID, &account.CreatedAt)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: account %q already exists", customErr.ErrAccountExists, account.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *AccountsPostgresRepository) GetAccount(ctx context.Context, id int64) (*domain.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE ` + tenantdb.InWorkspace + ` AND id = $1`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	account, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErr.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return account, nil
+}
+
+// ListAccountBalances возвращает счета пространства с остатками по всем
+// действующим операциям; операции в корзине остаток не меняют.
+func (r *AccountsPostgresRepository) ListAccountBalances(ctx context.Context) ([]*domain.AccountBalance, error) {
+	query := `
+		SELECT a.id, a.name, a.kind, a.currency, a.opening_balance, a.created_at,
+			a.opening_balance + COALESCE(SUM(` + accountDelta("a.id") + `), 0)
+		FROM ` + accountsInWorkspace + ` a
+		LEFT JOIN items i ON ` + accountItems("a.id") + `
+		GROUP BY a.id, a.name, a.kind, a.currency, a.opening_balance, a.created_at
+		ORDER BY a.name
+	`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	balances := []*domain.AccountBalance{}
+	for rows.Next() {
+		b := &domain.AccountBalance{Account: &domain.Account{}}
+		err := rows.Scan(
+			&b.Account.ID,
+			&b.Account.Name,
+			&b.Account.Kind,
+			&b.Account.Currency,
+			&b.Account.OpeningBalance,
+			&b.Account.CreatedAt,
+			&b.Balance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		balances = append(balances, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return balances, nil
+}
+
+func (r *AccountsPostgresRepository) UpdateAccount(ctx context.Context, id int64, patch *domain.AccountPatch) (*domain.Account, error) {
+	query := `
+		UPDATE accounts
+		SET name = COALESCE($2, name), kind = COALESCE($3, kind), opening_balance = COALESCE($4, opening_balance)
+		WHERE ` + tenantdb.InWorkspace + ` AND id = $1
+		RETURNING ` + accountColumns
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, id, patch.Name, patch.Kind, patch.OpeningBalance)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	account, err := scanAccount(row)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, customErr.ErrAccountNotFound
+	case isUniqueViolation(err):
+		return nil, fmt.Errorf("%w: account %q already exists", customErr.ErrAccountExists, *patch.Name)
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return account, nil
+}
+
+// DeleteAccount удаляет счёт без операций. Операции в корзине тоже
+// ссылаются на счёт и не дают его удалить, пока корзина не очищена.
+func (r *AccountsPostgresRepository) DeleteAccount(ctx context.Context, id int64) error {
+	query := `DELETE FROM accounts WHERE ` + tenantdb.InWorkspace + ` AND id = $1`
+	res, err := r.db.ExecWithRetry(ctx, r.retries, query, id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return fmt.Errorf("%w: account %d is referenced by items", customErr.ErrAccountInUse, id)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if n == 0 {
+		return customErr.ErrAccountNotFound
+	}
+	return nil
+}
+
+// GetStatement строит выписку счёта за период. Остатки считаются оконной
+// суммой по всем операциям счёта в порядке (date, id), поэтому остаток
+// каждой строки не зависит от страницы. Итоги и страница читаются в одном
+// снимке REPEATABLE READ.
+func (r *AccountsPostgresRepository) GetStatement(ctx context.Context, id int64, params *domain.AccountStatementParams) (*domain.AccountStatement, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer tx.Rollback()
+
+	summaryQuery := `
+		SELECT a.id, a.name, a.kind, a.currency, a.opening_balance, a.created_at,
+			a.opening_balance + COALESCE(SUM(` + accountDelta("a.id") + `) FILTER (WHERE i.date < $2), 0),
+			a.opening_balance + COALESCE(SUM(` + accountDelta("a.id") + `), 0),
+			COUNT(i.id) FILTER (WHERE $2::timestamptz IS NULL OR i.date >= $2)
+		FROM ` + accountsInWorkspace + ` a
+		LEFT JOIN items i ON ` + accountItems("a.id") + ` AND ($3::timestamptz IS NULL OR i.date <= $3)
+		WHERE a.id = $1
+		GROUP BY a.id, a.name, a.kind, a.currency, a.opening_balance, a.created_at
+	`
+	st := &domain.AccountStatement{Account: &domain.Account{}, Entries: []*domain.AccountEntry{}}
+	err = tx.QueryRowContext(ctx, summaryQuery, id, params.From, params.To).Scan(
+		&st.Account.ID,
+		&st.Account.Name,
+		&st.Account.Kind,
+		&st.Account.Currency,
+		&st.Account.OpeningBalance,
+		&st.Account.CreatedAt,
+		&st.OpeningBalance,
+		&st.ClosingBalance,
+		&st.Total,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErr.ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	entriesQuery := `
+		WITH entries AS (
+			SELECT i.id, i.type, i.amount, i.currency, i.date, i.category, i.description,
+				i.account_id, i.to_account_id, i.version, i.created_at, i.updated_at,
+				` + accountDelta("$1") + ` AS delta,
+				$4::numeric + SUM(` + accountDelta("$1") + `) OVER (ORDER BY i.date, i.id) AS balance
+			FROM items i
+			WHERE ` + accountItems("$1") + ` AND ($3::timestamptz IS NULL OR i.date <= $3)
+		)
+		SELECT * FROM entries
+		WHERE $2::timestamptz IS NULL OR date >= $2
+		ORDER BY date, id
+		OFFSET $5 LIMIT $6
+	`
+	rows, err := tx.QueryContext(ctx, entriesQuery,
+		id, params.From, params.To, st.Account.OpeningBalance, params.Offset, params.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		e := &domain.AccountEntry{Item: &domain.Item{}}
+		err := rows.Scan(
+			&e.Item.ID,
+			&e.Item.Type,
+			&e.Item.Amount,
+			&e.Item.Currency,
+			&e.Item.Date,
+			&e.Item.Category,
+			&e.Item.Description,
+			&e.Item.AccountID,
+			&e.Item.ToAccountID,
+			&e.Item.Version,
+			&e.Item.CreatedAt,
+			&e.Item.UpdatedAt,
+			&e.Amount,
+			&e.Balance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		st.Entries = append(st.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return st, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func scanAccount(row interface{ Scan(dest ...any) error }) (*domain.Account, error) {
+	account := &domain.Account{}
+	err := row.Scan(
+		&account.ID,
+		&account.Name,
+		&account.Kind,
+		&account.Currency,
+		&account.OpeningBalance,
+		&account.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
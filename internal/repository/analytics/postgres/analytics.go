@@ -6,28 +6,79 @@ import (
 	"fmt"
 	"sales-tracker/internal/domain"
 	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
 	"time"
 
-	"github.com/wb-go/wbf/dbpg"
 	"github.com/wb-go/wbf/retry"
 )
 
+// ConvertTo пересчитывает сумму amount в валюте currency в валюту $3 по
+// курсу на дату date. Каждая сумма округляется до копейки до агрегации;
+// без курса результат NULL. Используется и в учёте, чтобы сверка с
+// аналитикой считала суммы тем же способом.
+func ConvertTo(amount, currency, date string) string {
+	return `
+    CASE
+        WHEN ` + currency + ` = $3 THEN ` + amount + `
+        ELSE ROUND(` + amount + ` * exchange_rate_on(` + currency + `, ` + date + `) / exchange_rate_on($3, ` + date + `), 2)
+    END`
+}
+
+// convertedAmount пересчитывает сумму операции в валюту $3 по курсу на дату операции.
+var convertedAmount = ConvertTo("amount", "currency", "date")
+
+// notTransfer исключает переводы между счетами: они не доход и не расход,
+// поэтому не входят ни в один агрегат. В детализации переводы остаются.
+const notTransfer = `type <> '` + domain.ItemTypeTransfer + `'`
+
+// ConvertedItems — доходы и расходы текущего пространства за период
+// [$1, $2] с суммами, пересчитанными в валюту $3 (колонки type и amount).
+// Сумма операции без курса пересчёта — NULL. Это общая база аналитики
+// и сверки учёта с ней.
+var ConvertedItems = `
+    SELECT type, ` + convertedAmount + ` AS amount
+    FROM items
+    WHERE ` + tenantdb.InWorkspace + ` AND ` + notTransfer + ` AND date BETWEEN $1 AND $2 AND deleted_at IS NULL`
+
+// convertedByType — выборка пересчитанных сумм операций типа $4 за период [$1, $2].
+// Все запросы аналитики ограничены текущим пространством.
+var convertedByType = `
+    WITH converted AS (
+        SELECT amount FROM (` + ConvertedItems + `) i WHERE type = $4
+    )`
+
 type AnalyticsPostgresRepository struct {
-	db      *dbpg.DB
+	db      *tenantdb.DB
 	retries retry.Strategy
 }
 
-func NewAnalyticsPostgresRepository(db *dbpg.DB, retries retry.Strategy) *AnalyticsPostgresRepository {
+func NewAnalyticsPostgresRepository(db *tenantdb.DB, retries retry.Strategy) *AnalyticsPostgresRepository {
 	return &AnalyticsPostgresRepository{
 		db:      db,
 		retries: retries,
 	}
 }
 
-func (r *AnalyticsPostgresRepository) GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
+// ensureConvertible проверяет, что для всех операций периода есть курсы пересчёта в currency.
+func (r *AnalyticsPostgresRepository) ensureConvertible(ctx context.Context, tx *sql.Tx, from, to time.Time, currency string) error {
+	query := `SELECT COUNT(*) FROM (` + ConvertedItems + `) i WHERE amount IS NULL`
+
+	var missing int64
+	row := tx.QueryRowContext(ctx, query, from, to, currency)
+	if err := row.Scan(&missing); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if missing > 0 {
+		return fmt.Errorf("%w: %d items cannot be converted to %s", customErr.ErrRateNotFound, missing, currency)
+	}
+	return nil
+}
+
+func (r *AnalyticsPostgresRepository) GetAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error) {
 	analytics := &domain.Analytics{
-		Income:  &domain.ItemAnalytics{},
-		Expense: &domain.ItemAnalytics{},
+		Currency: params.Currency,
+		Income:   &domain.ItemAnalytics{},
+		Expense:  &domain.ItemAnalytics{},
 	}
 
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
@@ -39,71 +90,115 @@ func (r *AnalyticsPostgresRepository) GetAnalytics(ctx context.Context, from, to
 	}
 	defer tx.Rollback()
 
-	incomeQuery := `
+	if err := r.ensureConvertible(ctx, tx, params.From, params.To, params.Currency); err != nil {
+		return nil, err
+	}
+
+	statsQuery := convertedByType + `
     SELECT
-        COALESCE(SUM(amount), 0) AS sum,
-        COALESCE(AVG(amount), 0) AS avg,
+        ROUND(COALESCE(SUM(amount), 0), 2) AS sum,
+        ROUND(COALESCE(AVG(amount), 0), 2) AS avg,
         COUNT(*) AS count,
-        COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY amount), 0) AS median,
-        COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY amount), 0) AS percent90
-    FROM items
-    WHERE date BETWEEN $1 AND $2 AND type = 'income'
+        ROUND(COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY amount), 0)::numeric, 2) AS median,
+        ROUND(COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY amount), 0)::numeric, 2) AS percent90
+    FROM converted
     `
 
-	row := tx.QueryRowContext(ctx, incomeQuery, from, to)
-	err = row.Scan(
-		&analytics.Income.Sum,
-		&analytics.Income.Avg,
-		&analytics.Income.Count,
-		&analytics.Income.Median,
-		&analytics.Income.Percent90,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	for itemType, stats := range map[string]*domain.ItemAnalytics{
+		"income":  analytics.Income,
+		"expense": analytics.Expense,
+	} {
+		row := tx.QueryRowContext(ctx, statsQuery, params.From, params.To, params.Currency, itemType)
+		err = row.Scan(
+			&stats.Sum,
+			&stats.Avg,
+			&stats.Count,
+			&stats.Median,
+			&stats.Percent90,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+
+		if len(params.Percentiles) > 0 {
+			stats.Percentiles, err = r.getPercentiles(ctx, tx, params, itemType)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if params.Histogram != "" {
+			stats.Histogram, err = r.getHistogram(ctx, tx, params, itemType)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	expenseQuery := `
-    SELECT
-        COALESCE(SUM(amount), 0) AS sum,
-        COALESCE(AVG(amount), 0) AS avg,
-        COUNT(*) AS count,
-        COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY amount), 0) AS median,
-        COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY amount), 0) AS percent90
-    FROM items
-    WHERE date BETWEEN $1 AND $2 AND type = 'expense'
-    `
+	if !params.SkipDetails {
+		details, err := r.getDetails(ctx, tx, params)
+		if err != nil {
+			return nil, err
+		}
+		analytics.Details = details
+	}
 
-	row = tx.QueryRowContext(ctx, expenseQuery, from, to)
-	err = row.Scan(
-		&analytics.Expense.Sum,
-		&analytics.Expense.Avg,
-		&analytics.Expense.Count,
-		&analytics.Expense.Median,
-		&analytics.Expense.Percent90,
-	)
-	if err != nil {
+	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
 
-	detailsQuery := `
-    SELECT id, type, amount, date, category, description, created_at, updated_at
+	return analytics, nil
+}
+
+const detailsQuery = `
+    SELECT id, type, amount, currency, date, category, description, created_at, updated_at
     FROM items
-    WHERE date BETWEEN $1 AND $2
+    WHERE ` + tenantdb.InWorkspace + ` AND date BETWEEN $1 AND $2 AND deleted_at IS NULL
     ORDER BY date DESC
     `
 
-	rows, err := tx.QueryContext(ctx, detailsQuery, from, to)
+func (r *AnalyticsPostgresRepository) getDetails(ctx context.Context, tx *sql.Tx, params *domain.AnalyticsParams) ([]*domain.Item, error) {
+	var details []*domain.Item
+	rows, err := tx.QueryContext(ctx, detailsQuery, params.From, params.To)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
-	defer rows.Close()
+	err = scanDetails(rows, func(item *domain.Item) error {
+		details = append(details, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// StreamDetails передаёт операции периода в fn по мере чтения из БД,
+// не накапливая их в памяти. Ошибка fn прерывает чтение и возвращается как есть.
+func (r *AnalyticsPostgresRepository) StreamDetails(ctx context.Context, params *domain.AnalyticsParams, fn func(*domain.Item) error) error {
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, detailsQuery, params.From, params.To)
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return scanDetails(rows, fn)
+}
+
+// detailRows — выборка операций в транзакции аналитики или отдельным запросом.
+type detailRows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close() error
+}
 
+func scanDetails(rows detailRows, fn func(*domain.Item) error) error {
+	defer rows.Close()
 	for rows.Next() {
 		item := &domain.Item{}
 		err := rows.Scan(
 			&item.ID,
 			&item.Type,
 			&item.Amount,
+			&item.Currency,
 			&item.Date,
 			&item.Category,
 			&item.Description,
@@ -111,18 +206,15 @@ func (r *AnalyticsPostgresRepository) GetAnalytics(ctx context.Context, from, to
 			&item.UpdatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+			return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		if err := fn(item); err != nil {
+			return err
 		}
-		analytics.Details = append(analytics.Details, item)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
-	}
-
-	return analytics, nil
+	return nil
 }
@@ -0,0 +1,143 @@
+package analytics_postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+	"strings"
+)
+
+// GetBreakdown считает статистику по группам params.GroupBy. Доходы и расходы
+// никогда не складываются: группы всегда разделены по типу операции, а Top
+// и доля share считаются внутри своего типа. Если задан Top, группы типа за
+// пределами первых Top по сумме объединяются в группу «прочее» этого типа,
+// для которой медиана и перцентиль считаются по её собственным операциям.
+func (r *AnalyticsPostgresRepository) GetBreakdown(ctx context.Context, params *domain.BreakdownParams) (*domain.Breakdown, error) {
+	breakdown := &domain.Breakdown{
+		GroupBy:  params.GroupBy,
+		Currency: params.Currency,
+	}
+
+	args := []any{params.From, params.To, params.Currency}
+	dims := make([]string, len(params.GroupBy))
+	dimExprs := make([]string, len(params.GroupBy))
+	for i, dim := range params.GroupBy {
+		dims[i] = fmt.Sprintf("d%d", i)
+		switch dim {
+		case "category":
+			dimExprs[i] = "COALESCE(category, '')"
+		case "type":
+			dimExprs[i] = "type"
+		case "month":
+			args = append(args, params.TimeZone)
+			dimExprs[i] = fmt.Sprintf("to_char(date AT TIME ZONE $%d, 'YYYY-MM')", len(args))
+		default:
+			return nil, fmt.Errorf("%w: unsupported group_by %q", customErr.ErrInvalidInput, dim)
+		}
+		dimExprs[i] += " AS " + dims[i]
+	}
+	args = append(args, params.Top)
+	topArg := fmt.Sprintf("$%d", len(args))
+
+	joinConds := []string{"r.t = c.t"}
+	labeled := make([]string, len(dims))
+	for i, d := range dims {
+		joinConds = append(joinConds, fmt.Sprintf("r.%s = c.%s", d, d))
+		if params.GroupBy[i] == "type" {
+			labeled[i] = "c." + d
+			continue
+		}
+		labeled[i] = fmt.Sprintf("CASE WHEN r.is_other THEN NULL ELSE c.%s END AS %s", d, d)
+	}
+	dimList := strings.Join(dims, ", ")
+
+	query := `
+    WITH converted AS (
+        SELECT ` + strings.Join(dimExprs, ", ") + `, type AS t, ` + convertedAmount + ` AS amount
+        FROM items
+        WHERE ` + tenantdb.InWorkspace + ` AND ` + notTransfer + ` AND date BETWEEN $1 AND $2 AND deleted_at IS NULL
+    ),
+    ranked AS (
+        SELECT ` + dimList + `, t,
+            ` + topArg + ` > 0 AND ROW_NUMBER() OVER (PARTITION BY t ORDER BY SUM(amount) DESC, ` + dimList + `) > ` + topArg + ` AS is_other
+        FROM converted
+        GROUP BY ` + dimList + `, t
+    ),
+    labeled AS (
+        SELECT ` + strings.Join(labeled, ", ") + `, c.t, r.is_other, c.amount
+        FROM converted c
+        JOIN ranked r ON ` + strings.Join(joinConds, " AND ") + `
+    )
+    SELECT
+        ` + dimList + `,
+        is_other,
+        ROUND(SUM(amount), 2) AS sum,
+        ROUND(AVG(amount), 2) AS avg,
+        COUNT(*) AS count,
+        ROUND((PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY amount))::numeric, 2) AS median,
+        ROUND((PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY amount))::numeric, 2) AS percent90,
+        COALESCE(ROUND(100 * SUM(amount) / NULLIF(SUM(SUM(amount)) OVER (PARTITION BY t), 0), 2), 0) AS share
+    FROM labeled
+    GROUP BY ` + dimList + `, t, is_other
+    ORDER BY t, is_other, sum DESC, ` + dimList
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer tx.Rollback()
+
+	if err := r.ensureConvertible(ctx, tx, params.From, params.To, params.Currency); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		keys := make([]sql.NullString, len(dims))
+		group := &domain.BreakdownGroup{Stats: &domain.ItemAnalytics{}}
+		dest := make([]any, 0, len(dims)+7)
+		for i := range keys {
+			dest = append(dest, &keys[i])
+		}
+		dest = append(dest,
+			&group.Other,
+			&group.Stats.Sum,
+			&group.Stats.Avg,
+			&group.Stats.Count,
+			&group.Stats.Median,
+			&group.Stats.Percent90,
+			&group.Share,
+		)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		group.Keys = make(map[string]string, len(dims))
+		for i, key := range keys {
+			if key.Valid {
+				group.Keys[params.GroupBy[i]] = key.String
+			}
+		}
+		breakdown.Groups = append(breakdown.Groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	return breakdown, nil
+}
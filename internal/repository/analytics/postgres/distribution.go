@@ -0,0 +1,100 @@
+package analytics_postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+
+	"github.com/lib/pq"
+)
+
+func (r *AnalyticsPostgresRepository) getPercentiles(ctx context.Context, tx *sql.Tx, params *domain.AnalyticsParams, itemType string) (map[float64]domain.Money, error) {
+	query := convertedByType + `,
+    computed AS (
+        SELECT PERCENTILE_CONT($5::float8[]) WITHIN GROUP (ORDER BY amount) AS vals
+        FROM converted
+    )
+    SELECT u.p, ROUND(COALESCE(u.v, 0)::numeric, 2)
+    FROM computed, unnest($5::float8[], computed.vals) AS u(p, v)
+    `
+
+	rows, err := tx.QueryContext(ctx, query, params.From, params.To, params.Currency, itemType, pq.Array(params.Percentiles))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	percentiles := make(map[float64]domain.Money, len(params.Percentiles))
+	for rows.Next() {
+		var p float64
+		var value domain.Money
+		if err := rows.Scan(&p, &value); err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		percentiles[p] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return percentiles, nil
+}
+
+// getHistogram строит гистограмму сумм: границы корзин рассчитываются по
+// минимальной и максимальной сумме, а попадание в корзины считает width_bucket.
+func (r *AnalyticsPostgresRepository) getHistogram(ctx context.Context, tx *sql.Tx, params *domain.AnalyticsParams, itemType string) ([]*domain.HistogramBin, error) {
+	boundsQuery := convertedByType + `
+    SELECT COALESCE(MIN(amount), 0), COALESCE(MAX(amount), 0), COUNT(*)
+    FROM converted
+    `
+
+	var lo, hi domain.Money
+	var count int64
+	row := tx.QueryRowContext(ctx, boundsQuery, params.From, params.To, params.Currency, itemType)
+	if err := row.Scan(&lo, &hi, &count); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if count == 0 {
+		return []*domain.HistogramBin{}, nil
+	}
+
+	edges := domain.HistogramEdges(params.Histogram, lo, hi, params.Bins)
+	bins := make([]*domain.HistogramBin, len(edges))
+	thresholds := make([]string, len(edges))
+	for i, edge := range edges {
+		to := hi
+		if i+1 < len(edges) {
+			to = edges[i+1]
+		}
+		bins[i] = &domain.HistogramBin{From: edge, To: to}
+		thresholds[i] = edge.String()
+	}
+
+	countsQuery := convertedByType + `
+    SELECT GREATEST(width_bucket(amount, $5::numeric[]), 1) AS bin, COUNT(*)
+    FROM converted
+    GROUP BY bin
+    `
+
+	rows, err := tx.QueryContext(ctx, countsQuery, params.From, params.To, params.Currency, itemType, pq.Array(thresholds))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bin int
+		var binCount int64
+		if err := rows.Scan(&bin, &binCount); err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		if bin >= 1 && bin <= len(bins) {
+			bins[bin-1].Count = binCount
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return bins, nil
+}
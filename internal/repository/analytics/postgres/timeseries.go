@@ -0,0 +1,110 @@
+package analytics_postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+)
+
+// intervalSteps — шаг generate_series для каждого поддерживаемого интервала.
+var intervalSteps = map[string]string{
+	"day":     "1 day",
+	"week":    "1 week",
+	"month":   "1 month",
+	"quarter": "3 months",
+}
+
+// GetTimeSeries группирует операции по интервалам date_trunc в часовом поясе params.TimeZone.
+// Интервалы без операций заполняются нулями за счёт generate_series.
+func (r *AnalyticsPostgresRepository) GetTimeSeries(ctx context.Context, params *domain.TimeSeriesParams) (*domain.TimeSeries, error) {
+	step, ok := intervalSteps[params.Interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported interval %q", customErr.ErrInvalidInput, params.Interval)
+	}
+
+	series := &domain.TimeSeries{
+		Interval: params.Interval,
+		TimeZone: params.TimeZone,
+		Currency: params.Currency,
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer tx.Rollback()
+
+	if err := r.ensureConvertible(ctx, tx, params.From, params.To, params.Currency); err != nil {
+		return nil, err
+	}
+
+	query := `
+    WITH buckets AS (
+        SELECT generate_series(
+            date_trunc($4, $1::timestamptz AT TIME ZONE $5),
+            date_trunc($4, $2::timestamptz AT TIME ZONE $5),
+            $6::interval
+        ) AS bucket
+    ),
+    converted AS (
+        SELECT
+            date_trunc($4, date AT TIME ZONE $5) AS bucket,
+            type,
+            ` + convertedAmount + ` AS amount
+        FROM items
+        WHERE ` + tenantdb.InWorkspace + ` AND ` + notTransfer + ` AND date BETWEEN $1 AND $2 AND deleted_at IS NULL
+    )
+    SELECT
+        b.bucket AT TIME ZONE $5 AS start,
+        ROUND(COALESCE(SUM(c.amount) FILTER (WHERE c.type = 'income'), 0), 2),
+        COUNT(c.amount) FILTER (WHERE c.type = 'income'),
+        ROUND(COALESCE(AVG(c.amount) FILTER (WHERE c.type = 'income'), 0), 2),
+        ROUND(COALESCE(SUM(c.amount) FILTER (WHERE c.type = 'expense'), 0), 2),
+        COUNT(c.amount) FILTER (WHERE c.type = 'expense'),
+        ROUND(COALESCE(AVG(c.amount) FILTER (WHERE c.type = 'expense'), 0), 2)
+    FROM buckets b
+    LEFT JOIN converted c ON c.bucket = b.bucket
+    GROUP BY b.bucket
+    ORDER BY b.bucket
+    `
+
+	rows, err := tx.QueryContext(ctx, query, params.From, params.To, params.Currency, params.Interval, params.TimeZone, step)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		bucket := &domain.TimeSeriesBucket{}
+		err := rows.Scan(
+			&bucket.Start,
+			&bucket.Income.Sum,
+			&bucket.Income.Count,
+			&bucket.Income.Avg,
+			&bucket.Expense.Sum,
+			&bucket.Expense.Count,
+			&bucket.Expense.Avg,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		bucket.Net = bucket.Income.Sum - bucket.Expense.Sum
+		series.Buckets = append(series.Buckets, bucket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	return series, nil
+}
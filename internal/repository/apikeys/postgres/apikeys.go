@@ -0,0 +1,142 @@
+package apikeys_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/retry"
+)
+
+const apiKeyColumns = `id, workspace_id, name, prefix, key_hash, roles, created_at, expires_at, last_used_at, revoked_at`
+
+type APIKeysPostgresRepository struct {
+	db      *tenantdb.DB
+	retries retry.Strategy
+}
+
+func NewAPIKeysPostgresRepository(db *tenantdb.DB, retries retry.Strategy) *APIKeysPostgresRepository {
+	return &APIKeysPostgresRepository{
+		db:      db,
+		retries: retries,
+	}
+}
+
+func (r *APIKeysPostgresRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (workspace_id, name, prefix, key_hash, roles, expires_at)
+		VALUES (current_workspace_id(), $1, $2, $3, $4, $5)
+		RETURNING id, workspace_id, created_at
+	`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query,
+		key.Name, key.Prefix, key.Hash, pq.Array(key.Roles), key.ExpiresAt,
+	)
+	if err == nil {
+		err = row.Scan(&key.ID, &key.WorkspaceID, &key.CreatedAt)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return fmt.Errorf("%w: %s", customErr.ErrAPIKeyPrefixTaken, key.Prefix)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+// GetAPIKeyByPrefix ищет ключ до того, как известно его пространство,
+// поэтому вызывается в контексте, открывающем все пространства.
+func (r *APIKeysPostgresRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix = $1`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErr.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return key, nil
+}
+
+func (r *APIKeysPostgresRepository) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE ` + tenantdb.InWorkspace + ` ORDER BY id`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ; повторный отзыв не меняет время отзыва.
+func (r *APIKeysPostgresRepository) RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error) {
+	query := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now())
+		WHERE ` + tenantdb.InWorkspace + ` AND id = $1
+		RETURNING ` + apiKeyColumns
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErr.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return key, nil
+}
+
+// TouchAPIKey отмечает использование ключа не чаще раза в минуту, чтобы не
+// писать в базу на каждый запрос.
+func (r *APIKeysPostgresRepository) TouchAPIKey(ctx context.Context, id int64) error {
+	query := `
+		UPDATE api_keys SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	if _, err := r.db.ExecWithRetry(ctx, r.retries, query, id); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	err := row.Scan(
+		&key.ID,
+		&key.WorkspaceID,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		pq.Array(&key.Roles),
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,41 @@
+package artifacts_local
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Storage хранит готовые отчёты в каталоге на локальном диске.
+type Storage struct {
+	dir string
+}
+
+func NewStorage(dir string) (*Storage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create artifacts dir: %w", err)
+	}
+	return &Storage{dir: dir}, nil
+}
+
+// Create открывает файл на запись и возвращает путь, который сохраняется в задаче.
+func (s *Storage) Create(name string) (io.WriteCloser, string, error) {
+	path := filepath.Join(s.dir, filepath.Base(name))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, path, nil
+}
+
+func (s *Storage) Open(path string) (io.ReadSeekCloser, error) {
+	return os.Open(path)
+}
+
+func (s *Storage) Remove(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
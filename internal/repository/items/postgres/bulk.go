@@ -0,0 +1,174 @@
+package items_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+)
+
+// ExecBulk выполняет операции пакета одной транзакцией и возвращает их итоги.
+// Первая неудачная операция откатывает транзакцию; ошибка возвращается
+// как *domain.BulkOpError с индексом операции.
+func (r *ItemsPostgresRepository) ExecBulk(ctx context.Context, ops []*domain.BulkOperation) ([]*domain.BulkOpResult, error) {
+	var results []*domain.BulkOpResult
+	err := r.db.WithTxWithRetry(ctx, r.retries, func(tx *sql.Tx) error {
+		results = make([]*domain.BulkOpResult, len(ops))
+		for i, op := range ops {
+			res, err := execBulkOp(ctx, tx, op)
+			if err != nil {
+				return &domain.BulkOpError{Index: i, Err: err}
+			}
+			res.Index = i
+			results[i] = res
+		}
+		return nil
+	})
+	if err != nil {
+		var opErr *domain.BulkOpError
+		if errors.As(err, &opErr) {
+			return nil, opErr
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return results, nil
+}
+
+func execBulkOp(ctx context.Context, tx *sql.Tx, op *domain.BulkOperation) (*domain.BulkOpResult, error) {
+	res := &domain.BulkOpResult{Op: op.Op, ID: op.ID}
+	switch op.Op {
+	case domain.BulkOpCreate:
+		item := op.Item
+		err := tx.QueryRowContext(ctx, createItemQuery, createItemArgs(ctx, item)...).Scan(&res.ID, &res.Version)
+		if err != nil {
+			return nil, writeError(err)
+		}
+	case domain.BulkOpUpdate:
+		item := op.Item
+		err := tx.QueryRowContext(ctx, updateItemQuery, updateItemArgs(ctx, op.ID, item, op.Version)...).Scan(&res.Version, &item.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, missingOrStaleTx(ctx, tx, op.ID)
+		}
+		if err != nil {
+			return nil, writeError(err)
+		}
+	case domain.BulkOpDelete:
+		err := tx.QueryRowContext(ctx, deleteItemQuery, auditArgs(ctx, op.ID, op.Version)...).Scan(new(int64))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, missingOrStaleTx(ctx, tx, op.ID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown bulk operation %q", customErr.ErrInvalidInput, op.Op)
+	}
+	return res, nil
+}
+
+func missingOrStaleTx(ctx context.Context, tx *sql.Tx, id int64) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, itemExistsQuery, id).Scan(&exists); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if exists {
+		return customErr.ErrVersionMismatch
+	}
+	return customErr.ErrItemNotFound
+}
+
+// CountItems возвращает число операций, подходящих под фильтр.
+func (r *ItemsPostgresRepository) CountItems(ctx context.Context, filter *domain.ItemsFilter) (int64, error) {
+	conds, args := buildItemsFilter(filter)
+	var total int64
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, `SELECT COUNT(*) FROM items `+whereClause(conds), args...)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if err := row.Scan(&total); err != nil {
+		return 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return total, nil
+}
+
+// UpdateItemsByFilter применяет патч ко всем операциям, подходящим под фильтр,
+// увеличивая их версии, и возвращает число изменённых записей.
+func (r *ItemsPostgresRepository) UpdateItemsByFilter(ctx context.Context, filter *domain.ItemsFilter, patch *domain.ItemPatch) (int64, error) {
+	conds, args := buildItemsFilter(filter)
+	sets := []string{"version = version + 1", "updated_at = now()"}
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if patch.Type != nil {
+		set("type", *patch.Type)
+	}
+	if patch.Amount != nil {
+		set("amount", *patch.Amount)
+	}
+	if patch.Currency != nil {
+		set("currency", *patch.Currency)
+	}
+	if patch.Date != nil {
+		set("date", *patch.Date)
+	}
+	if patch.Category != nil {
+		set("category", *patch.Category)
+	}
+	if patch.Description != nil {
+		set("description", *patch.Description)
+	}
+	if patch.AccountID != nil {
+		set("account_id", accountArg(*patch.AccountID))
+	}
+	if patch.ToAccountID != nil {
+		set("to_account_id", accountArg(*patch.ToAccountID))
+	}
+	return r.changeByFilter(ctx, domain.AuditActionUpdate, strings.Join(sets, ", "), conds, args)
+}
+
+// DeleteItemsByFilter перемещает в корзину операции, подходящие под фильтр.
+func (r *ItemsPostgresRepository) DeleteItemsByFilter(ctx context.Context, filter *domain.ItemsFilter) (int64, error) {
+	conds, args := buildItemsFilter(filter)
+	return r.changeByFilter(ctx, domain.AuditActionDelete, "deleted_at = now(), version = version + 1", conds, args)
+}
+
+// changeByFilter выполняет UPDATE items SET sets для строк, подходящих под
+// conds, с записью каждого изменения в историю, и возвращает число строк.
+func (r *ItemsPostgresRepository) changeByFilter(ctx context.Context, action, sets string, conds []string, args []any) (int64, error) {
+	query := `
+		WITH old AS (
+			SELECT id, ` + itemSnapshot + ` AS before
+			FROM items
+			` + whereClause(conds) + `
+			FOR UPDATE
+		), changed AS (
+			UPDATE items
+			SET ` + sets + `
+			FROM old
+			WHERE items.id = old.id
+			RETURNING items.id, old.before, ` + itemSnapshot + ` AS after
+		), ` + historyCTE(action, len(args)+1) + `
+		SELECT COUNT(*) FROM changed
+	`
+	var n int64
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, auditArgs(ctx, args...)...)
+	if err != nil {
+		return 0, writeError(err)
+	}
+	if err := row.Scan(&n); err != nil {
+		return 0, writeError(err)
+	}
+	return n, nil
+}
+
+// accountArg переводит ссылку на счёт из патча в значение столбца: 0 снимает привязку.
+func accountArg(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
@@ -0,0 +1,85 @@
+package items_postgres
+
+import (
+	"fmt"
+	"sales-tracker/internal/domain"
+	"sales-tracker/internal/repository/tenantdb"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// sortColumns — белый список полей сортировки; значения подставляются в SQL напрямую.
+var sortColumns = map[string]string{
+	"date":       "date",
+	"amount":     "amount",
+	"type":       "type",
+	"category":   "category",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"id":         "id",
+}
+
+// buildItemsFilter всегда ограничивает выборку текущим пространством
+// и исключает операции из корзины.
+func buildItemsFilter(filter *domain.ItemsFilter) ([]string, []any) {
+	conds := []string{tenantdb.InWorkspace, "deleted_at IS NULL"}
+	var args []any
+	if filter == nil {
+		return conds, args
+	}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Type != "" {
+		add("type = $%d", filter.Type)
+	}
+	if len(filter.Categories) > 0 {
+		add("category = ANY($%d)", pq.Array(filter.Categories))
+	}
+	if filter.From != nil {
+		add("date >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("date <= $%d", *filter.To)
+	}
+	if filter.MinAmount != nil {
+		add("amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		add("amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.Search != "" {
+		add("search_vector @@ websearch_to_tsquery('russian', $%d)", filter.Search)
+	}
+	if filter.AccountID > 0 {
+		add("(account_id = $%[1]d OR to_account_id = $%[1]d)", filter.AccountID)
+	}
+
+	return conds, args
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
+
+func buildItemsOrder(filter *domain.ItemsFilter) string {
+	column, order := "date", "DESC"
+	if filter != nil {
+		if c, ok := sortColumns[filter.SortBy]; ok {
+			column = c
+		}
+		if filter.SortOrder == domain.SortOrderAsc {
+			order = "ASC"
+		}
+	}
+	if column == "id" {
+		return "ORDER BY id " + order
+	}
+	return fmt.Sprintf("ORDER BY %s %s, id %s", column, order, order)
+}
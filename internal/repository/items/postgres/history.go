@@ -0,0 +1,143 @@
+package items_postgres
+
+import (
+	"context"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+)
+
+// itemSnapshot — снимок строки items для истории изменений.
+const itemSnapshot = `to_jsonb(items) - 'search_vector'`
+
+// historyCTE записывает в item_history строки CTE changed, которая должна
+// возвращать id, before и after. Актор и ID запроса передаются
+// параметрами $actorArg и $actorArg+1 — см. auditArgs. Пространство записи
+// берётся из снимка операции: очистка корзины обходит все пространства сразу.
+func historyCTE(action string, actorArg int) string {
+	return fmt.Sprintf(`history AS (
+		INSERT INTO item_history (workspace_id, item_id, action, before, after, actor, request_id)
+		SELECT (coalesce(after, before)->>'workspace_id')::bigint, id, '%s', before, after, $%d, $%d FROM changed
+	)`, action, actorArg, actorArg+1)
+}
+
+// auditArgs дописывает к args автора изменения и ID запроса из контекста.
+func auditArgs(ctx context.Context, args ...any) []any {
+	meta := domain.AuditMetaFrom(ctx)
+	return append(args, meta.Actor, meta.RequestID)
+}
+
+const historyColumns = `id, seq, item_id, action, before, after, actor, request_id, created_at, prev_hash, hash`
+
+func scanHistory(row rowScanner) (*domain.AuditEntry, error) {
+	entry := &domain.AuditEntry{}
+	var before, after []byte
+	err := row.Scan(
+		&entry.ID,
+		&entry.Seq,
+		&entry.ItemID,
+		&entry.Action,
+		&before,
+		&after,
+		&entry.Actor,
+		&entry.RequestID,
+		&entry.CreatedAt,
+		&entry.PrevHash,
+		&entry.Hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	entry.Before, entry.After = before, after
+	return entry, nil
+}
+
+// GetItemHistory возвращает историю операции в порядке изменений,
+// в том числе для удалённых операций.
+func (r *ItemsPostgresRepository) GetItemHistory(ctx context.Context, itemID int64) ([]*domain.AuditEntry, error) {
+	query := `
+		SELECT ` + historyColumns + `
+		FROM item_history
+		WHERE ` + tenantdb.InWorkspace + ` AND item_id = $1
+		ORDER BY seq
+	`
+	return r.queryHistory(ctx, query, itemID)
+}
+
+// GetAudit возвращает журнал изменений операций пространства в порядке записи.
+func (r *ItemsPostgresRepository) GetAudit(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	conds := []string{tenantdb.InWorkspace}
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	add("seq > $%d", filter.After)
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at <= $%d", *filter.To)
+	}
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(`
+		SELECT `+historyColumns+`
+		FROM item_history
+		%s
+		ORDER BY seq
+		LIMIT $%d
+	`, whereClause(conds), len(args))
+	return r.queryHistory(ctx, query, args...)
+}
+
+// StreamHistory передаёт fn все записи журнала пространства в порядке цепочки.
+func (r *ItemsPostgresRepository) StreamHistory(ctx context.Context, fn func(*domain.AuditEntry) error) error {
+	query := `
+		SELECT ` + historyColumns + `
+		FROM item_history
+		WHERE ` + tenantdb.InWorkspace + `
+		ORDER BY seq
+	`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query)
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry, err := scanHistory(rows)
+		if err != nil {
+			return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *ItemsPostgresRepository) queryHistory(ctx context.Context, query string, args ...any) ([]*domain.AuditEntry, error) {
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		entry, err := scanHistory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,63 @@
+package items_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+	"time"
+)
+
+// CreateItemIdempotent создаёт запись не более одного раза на ключ; ключи
+// разных пространств не пересекаются.
+// Запросы с одним ключом сериализуются advisory-блокировкой, поэтому
+// параллельный повтор дождётся первого и получит его результат.
+// Возвращает ID записи и признак того, что ответ взят из сохранённого.
+func (r *ItemsPostgresRepository) CreateItemIdempotent(ctx context.Context, key, requestHash string, ttl time.Duration, item *domain.Item) (int64, bool, error) {
+	var (
+		id       int64
+		replayed bool
+	)
+	err := r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext(current_workspace_id() || ':' || $1))`, key); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE `+tenantdb.InWorkspace+` AND expires_at <= now()`); err != nil {
+			return err
+		}
+
+		var storedHash string
+		err := tx.QueryRowContext(ctx,
+			`SELECT request_hash, item_id FROM idempotency_keys WHERE `+tenantdb.InWorkspace+` AND key = $1`, key,
+		).Scan(&storedHash, &id)
+		switch {
+		case err == nil:
+			if storedHash != requestHash {
+				return customErr.ErrIdempotencyKey
+			}
+			replayed = true
+			return nil
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, createItemQuery, createItemArgs(ctx, item)...).Scan(&id, &item.Version)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO idempotency_keys (workspace_id, key, request_hash, item_id, expires_at)
+			VALUES (current_workspace_id(), $1, $2, $3, now() + $4 * interval '1 second')
+		`, key, requestHash, id, ttl.Seconds())
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, customErr.ErrIdempotencyKey) {
+			return 0, false, err
+		}
+		return 0, false, writeError(err)
+	}
+	return id, replayed, nil
+}
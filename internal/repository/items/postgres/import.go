@@ -0,0 +1,76 @@
+package items_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+
+	"github.com/lib/pq"
+)
+
+// CreateItems вставляет операции одной транзакцией и заполняет их ID.
+// Операции с уже загруженным external_id пропускаются: их ID остаётся нулевым.
+// При ошибке транзакция откатывается целиком.
+func (r *ItemsPostgresRepository) CreateItems(ctx context.Context, items []*domain.Item) error {
+	query := `
+		WITH changed AS (
+			INSERT INTO items (workspace_id, type, amount, currency, date, category, description, external_id, account_id)
+			VALUES (current_workspace_id(), $1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8)
+			ON CONFLICT (workspace_id, external_id) WHERE external_id IS NOT NULL DO NOTHING
+			RETURNING id, NULL::jsonb AS before, ` + itemSnapshot + ` AS after
+		), ` + historyCTE(domain.AuditActionCreate, 9) + `
+		SELECT id FROM changed
+	`
+	ids := make([]int64, len(items))
+	err := r.db.WithTxWithRetry(ctx, r.retries, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for i, item := range items {
+			ids[i] = 0
+			row := stmt.QueryRowContext(ctx, auditArgs(ctx, item.Type, item.Amount, item.Currency, item.Date, item.Category, item.Description, item.ExternalID, item.AccountID)...)
+			if err := row.Scan(&ids[i]); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return writeError(err)
+	}
+	for i, item := range items {
+		item.ID = ids[i]
+	}
+	return nil
+}
+
+// GetExistingExternalIDs возвращает те из ids, что уже загружены в текущее пространство.
+func (r *ItemsPostgresRepository) GetExistingExternalIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	query := `SELECT external_id FROM items WHERE ` + tenantdb.InWorkspace + ` AND external_id = ANY($1)`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		existing[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return existing, nil
+}
@@ -7,40 +7,99 @@ import (
 	"fmt"
 	"sales-tracker/internal/domain"
 	customErr "sales-tracker/internal/domain/errors"
+	"slices"
 
-	"github.com/wb-go/wbf/dbpg"
+	"sales-tracker/internal/repository/tenantdb"
+
+	"github.com/lib/pq"
 	"github.com/wb-go/wbf/retry"
 )
 
+const itemColumns = `id, type, amount, currency, date, category, description, COALESCE(external_id, ''), account_id, to_account_id, version, created_at, updated_at, deleted_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItem(row rowScanner) (*domain.Item, error) {
+	item := &domain.Item{}
+	err := row.Scan(
+		&item.ID,
+		&item.Type,
+		&item.Amount,
+		&item.Currency,
+		&item.Date,
+		&item.Category,
+		&item.Description,
+		&item.ExternalID,
+		&item.AccountID,
+		&item.ToAccountID,
+		&item.Version,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+		&item.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
 type ItemsPostgresRepository struct {
-	db      *dbpg.DB
+	db      *tenantdb.DB
 	retries retry.Strategy
 }
 
-func NewPostgresRepository(db *dbpg.DB, retries retry.Strategy) *ItemsPostgresRepository {
+// accountConstraints — ограничения items на ссылки на счета. Их нарушение
+// означает ошибку во входных данных, а не сбой БД.
+var accountConstraints = map[string]string{
+	"items_account_fk":        "account not found",
+	"items_to_account_fk":     "to_account not found",
+	"items_transfer_accounts": "transfer requires two different accounts, other types only account_id",
+	"items_account_currency":  "item currency does not match account currency",
+}
+
+// writeError оборачивает ошибку записи операции: нарушения ограничений
+// счетов становятся ErrInvalidInput, остальное — ErrDatabase.
+func writeError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if msg, ok := accountConstraints[pqErr.Constraint]; ok {
+			return fmt.Errorf("%w: %s", customErr.ErrInvalidInput, msg)
+		}
+	}
+	return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+}
+
+func NewPostgresRepository(db *tenantdb.DB, retries retry.Strategy) *ItemsPostgresRepository {
 	return &ItemsPostgresRepository{
 		db:      db,
 		retries: retries,
 	}
 }
 
+// createItemQuery вставляет операцию в текущее пространство и запись о создании в истории.
+var createItemQuery = `
+	WITH changed AS (
+		INSERT INTO items (workspace_id, type, amount, currency, date, category, description, account_id, to_account_id)
+		VALUES (current_workspace_id(), $1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, version, NULL::jsonb AS before, ` + itemSnapshot + ` AS after
+	), ` + historyCTE(domain.AuditActionCreate, 9) + `
+	SELECT id, version FROM changed
+`
+
 func (r *ItemsPostgresRepository) CreateItem(ctx context.Context, item *domain.Item) (int64, error) {
 	var id int64
-	query := `
-		INSERT INTO items (type, amount, date, category, description)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
-	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, item.Type, item.Amount, item.Date, item.Category, item.Description)
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, createItemQuery, createItemArgs(ctx, item)...)
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		return 0, writeError(err)
 	}
-	err = row.Scan(&id)
+	err = row.Scan(&id, &item.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, fmt.Errorf("%w: no rows returned", customErr.ErrDatabase)
 		}
-		return 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		return 0, writeError(err)
 	}
 	return id, nil
 }
@@ -48,8 +107,9 @@ func (r *ItemsPostgresRepository) CreateItem(ctx context.Context, item *domain.I
 func (r *ItemsPostgresRepository) GetItems(ctx context.Context) ([]*domain.Item, error) {
 	var items []*domain.Item
 	query := `
-		SELECT id, type, amount, date, category, description, created_at, updated_at
+		SELECT ` + itemColumns + `
 		FROM items
+		WHERE ` + tenantdb.InWorkspace + ` AND deleted_at IS NULL
 		ORDER BY date DESC
 	`
 	rows, err := r.db.QueryWithRetry(ctx, r.retries, query)
@@ -58,17 +118,7 @@ func (r *ItemsPostgresRepository) GetItems(ctx context.Context) ([]*domain.Item,
 	}
 	defer rows.Close()
 	for rows.Next() {
-		item := &domain.Item{}
-		err := rows.Scan(
-			&item.ID,
-			&item.Type,
-			&item.Amount,
-			&item.Date,
-			&item.Category,
-			&item.Description,
-			&item.CreatedAt,
-			&item.UpdatedAt,
-		)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 		}
@@ -80,12 +130,15 @@ func (r *ItemsPostgresRepository) GetItems(ctx context.Context) ([]*domain.Item,
 	return items, nil
 }
 
-func (r *ItemsPostgresRepository) GetItemsWithPagination(ctx context.Context, offset, limit int) ([]*domain.Item, int64, error) {
+func (r *ItemsPostgresRepository) GetItemsWithPagination(ctx context.Context, filter *domain.ItemsFilter, offset, limit int) ([]*domain.Item, int64, error) {
 	var items []*domain.Item
 	var total int64
 
-	countQuery := `SELECT COUNT(*) FROM items`
-	row, err := r.db.QueryRowWithRetry(ctx, r.retries, countQuery)
+	conds, args := buildItemsFilter(filter)
+	where := whereClause(conds)
+
+	countQuery := `SELECT COUNT(*) FROM items ` + where
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, countQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
@@ -95,30 +148,21 @@ func (r *ItemsPostgresRepository) GetItemsWithPagination(ctx context.Context, of
 		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
 
-	query := `
-		SELECT id, type, amount, date, category, description, created_at, updated_at
+	query := fmt.Sprintf(`
+		SELECT `+itemColumns+`
 		FROM items
-		ORDER BY date DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, limit, offset)
+		%s
+		%s
+		LIMIT $%d OFFSET $%d
+	`, where, buildItemsOrder(filter), len(args)+1, len(args)+2)
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		item := &domain.Item{}
-		err := rows.Scan(
-			&item.ID,
-			&item.Type,
-			&item.Amount,
-			&item.Date,
-			&item.Category,
-			&item.Description,
-			&item.CreatedAt,
-			&item.UpdatedAt,
-		)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 		}
@@ -132,27 +176,111 @@ func (r *ItemsPostgresRepository) GetItemsWithPagination(ctx context.Context, of
 	return items, total, nil
 }
 
+// GetItemsByCursor возвращает страницу по ключу (date, id) без OFFSET.
+// Запрашивается limit+1 строка, чтобы понять, есть ли данные за границей страницы.
+func (r *ItemsPostgresRepository) GetItemsByCursor(ctx context.Context, filter *domain.ItemsFilter, cursor *domain.ItemsCursor, limit int, withTotal bool) (*domain.ItemsPage, error) {
+	page := &domain.ItemsPage{}
+	conds, args := buildItemsFilter(filter)
+
+	if withTotal {
+		var total int64
+		countQuery := `SELECT COUNT(*) FROM items ` + whereClause(conds)
+		row, err := r.db.QueryRowWithRetry(ctx, r.retries, countQuery, args...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		if err := row.Scan(&total); err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		page.Total = &total
+	}
+
+	desc := filter == nil || filter.SortOrder != domain.SortOrderAsc
+	backward := cursor != nil && cursor.Backward
+	// При движении назад выборка идёт в обратном порядке и затем разворачивается.
+	scanDesc := desc != backward
+
+	if cursor != nil {
+		op := ">"
+		if scanDesc {
+			op = "<"
+		}
+		args = append(args, cursor.Date, cursor.ID)
+		conds = append(conds, fmt.Sprintf("(date, id) %s ($%d, $%d)", op, len(args)-1, len(args)))
+	}
+
+	order := "ASC"
+	if scanDesc {
+		order = "DESC"
+	}
+	args = append(args, limit+1)
+	query := fmt.Sprintf(`
+		SELECT `+itemColumns+`
+		FROM items
+		%s
+		ORDER BY date %s, id %s
+		LIMIT $%d
+	`, whereClause(conds), order, order, len(args))
+
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	items := make([]*domain.Item, 0, limit+1)
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+	if backward {
+		slices.Reverse(items)
+	}
+	page.Items = items
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	first, last := items[0], items[len(items)-1]
+	if backward {
+		if hasMore {
+			page.PrevCursor = &domain.ItemsCursor{Date: first.Date, ID: first.ID, Backward: true}
+		}
+		page.NextCursor = &domain.ItemsCursor{Date: last.Date, ID: last.ID}
+	} else {
+		if cursor != nil {
+			page.PrevCursor = &domain.ItemsCursor{Date: first.Date, ID: first.ID, Backward: true}
+		}
+		if hasMore {
+			page.NextCursor = &domain.ItemsCursor{Date: last.Date, ID: last.ID}
+		}
+	}
+
+	return page, nil
+}
+
 func (r *ItemsPostgresRepository) GetItemByID(ctx context.Context, id int64) (*domain.Item, error) {
-	item := &domain.Item{}
 	query := `
-		SELECT id, type, amount, date, category, description, created_at, updated_at
+		SELECT ` + itemColumns + `
 		FROM items
-		WHERE id = $1
+		WHERE id = $1 AND ` + tenantdb.InWorkspace + ` AND deleted_at IS NULL
 	`
 	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, id)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
-	err = row.Scan(
-		&item.ID,
-		&item.Type,
-		&item.Amount,
-		&item.Date,
-		&item.Category,
-		&item.Description,
-		&item.CreatedAt,
-		&item.UpdatedAt,
-	)
+	item, err := scanItem(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, customErr.ErrItemNotFound
@@ -162,41 +290,99 @@ func (r *ItemsPostgresRepository) GetItemByID(ctx context.Context, id int64) (*d
 	return item, nil
 }
 
-func (r *ItemsPostgresRepository) UpdateItem(ctx context.Context, id int64, item *domain.Item) error {
-	query := `
+func createItemArgs(ctx context.Context, item *domain.Item) []any {
+	return auditArgs(ctx, item.Type, item.Amount, item.Currency, item.Date, item.Category, item.Description,
+		item.AccountID, item.ToAccountID)
+}
+
+func updateItemArgs(ctx context.Context, id int64, item *domain.Item, version int64) []any {
+	return auditArgs(ctx, item.Type, item.Amount, item.Currency, item.Date, item.Category, item.Description,
+		item.AccountID, item.ToAccountID, id, version)
+}
+
+// Изменяющие запросы блокируют строку в CTE old, чтобы снять снимок «до»,
+// и пишут историю тем же оператором, что и изменение.
+var updateItemQuery = `
+	WITH old AS (
+		SELECT id, ` + itemSnapshot + ` AS before
+		FROM items
+		WHERE id = $9 AND ` + tenantdb.InWorkspace + ` AND deleted_at IS NULL AND ($10::bigint = 0 OR version = $10)
+		FOR UPDATE
+	), changed AS (
 		UPDATE items
-		SET type = $1, amount = $2, date = $3, category = $4, description = $5, updated_at = now()
-		WHERE id = $6
-	`
-	res, err := r.db.ExecWithRetry(ctx, r.retries, query, item.Type, item.Amount, item.Date, item.Category, item.Description, id)
+		SET type = $1, amount = $2, currency = $3, date = $4, category = $5, description = $6,
+			account_id = $7, to_account_id = $8, version = version + 1, updated_at = now()
+		FROM old
+		WHERE items.id = old.id
+		RETURNING items.id, items.version, items.updated_at, old.before, ` + itemSnapshot + ` AS after
+	), ` + historyCTE(domain.AuditActionUpdate, 11) + `
+	SELECT version, updated_at FROM changed
+`
+
+// deleteItemQuery перемещает операцию в корзину; окончательно её удаляет PurgeDeleted.
+var deleteItemQuery = `
+	WITH old AS (
+		SELECT id, ` + itemSnapshot + ` AS before
+		FROM items
+		WHERE id = $1 AND ` + tenantdb.InWorkspace + ` AND deleted_at IS NULL AND ($2::bigint = 0 OR version = $2)
+		FOR UPDATE
+	), changed AS (
+		UPDATE items
+		SET deleted_at = now(), version = version + 1
+		FROM old
+		WHERE items.id = old.id
+		RETURNING items.id, old.before, ` + itemSnapshot + ` AS after
+	), ` + historyCTE(domain.AuditActionDelete, 3) + `
+	SELECT id FROM changed
+`
+
+// UpdateItem перезаписывает операцию и увеличивает её версию. Если version > 0,
+// запись выполняется только при совпадении версии — проверка и обновление
+// происходят в одном UPDATE. Новая версия записывается в item.Version.
+func (r *ItemsPostgresRepository) UpdateItem(ctx context.Context, id int64, item *domain.Item, version int64) error {
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, updateItemQuery, updateItemArgs(ctx, id, item, version)...)
 	if err != nil {
-		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		return writeError(err)
 	}
-	rows, err := res.RowsAffected()
+	if err := row.Scan(&item.Version, &item.UpdatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.missingOrStale(ctx, id)
+		}
+		return writeError(err)
+	}
+	return nil
+}
+
+// DeleteItem перемещает операцию в корзину; при version > 0 — только если версия совпадает.
+func (r *ItemsPostgresRepository) DeleteItem(ctx context.Context, id int64, version int64) error {
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, deleteItemQuery, auditArgs(ctx, id, version)...)
 	if err != nil {
 		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
-	if rows == 0 {
-		return customErr.ErrItemNotFound
+	if err := row.Scan(new(int64)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.missingOrStale(ctx, id)
+		}
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
 	return nil
 }
 
-func (r *ItemsPostgresRepository) DeleteItem(ctx context.Context, id int64) error {
-	query := `
-		DELETE FROM items
-		WHERE id = $1
-	`
-	res, err := r.db.ExecWithRetry(ctx, r.retries, query, id)
+const itemExistsQuery = `SELECT EXISTS (SELECT 1 FROM items WHERE id = $1 AND ` + tenantdb.InWorkspace + ` AND deleted_at IS NULL)`
+
+// missingOrStale выясняет, почему условная запись не затронула строк:
+// операции нет вовсе или её версия уже изменилась.
+func (r *ItemsPostgresRepository) missingOrStale(ctx context.Context, id int64) error {
+	var exists bool
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, itemExistsQuery, id)
 	if err != nil {
 		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
-	rows, err := res.RowsAffected()
-	if err != nil {
+	if err := row.Scan(&exists); err != nil {
 		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
 	}
-	if rows == 0 {
-		return customErr.ErrItemNotFound
+	if exists {
+		return customErr.ErrVersionMismatch
 	}
-	return nil
+	return customErr.ErrItemNotFound
 }
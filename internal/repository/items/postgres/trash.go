@@ -0,0 +1,120 @@
+package items_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+	"time"
+)
+
+// purgeBatchSize ограничивает число строк, удаляемых одним запросом очистки,
+// чтобы не держать долгие блокировки.
+const purgeBatchSize = 1000
+
+// GetTrash возвращает операции из корзины, начиная с удалённых последними.
+func (r *ItemsPostgresRepository) GetTrash(ctx context.Context, offset, limit int) ([]*domain.Item, int64, error) {
+	var total int64
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, `SELECT COUNT(*) FROM items WHERE `+tenantdb.InWorkspace+` AND deleted_at IS NOT NULL`)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	query := `
+		SELECT ` + itemColumns + `
+		FROM items
+		WHERE ` + tenantdb.InWorkspace + ` AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	var items []*domain.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return items, total, nil
+}
+
+// RestoreItem возвращает операцию из корзины и увеличивает её версию.
+// Если операции нет в корзине, возвращается ErrItemNotFound.
+func (r *ItemsPostgresRepository) RestoreItem(ctx context.Context, id int64) (*domain.Item, error) {
+	query := `
+		WITH old AS (
+			SELECT id, ` + itemSnapshot + ` AS before
+			FROM items
+			WHERE id = $1 AND ` + tenantdb.InWorkspace + ` AND deleted_at IS NOT NULL
+			FOR UPDATE
+		), changed AS (
+			UPDATE items
+			SET deleted_at = NULL, version = version + 1
+			FROM old
+			WHERE items.id = old.id
+			RETURNING items.*, old.before, ` + itemSnapshot + ` AS after
+		), ` + historyCTE(domain.AuditActionRestore, 2) + `
+		SELECT ` + itemColumns + ` FROM changed
+	`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, auditArgs(ctx, id)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	item, err := scanItem(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrItemNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return item, nil
+}
+
+// PurgeDeleted окончательно удаляет операции, попавшие в корзину раньше before,
+// и возвращает их число. История операций при этом сохраняется. Очистка
+// обслуживает все пространства, которые открывает контекст, поэтому условия
+// по workspace_id в запросе нет.
+func (r *ItemsPostgresRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		WITH changed AS (
+			DELETE FROM items
+			WHERE id IN (
+				SELECT id FROM items
+				WHERE deleted_at < $1
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, ` + itemSnapshot + ` AS before, NULL::jsonb AS after
+		), ` + historyCTE(domain.AuditActionPurge, 3) + `
+		SELECT COUNT(*) FROM changed
+	`
+	var purged int64
+	for {
+		var n int64
+		row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, auditArgs(ctx, before, purgeBatchSize)...)
+		if err == nil {
+			err = row.Scan(&n)
+		}
+		if err != nil {
+			return purged, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		purged += n
+		if n < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package ledger_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	analytics_postgres "sales-tracker/internal/repository/analytics/postgres"
+	"sales-tracker/internal/repository/tenantdb"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/retry"
+)
+
+const ledgerAccountColumns = `id, code, name, type, COALESCE(system, ''), account_id, COALESCE(category, ''), created_at`
+
+// postingsInWorkspace — строки проводок текущего пространства; подзапрос
+// нужен, чтобы условие tenantdb.InWorkspace не стало неоднозначным в соединении.
+const postingsInWorkspace = `(SELECT * FROM journal_postings WHERE ` + tenantdb.InWorkspace + `)`
+
+type LedgerPostgresRepository struct {
+	db      *tenantdb.DB
+	retries retry.Strategy
+}
+
+func NewLedgerPostgresRepository(db *tenantdb.DB, retries retry.Strategy) *LedgerPostgresRepository {
+	return &LedgerPostgresRepository{
+		db:      db,
+		retries: retries,
+	}
+}
+
+func (r *LedgerPostgresRepository) LedgerEnabled(ctx context.Context) (bool, error) {
+	var enabled bool
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, `SELECT ledger_enabled(current_workspace_id())`)
+	if err == nil {
+		err = row.Scan(&enabled)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return enabled, nil
+}
+
+// EnableLedger включает двойную запись в текущем пространстве и проводит
+// все его счета и операции; повторный вызов заново строит проводки.
+func (r *LedgerPostgresRepository) EnableLedger(ctx context.Context) error {
+	if _, err := r.db.ExecWithRetry(ctx, r.retries, `SELECT ledger_enable(current_workspace_id())`); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *LedgerPostgresRepository) ListLedgerAccounts(ctx context.Context) ([]*domain.LedgerAccount, error) {
+	query := `SELECT ` + ledgerAccountColumns + ` FROM ledger_accounts WHERE ` + tenantdb.InWorkspace + ` ORDER BY code`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	accounts := []*domain.LedgerAccount{}
+	for rows.Next() {
+		account, err := scanLedgerAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return accounts, nil
+}
+
+func (r *LedgerPostgresRepository) GetLedgerAccount(ctx context.Context, id int64) (*domain.LedgerAccount, error) {
+	query := `SELECT ` + ledgerAccountColumns + ` FROM ledger_accounts WHERE ` + tenantdb.InWorkspace + ` AND id = $1`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	account, err := scanLedgerAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErr.ErrLedgerAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return account, nil
+}
+
+// CreateLedgerAccount добавляет счёт в план счетов. Счёт категории сразу
+// забирает проводки операций этой категории — это делает триггер БД.
+func (r *LedgerPostgresRepository) CreateLedgerAccount(ctx context.Context, account *domain.LedgerAccount) error {
+	query := `
+		INSERT INTO ledger_accounts (workspace_id, code, name, type, category)
+		VALUES (current_workspace_id(), $1, $2, $3, NULLIF($4, ''))
+		RETURNING id, created_at
+	`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, account.Code, account.Name, account.Type, account.Category)
+	if err == nil {
+		err = row.Scan(&account.ID, &account.CreatedAt)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return fmt.Errorf("%w: %s", customErr.ErrLedgerAccountExists, pqErr.Detail)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+// DeleteLedgerAccount удаляет счёт без проводок.
+func (r *LedgerPostgresRepository) DeleteLedgerAccount(ctx context.Context, id int64) error {
+	query := `DELETE FROM ledger_accounts WHERE ` + tenantdb.InWorkspace + ` AND id = $1`
+	res, err := r.db.ExecWithRetry(ctx, r.retries, query, id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return fmt.Errorf("%w: ledger account %d", customErr.ErrLedgerAccountInUse, id)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if n == 0 {
+		return customErr.ErrLedgerAccountNotFound
+	}
+	return nil
+}
+
+// CreateJournalEntry записывает ручную проводку. Баланс строк ещё раз
+// проверяет отложенный триггер journal_entry_balanced при фиксации.
+func (r *LedgerPostgresRepository) CreateJournalEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	err := r.db.WithTxWithRetry(ctx, r.retries, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO journal_entries (workspace_id, date, currency, description, source)
+			VALUES (current_workspace_id(), $1, $2, $3, 'manual')
+			RETURNING id, source, created_at
+		`, entry.Date, entry.Currency, entry.Description).Scan(&entry.ID, &entry.Source, &entry.CreatedAt)
+		if err != nil {
+			return err
+		}
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO journal_postings (workspace_id, entry_id, ledger_account_id, debit, credit)
+			VALUES (current_workspace_id(), $1, $2, $3, $4)
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, p := range entry.Postings {
+			if _, err := stmt.ExecContext(ctx, entry.ID, p.LedgerAccountID, p.Debit, p.Credit); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "23503":
+			return fmt.Errorf("%w: ledger account not found", customErr.ErrInvalidInput)
+		case pqErr.Code.Class() == "23":
+			return fmt.Errorf("%w: %s", customErr.ErrInvalidInput, pqErr.Message)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+// ListJournalEntries возвращает страницу журнала в порядке (date, id)
+// вместе со строками проводок и общее число проводок периода.
+func (r *LedgerPostgresRepository) ListJournalEntries(ctx context.Context, filter *domain.JournalFilter) ([]*domain.JournalEntry, int64, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer tx.Rollback()
+
+	where := `WHERE ` + tenantdb.InWorkspace + `
+		AND ($1::timestamptz IS NULL OR date >= $1)
+		AND ($2::timestamptz IS NULL OR date <= $2)`
+	var total int64
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM journal_entries `+where, filter.From, filter.To).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, date, currency, description, source, item_id, account_id, created_at
+		FROM journal_entries `+where+`
+		ORDER BY date, id
+		OFFSET $3 LIMIT $4
+	`, filter.From, filter.To, filter.Offset, filter.Limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	entries := []*domain.JournalEntry{}
+	byID := make(map[int64]*domain.JournalEntry)
+	ids := []int64{}
+	for rows.Next() {
+		e := &domain.JournalEntry{Postings: []*domain.Posting{}}
+		err := rows.Scan(&e.ID, &e.Date, &e.Currency, &e.Description, &e.Source, &e.ItemID, &e.AccountID, &e.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		entries = append(entries, e)
+		byID[e.ID] = e
+		ids = append(ids, e.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT entry_id, ledger_account_id, debit, credit
+		FROM journal_postings
+		WHERE `+tenantdb.InWorkspace+` AND entry_id = ANY($1)
+		ORDER BY entry_id, id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entryID int64
+		p := &domain.Posting{}
+		if err := rows.Scan(&entryID, &p.LedgerAccountID, &p.Debit, &p.Credit); err != nil {
+			return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		byID[entryID].Postings = append(byID[entryID].Postings, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return entries, total, nil
+}
+
+// DeleteJournalEntry удаляет ручную проводку; проводки операций и начальных
+// остатков меняются только вместе с их источником.
+func (r *LedgerPostgresRepository) DeleteJournalEntry(ctx context.Context, id int64) error {
+	query := `DELETE FROM journal_entries WHERE ` + tenantdb.InWorkspace + ` AND id = $1 AND source = 'manual'`
+	res, err := r.db.ExecWithRetry(ctx, r.retries, query, id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if n == 0 {
+		return customErr.ErrJournalEntryNotFound
+	}
+	return nil
+}
+
+// GetTrialBalance возвращает обороты счетов по валютам проводок на дату to
+// (nil — на текущий момент) в порядке валют и кодов счетов.
+func (r *LedgerPostgresRepository) GetTrialBalance(ctx context.Context, to *time.Time) ([]*domain.TrialBalanceRow, error) {
+	query := `
+		SELECT la.id, la.code, la.name, la.type, COALESCE(la.system, ''), la.account_id, COALESCE(la.category, ''), la.created_at,
+			e.currency, SUM(p.debit), SUM(p.credit)
+		FROM ` + postingsInWorkspace + ` p
+		JOIN journal_entries e ON e.id = p.entry_id
+		JOIN ledger_accounts la ON la.id = p.ledger_account_id
+		WHERE $1::timestamptz IS NULL OR e.date <= $1
+		GROUP BY la.id, e.currency
+		ORDER BY e.currency, la.code
+	`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	result := []*domain.TrialBalanceRow{}
+	for rows.Next() {
+		row := &domain.TrialBalanceRow{Account: &domain.LedgerAccount{}}
+		a := row.Account
+		err := rows.Scan(&a.ID, &a.Code, &a.Name, &a.Type, &a.System, &a.AccountID, &a.Category, &a.CreatedAt,
+			&row.Currency, &row.Debit, &row.Credit)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return result, nil
+}
+
+// convertedPosting — сальдо строки проводки (кредит минус дебет),
+// пересчитанное в валюту $3 по курсу на дату проводки и округлённое до
+// копейки так же, как сумма операции в аналитике.
+var convertedPosting = analytics_postgres.ConvertTo("(p.credit - p.debit)", "e.currency", "e.date")
+
+// GetProfitAndLoss возвращает обороты счетов доходов и расходов за период
+// [From, To] в валюте отчёта: для доходов — кредит минус дебет, для
+// расходов — дебет минус кредит. Сверка заполняется суммами ручных проводок
+// и операций за тот же период; всё читается из одного снимка, поэтому
+// запись, сделанная во время построения отчёта, не даёт ложного расхождения.
+func (r *LedgerPostgresRepository) GetProfitAndLoss(ctx context.Context, params *domain.ProfitAndLossParams) ([]*domain.LedgerLine, *domain.LedgerReconciliation, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer tx.Rollback()
+
+	from := `
+		FROM ` + postingsInWorkspace + ` p
+		JOIN journal_entries e ON e.id = p.entry_id
+		JOIN ledger_accounts la ON la.id = p.ledger_account_id
+		WHERE la.type IN ('income', 'expense') AND e.date BETWEEN $1 AND $2`
+
+	var missingPostings, missingItems int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) `+from+`
+				AND e.currency <> $3
+				AND (exchange_rate_on(e.currency, e.date) IS NULL OR exchange_rate_on($3, e.date) IS NULL)),
+			(SELECT COUNT(*) FROM (`+analytics_postgres.ConvertedItems+`) i WHERE i.amount IS NULL)
+	`, params.From, params.To, params.Currency).Scan(&missingPostings, &missingItems)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if missingPostings > 0 {
+		return nil, nil, fmt.Errorf("%w: %d postings cannot be converted to %s", customErr.ErrRateNotFound, missingPostings, params.Currency)
+	}
+	if missingItems > 0 {
+		return nil, nil, fmt.Errorf("%w: %d items cannot be converted to %s", customErr.ErrRateNotFound, missingItems, params.Currency)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH converted AS (
+			SELECT la.id, e.source, `+convertedPosting+` AS amount `+from+`
+		)
+		SELECT la.id, la.code, la.name, la.type, COALESCE(la.system, ''), la.account_id, COALESCE(la.category, ''), la.created_at,
+			SUM(c.amount), COALESCE(SUM(c.amount) FILTER (WHERE c.source = 'manual'), 0)
+		FROM converted c
+		JOIN ledger_accounts la ON la.id = c.id
+		GROUP BY la.id
+		ORDER BY la.code
+	`, params.From, params.To, params.Currency)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	lines := []*domain.LedgerLine{}
+	rec := &domain.LedgerReconciliation{}
+	for rows.Next() {
+		line := &domain.LedgerLine{Account: &domain.LedgerAccount{}}
+		a := line.Account
+		var manual domain.Money
+		err := rows.Scan(&a.ID, &a.Code, &a.Name, &a.Type, &a.System, &a.AccountID, &a.Category, &a.CreatedAt,
+			&line.Amount, &manual)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		if a.DebitNormal() {
+			line.Amount, manual = -line.Amount, -manual
+		}
+		if a.Type == domain.LedgerIncome {
+			rec.ManualIncome += manual
+		} else {
+			rec.ManualExpense += manual
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE type = '`+domain.ItemTypeIncome+`'), 0),
+			COALESCE(SUM(amount) FILTER (WHERE type = '`+domain.ItemTypeExpense+`'), 0)
+		FROM (`+analytics_postgres.ConvertedItems+`) i
+	`, params.From, params.To, params.Currency).Scan(&rec.AnalyticsIncome, &rec.AnalyticsExpense)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return lines, rec, nil
+}
+
+func scanLedgerAccount(row interface{ Scan(dest ...any) error }) (*domain.LedgerAccount, error) {
+	a := &domain.LedgerAccount{}
+	err := row.Scan(&a.ID, &a.Code, &a.Name, &a.Type, &a.System, &a.AccountID, &a.Category, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
@@ -0,0 +1,89 @@
+package rates_postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+	"time"
+
+	"github.com/wb-go/wbf/retry"
+)
+
+type RatesPostgresRepository struct {
+	db      *tenantdb.DB
+	retries retry.Strategy
+}
+
+func NewRatesPostgresRepository(db *tenantdb.DB, retries retry.Strategy) *RatesPostgresRepository {
+	return &RatesPostgresRepository{
+		db:      db,
+		retries: retries,
+	}
+}
+
+// UpsertRates записывает курсы текущего пространства; в контексте system
+// (без пространства) — общие курсы.
+func (r *RatesPostgresRepository) UpsertRates(ctx context.Context, rates []*domain.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (workspace_id, currency, date, rate)
+		VALUES (current_workspace_id(), $1, $2, $3)
+		ON CONFLICT (workspace_id, currency, date) DO UPDATE
+		SET rate = EXCLUDED.rate, updated_at = now()
+	`
+	err := r.db.WithTxWithRetry(ctx, r.retries, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, rate := range rates {
+			if _, err := stmt.ExecContext(ctx, rate.Currency, rate.Date.Format(time.DateOnly), rate.Rate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+// GetRates возвращает курсы, действующие в текущем пространстве: его
+// собственные и общие; на одну дату курс пространства заменяет общий.
+func (r *RatesPostgresRepository) GetRates(ctx context.Context, currency string, from, to time.Time) ([]*domain.ExchangeRate, error) {
+	var rates []*domain.ExchangeRate
+	query := `
+		SELECT DISTINCT ON (currency, date) currency, date, rate, created_at, updated_at
+		FROM exchange_rates
+		WHERE (workspace_id IS NULL OR workspace_id = current_workspace_id())
+			AND ($1::text = '' OR currency = $1) AND date BETWEEN $2 AND $3
+		ORDER BY currency, date DESC, workspace_id NULLS LAST
+	`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, currency, from.Format(time.DateOnly), to.Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		rate := &domain.ExchangeRate{}
+		err := rows.Scan(
+			&rate.Currency,
+			&rate.Date,
+			&rate.Rate,
+			&rate.CreatedAt,
+			&rate.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return rates, nil
+}
@@ -0,0 +1,254 @@
+package reports_postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"sales-tracker/internal/repository/tenantdb"
+	"time"
+
+	"github.com/wb-go/wbf/retry"
+)
+
+const jobColumns = `id, workspace_id, kind, params, status, progress, error, artifact_path, artifact_name, content_type,
+	created_at, started_at, finished_at, expires_at`
+
+type ReportsPostgresRepository struct {
+	db      *tenantdb.DB
+	retries retry.Strategy
+}
+
+func NewReportsPostgresRepository(db *tenantdb.DB, retries retry.Strategy) *ReportsPostgresRepository {
+	return &ReportsPostgresRepository{
+		db:      db,
+		retries: retries,
+	}
+}
+
+// paramsRecord — параметры задачи в колонке params (JSONB).
+type paramsRecord struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Currency    string    `json:"currency"`
+	Compare     string    `json:"compare,omitempty"`
+	Percentiles []float64 `json:"percentiles,omitempty"`
+	Histogram   string    `json:"histogram,omitempty"`
+	Bins        int       `json:"bins,omitempty"`
+}
+
+func scanJob(row interface{ Scan(dest ...any) error }) (*domain.ReportJob, error) {
+	job := &domain.ReportJob{}
+	var params []byte
+	var startedAt, finishedAt, expiresAt sql.NullTime
+	err := row.Scan(
+		&job.ID,
+		&job.WorkspaceID,
+		&job.Kind,
+		&params,
+		&job.Status,
+		&job.Progress,
+		&job.Error,
+		&job.ArtifactPath,
+		&job.ArtifactName,
+		&job.ContentType,
+		&job.CreatedAt,
+		&startedAt,
+		&finishedAt,
+		&expiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var record paramsRecord
+	if err := json.Unmarshal(params, &record); err != nil {
+		return nil, fmt.Errorf("decode report params: %w", err)
+	}
+	job.Params = &domain.AnalyticsParams{
+		From:        record.From,
+		To:          record.To,
+		Currency:    record.Currency,
+		Compare:     record.Compare,
+		Percentiles: record.Percentiles,
+		Histogram:   record.Histogram,
+		Bins:        record.Bins,
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	if expiresAt.Valid {
+		job.ExpiresAt = &expiresAt.Time
+	}
+	return job, nil
+}
+
+func (r *ReportsPostgresRepository) CreateJob(ctx context.Context, job *domain.ReportJob) error {
+	params, err := json.Marshal(paramsRecord{
+		From:        job.Params.From,
+		To:          job.Params.To,
+		Currency:    job.Params.Currency,
+		Compare:     job.Params.Compare,
+		Percentiles: job.Params.Percentiles,
+		Histogram:   job.Params.Histogram,
+		Bins:        job.Params.Bins,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	query := `
+		INSERT INTO report_jobs (id, workspace_id, kind, params, status)
+		VALUES ($1, current_workspace_id(), $2, $3, $4)
+		RETURNING workspace_id, created_at
+	`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, job.ID, job.Kind, params, job.Status)
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	if err := row.Scan(&job.WorkspaceID, &job.CreatedAt); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *ReportsPostgresRepository) GetJob(ctx context.Context, id string) (*domain.ReportJob, error) {
+	query := `SELECT ` + jobColumns + ` FROM report_jobs WHERE ` + tenantdb.InWorkspace + ` AND id = $1`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	job, err := scanJob(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrReportNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return job, nil
+}
+
+// ClaimNext атомарно забирает самую старую задачу из очереди.
+// SKIP LOCKED позволяет нескольким воркерам (и экземплярам сервиса)
+// разбирать очередь без двойной обработки. Возвращает nil, если очередь пуста.
+// Очередь общая для всех пространств: этот и следующие методы воркера
+// выполняются в контексте, открывающем все пространства или пространство задачи.
+func (r *ReportsPostgresRepository) ClaimNext(ctx context.Context) (*domain.ReportJob, error) {
+	query := `
+		UPDATE report_jobs
+		SET status = 'running', progress = 0, started_at = now()
+		WHERE id = (
+			SELECT id FROM report_jobs
+			WHERE status = 'queued'
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	job, err := scanJob(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return job, nil
+}
+
+func (r *ReportsPostgresRepository) UpdateProgress(ctx context.Context, id string, progress int) error {
+	query := `UPDATE report_jobs SET progress = $2 WHERE id = $1 AND status = 'running'`
+	if _, err := r.db.ExecWithRetry(ctx, r.retries, query, id, progress); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *ReportsPostgresRepository) CompleteJob(ctx context.Context, job *domain.ReportJob) error {
+	query := `
+		UPDATE report_jobs
+		SET status = 'done', progress = 100, error = '',
+			artifact_path = $2, artifact_name = $3, content_type = $4,
+			finished_at = $5, expires_at = $6
+		WHERE id = $1
+	`
+	_, err := r.db.ExecWithRetry(ctx, r.retries, query,
+		job.ID, job.ArtifactPath, job.ArtifactName, job.ContentType, job.FinishedAt, job.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *ReportsPostgresRepository) FailJob(ctx context.Context, id string, reason string) error {
+	query := `UPDATE report_jobs SET status = 'failed', error = $2, finished_at = now() WHERE id = $1`
+	if _, err := r.db.ExecWithRetry(ctx, r.retries, query, id, reason); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+// RequeueJob возвращает прерванную задачу в очередь (например, при остановке сервиса).
+func (r *ReportsPostgresRepository) RequeueJob(ctx context.Context, id string) error {
+	query := `UPDATE report_jobs SET status = 'queued', progress = 0, started_at = NULL WHERE id = $1 AND status = 'running'`
+	if _, err := r.db.ExecWithRetry(ctx, r.retries, query, id); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+// FailStaleJobs помечает ошибочными задачи, которые выполняются с момента
+// раньше before: их воркер завершился аварийно (kill, OOM) и не вернул
+// задачу в очередь. В очередь такие задачи не возвращаются, чтобы отчёт,
+// роняющий сервис, не перезапускался бесконечно.
+func (r *ReportsPostgresRepository) FailStaleJobs(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		UPDATE report_jobs
+		SET status = 'failed', error = 'report job timed out', finished_at = now()
+		WHERE status = 'running' AND started_at < $1
+	`
+	res, err := r.db.ExecWithRetry(ctx, r.retries, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return n, nil
+}
+
+// ExpireJobs помечает просроченные отчёты и возвращает их, чтобы удалить файлы.
+func (r *ReportsPostgresRepository) ExpireJobs(ctx context.Context, now time.Time) ([]*domain.ReportJob, error) {
+	query := `
+		UPDATE report_jobs
+		SET status = 'expired'
+		WHERE status = 'done' AND expires_at <= $1
+		RETURNING ` + jobColumns
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	var jobs []*domain.ReportJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		jobs = append(jobs, job)
+	}
+	// Close фиксирует UPDATE: без успешного коммита файлы удалять нельзя,
+	// иначе задачи останутся в статусе done без артефактов.
+	if err := rows.Close(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,215 @@
+// Package tenantdb выполняет запросы в рамках рабочего пространства из
+// контекста. Каждый запрос идёт в транзакции, в начале которой задаётся
+// app.workspace_id: по нему SQL-функция current_workspace_id() подставляет
+// пространство в условия и вставки, а политики RLS отсекают чужие строки.
+package tenantdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/wb-go/wbf/retry"
+)
+
+// InWorkspace — условие на строки текущего пространства. Запросы указывают
+// его явно, не полагаясь только на RLS.
+const InWorkspace = `workspace_id = current_workspace_id()`
+
+// allWorkspaces — значение app.workspace_id, открывающее все пространства.
+const allWorkspaces = "*"
+
+var ErrNoWorkspace = errors.New("workspace is not set in context")
+
+type DB struct {
+	db *dbpg.DB
+}
+
+func New(db *dbpg.DB) *DB {
+	return &DB{db: db}
+}
+
+// permanent сообщает, что повтор не поможет: нарушение ограничений
+// целостности (класс 23) воспроизведётся на тех же данных.
+func permanent(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Class() == "23"
+}
+
+// withRetry повторяет fn по стратегии, кроме постоянных ошибок.
+func withRetry(strategy retry.Strategy, fn func() error) error {
+	var final error
+	err := retry.Do(func() error {
+		err := fn()
+		if permanent(err) {
+			final = err
+			return nil
+		}
+		return err
+	}, strategy)
+	if final != nil {
+		return final
+	}
+	return err
+}
+
+// scope возвращает значение app.workspace_id для контекста.
+func scope(ctx context.Context) (string, error) {
+	if id, ok := domain.WorkspaceFrom(ctx); ok {
+		return strconv.FormatInt(id, 10), nil
+	}
+	if domain.IsAllWorkspaces(ctx) {
+		return allWorkspaces, nil
+	}
+	return "", ErrNoWorkspace
+}
+
+// BeginTx открывает транзакцию на мастере с пространством из контекста.
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	workspace, err := scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.workspace_id', $1, true)`, workspace); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (d *DB) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := d.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *DB) WithTxWithRetry(ctx context.Context, strategy retry.Strategy, fn func(*sql.Tx) error) error {
+	if _, err := scope(ctx); err != nil {
+		return err
+	}
+	var final error
+	err := retry.DoContext(ctx, strategy, func() error {
+		err := d.WithTx(ctx, fn)
+		if permanent(err) {
+			final = err
+			return nil
+		}
+		return err
+	})
+	if final != nil {
+		return final
+	}
+	return err
+}
+
+func (d *DB) ExecWithRetry(ctx context.Context, strategy retry.Strategy, query string, args ...any) (sql.Result, error) {
+	var res sql.Result
+	err := d.WithTxWithRetry(ctx, strategy, func(tx *sql.Tx) error {
+		r, err := tx.ExecContext(ctx, query, args...)
+		res = r
+		return err
+	})
+	return res, err
+}
+
+// Rows — результат запроса, держащий транзакцию открытой до Close.
+type Rows struct {
+	*sql.Rows
+	tx *sql.Tx
+}
+
+// Close закрывает выборку и завершает транзакцию: фиксирует её, если
+// чтение прошло без ошибок, иначе откатывает.
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if err == nil {
+		err = r.Rows.Err()
+	}
+	if err != nil {
+		_ = r.tx.Rollback()
+		return err
+	}
+	return r.tx.Commit()
+}
+
+func (d *DB) QueryWithRetry(ctx context.Context, strategy retry.Strategy, query string, args ...any) (*Rows, error) {
+	if _, err := scope(ctx); err != nil {
+		return nil, err
+	}
+	var rows *Rows
+	err := withRetry(strategy, func() error {
+		tx, err := d.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		r, err := tx.QueryContext(ctx, query, args...)
+		if err == nil {
+			err = r.Err()
+			if err != nil {
+				_ = r.Close()
+			}
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		rows = &Rows{Rows: r, tx: tx}
+		return nil
+	})
+	return rows, err
+}
+
+// Row — результат запроса одной строки. Транзакция завершается в Scan;
+// sql.ErrNoRows не мешает зафиксировать изменения запроса.
+type Row struct {
+	row *sql.Row
+	tx  *sql.Tx
+}
+
+func (r *Row) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		_ = r.tx.Rollback()
+		return err
+	}
+	if commitErr := r.tx.Commit(); commitErr != nil {
+		return fmt.Errorf("commit: %w", commitErr)
+	}
+	return err
+}
+
+func (d *DB) QueryRowWithRetry(ctx context.Context, strategy retry.Strategy, query string, args ...any) (*Row, error) {
+	if _, err := scope(ctx); err != nil {
+		return nil, err
+	}
+	var row *Row
+	err := withRetry(strategy, func() error {
+		tx, err := d.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		r := tx.QueryRowContext(ctx, query, args...)
+		if err := r.Err(); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		row = &Row{row: r, tx: tx}
+		return nil
+	})
+	return row, err
+}
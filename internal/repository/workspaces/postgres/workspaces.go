@@ -0,0 +1,96 @@
+package workspaces_postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+
+	"github.com/lib/pq"
+	"github.com/wb-go/wbf/dbpg"
+	"github.com/wb-go/wbf/retry"
+)
+
+const workspaceColumns = `id, slug, name, created_at`
+
+// WorkspacesPostgresRepository работает со справочником пространств. Сама
+// таблица workspaces не разделяется по пространствам, поэтому запросы идут
+// напрямую, без tenantdb.
+type WorkspacesPostgresRepository struct {
+	db      *dbpg.DB
+	retries retry.Strategy
+}
+
+func NewWorkspacesPostgresRepository(db *dbpg.DB, retries retry.Strategy) *WorkspacesPostgresRepository {
+	return &WorkspacesPostgresRepository{
+		db:      db,
+		retries: retries,
+	}
+}
+
+func (r *WorkspacesPostgresRepository) CreateWorkspace(ctx context.Context, ws *domain.Workspace) error {
+	query := `
+		INSERT INTO workspaces (slug, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, ws.Slug, ws.Name)
+	if err == nil {
+		err = row.Scan(&ws.ID, &ws.CreatedAt)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return fmt.Errorf("%w: workspace %q already exists", customErr.ErrWorkspaceExists, ws.Slug)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *WorkspacesPostgresRepository) GetWorkspaceBySlug(ctx context.Context, slug string) (*domain.Workspace, error) {
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces WHERE slug = $1`
+	row, err := r.db.QueryRowWithRetry(ctx, r.retries, query, slug)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	ws, err := scanWorkspace(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, customErr.ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return ws, nil
+}
+
+func (r *WorkspacesPostgresRepository) ListWorkspaces(ctx context.Context) ([]*domain.Workspace, error) {
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces ORDER BY id`
+	rows, err := r.db.QueryWithRetry(ctx, r.retries, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	defer rows.Close()
+	workspaces := []*domain.Workspace{}
+	for rows.Next() {
+		ws, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+		}
+		workspaces = append(workspaces, ws)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrDatabase, err)
+	}
+	return workspaces, nil
+}
+
+func scanWorkspace(row interface{ Scan(dest ...any) error }) (*domain.Workspace, error) {
+	ws := &domain.Workspace{}
+	if err := row.Scan(&ws.ID, &ws.Slug, &ws.Name, &ws.CreatedAt); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
@@ -0,0 +1,160 @@
+package accounts_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type Service struct {
+	repo     accountsRepository
+	logger   *zlog.Zerolog
+	validate *validator.Validate
+}
+
+func NewService(repo accountsRepository, logger *zlog.Zerolog) *Service {
+	return &Service{
+		repo:     repo,
+		logger:   logger,
+		validate: validator.New(),
+	}
+}
+
+func (s *Service) CreateAccount(ctx context.Context, account *domain.Account) error {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return err
+	}
+	account.Name = strings.TrimSpace(account.Name)
+	account.Currency = strings.ToUpper(strings.TrimSpace(account.Currency))
+	if account.Currency == "" {
+		account.Currency = domain.BaseCurrency
+	}
+	if err := s.validate.Struct(account); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+
+	s.logger.Info().Str("name", account.Name).Msg("Creating account")
+	if err := s.repo.CreateAccount(ctx, account); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to create account")
+		return accountErr(err)
+	}
+	s.logger.Info().Int64("id", account.ID).Msg("Account created")
+	return nil
+}
+
+// ListAccounts возвращает счета пространства с текущими остатками.
+func (s *Service) ListAccounts(ctx context.Context) ([]*domain.AccountBalance, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsRead); err != nil {
+		return nil, err
+	}
+	balances, err := s.repo.ListAccountBalances(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to list accounts")
+		return nil, accountErr(err)
+	}
+	return balances, nil
+}
+
+func (s *Service) GetAccount(ctx context.Context, id int64) (*domain.Account, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsRead); err != nil {
+		return nil, err
+	}
+	if id <= 0 {
+		return nil, customErr.ErrInvalidInput
+	}
+	account, err := s.repo.GetAccount(ctx, id)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to get account")
+		return nil, accountErr(err)
+	}
+	return account, nil
+}
+
+func (s *Service) UpdateAccount(ctx context.Context, id int64, patch *domain.AccountPatch) (*domain.Account, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return nil, err
+	}
+	if id <= 0 {
+		return nil, customErr.ErrInvalidInput
+	}
+	if patch == nil || *patch == (domain.AccountPatch{}) {
+		return nil, fmt.Errorf("%w: nothing to update", customErr.ErrMissingParameter)
+	}
+	if patch.Name != nil {
+		name := strings.TrimSpace(*patch.Name)
+		patch.Name = &name
+		if err := s.validate.Var(name, "required,max=255"); err != nil {
+			return nil, fmt.Errorf("%w: name: %v", customErr.ErrInvalidInput, err)
+		}
+	}
+	if patch.Kind != nil {
+		if err := s.validate.Var(*patch.Kind, "required,oneof=cash bank card"); err != nil {
+			return nil, fmt.Errorf("%w: kind: %v", customErr.ErrInvalidInput, err)
+		}
+	}
+
+	s.logger.Info().Int64("id", id).Msg("Updating account")
+	account, err := s.repo.UpdateAccount(ctx, id, patch)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to update account")
+		return nil, accountErr(err)
+	}
+	return account, nil
+}
+
+// DeleteAccount удаляет счёт, на который не ссылается ни одна операция.
+func (s *Service) DeleteAccount(ctx context.Context, id int64) error {
+	if err := domain.Authorize(ctx, domain.PermItemsDelete); err != nil {
+		return err
+	}
+	if id <= 0 {
+		return customErr.ErrInvalidInput
+	}
+	s.logger.Info().Int64("id", id).Msg("Deleting account")
+	if err := s.repo.DeleteAccount(ctx, id); err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to delete account")
+		return accountErr(err)
+	}
+	return nil
+}
+
+// GetStatement возвращает выписку счёта с нарастающим остатком за период.
+func (s *Service) GetStatement(ctx context.Context, id int64, params *domain.AccountStatementParams) (*domain.AccountStatement, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsRead); err != nil {
+		return nil, err
+	}
+	if id <= 0 {
+		return nil, customErr.ErrInvalidInput
+	}
+	if err := s.validate.Struct(params); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if params.From != nil && params.To != nil && params.From.After(*params.To) {
+		return nil, customErr.ErrInvalidDateRange
+	}
+	st, err := s.repo.GetStatement(ctx, id, params)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to get account statement")
+		return nil, accountErr(err)
+	}
+	return st, nil
+}
+
+// accountErr сводит ошибку репозитория к бизнес-ошибкам сервиса.
+func accountErr(err error) error {
+	for _, known := range []error{customErr.ErrAccountNotFound, customErr.ErrAccountInUse, customErr.ErrDatabase} {
+		if errors.Is(err, known) {
+			return known
+		}
+	}
+	if errors.Is(err, customErr.ErrAccountExists) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+}
@@ -0,0 +1,15 @@
+package accounts_usecase
+
+import (
+	"context"
+	"sales-tracker/internal/domain"
+)
+
+type accountsRepository interface {
+	CreateAccount(ctx context.Context, account *domain.Account) error
+	GetAccount(ctx context.Context, id int64) (*domain.Account, error)
+	ListAccountBalances(ctx context.Context) ([]*domain.AccountBalance, error)
+	UpdateAccount(ctx context.Context, id int64, patch *domain.AccountPatch) (*domain.Account, error)
+	DeleteAccount(ctx context.Context, id int64) error
+	GetStatement(ctx context.Context, id int64, params *domain.AccountStatementParams) (*domain.AccountStatement, error)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sales-tracker/internal/domain"
 	customErr "sales-tracker/internal/domain/errors"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -26,29 +28,250 @@ func NewService(repo analyticsRepository, logger *zlog.Zerolog) *Service {
 	}
 }
 
-func (s *Service) GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error) {
+func validateRange(from, to time.Time) error {
 	if from.IsZero() || to.IsZero() {
-		return nil, customErr.ErrMissingParameter
+		return customErr.ErrMissingParameter
 	}
 
 	if from.After(to) {
-		return nil, customErr.ErrInvalidDateRange
+		return customErr.ErrInvalidDateRange
+	}
+	return nil
+}
+
+func validatePeriod(from, to time.Time) error {
+	if err := validateRange(from, to); err != nil {
+		return err
 	}
 
 	maxPeriod := 365 * 24 * time.Hour
 	if to.Sub(from) > maxPeriod {
-		return nil, customErr.ErrPeriodTooLarge
+		return customErr.ErrPeriodTooLarge
 	}
+	return nil
+}
 
-	s.logger.Info().Time("from", from).Time("to", to).Msg("Getting analytics")
-	anal, err := s.repo.GetAnalytics(ctx, from, to)
+func (s *Service) GetAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error) {
+	if err := domain.Authorize(ctx, domain.PermAnalyticsRead); err != nil {
+		return nil, err
+	}
+	if err := validatePeriod(params.From, params.To); err != nil {
+		return nil, err
+	}
+	return s.getAnalytics(ctx, params)
+}
+
+// GetExportAnalytics считает сводку для выгрузки /items/export и требует
+// права export, а не чтения аналитики. Операции выгрузки читаются курсором,
+// поэтому ограничение периода в 365 дней к ней не применяется.
+func (s *Service) GetExportAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error) {
+	if err := domain.Authorize(ctx, domain.PermExport); err != nil {
+		return nil, err
+	}
+	if err := validateRange(params.From, params.To); err != nil {
+		return nil, err
+	}
+	return s.getAnalytics(ctx, params)
+}
+
+// GetReportAnalytics считает аналитику для фоновых отчётов:
+// ограничение периода в 365 дней к ним не применяется.
+func (s *Service) GetReportAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error) {
+	if err := domain.Authorize(ctx, domain.PermExport); err != nil {
+		return nil, err
+	}
+	if err := validateRange(params.From, params.To); err != nil {
+		return nil, err
+	}
+	return s.getAnalytics(ctx, params)
+}
+
+func (s *Service) getAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error) {
+	params.Currency = strings.ToUpper(params.Currency)
+	if params.Currency == "" {
+		params.Currency = domain.BaseCurrency
+	}
+	if params.Histogram != "" && params.Bins == 0 {
+		params.Bins = 10
+	}
+	if err := s.validate.Struct(params); err != nil {
+		s.logger.Error().Err(err).Msg("Validation failed")
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+
+	s.logger.Info().Time("from", params.From).Time("to", params.To).Str("currency", params.Currency).Msg("Getting analytics")
+	anal, err := s.repo.GetAnalytics(ctx, params)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Failed to get analytics")
+		if errors.Is(err, customErr.ErrRateNotFound) {
+			return nil, err
+		}
 		if errors.Is(err, customErr.ErrDatabase) {
 			return nil, customErr.ErrDatabase
 		}
 		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
 	}
+
+	if params.Compare != domain.CompareNone {
+		comparison, err := s.compare(ctx, params, anal)
+		if err != nil {
+			return nil, err
+		}
+		anal.Comparison = comparison
+	}
 	s.logger.Info().Msg("Analytics retrieved")
 	return anal, nil
 }
+
+func (s *Service) compare(ctx context.Context, params *domain.AnalyticsParams, current *domain.Analytics) (*domain.AnalyticsComparison, error) {
+	from, to := comparisonWindow(params.Compare, params.From, params.To)
+	s.logger.Info().Str("compare", params.Compare).Time("from", from).Time("to", to).Msg("Getting comparison analytics")
+	previous, err := s.repo.GetAnalytics(ctx, &domain.AnalyticsParams{
+		From:        from,
+		To:          to,
+		Currency:    params.Currency,
+		SkipDetails: true,
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get comparison analytics")
+		if errors.Is(err, customErr.ErrRateNotFound) {
+			return nil, err
+		}
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	return &domain.AnalyticsComparison{
+		Mode:     params.Compare,
+		From:     from,
+		To:       to,
+		Previous: previous,
+		Income:   analyticsDelta(current.Income, previous.Income),
+		Expense:  analyticsDelta(current.Expense, previous.Expense),
+	}, nil
+}
+
+func (s *Service) GetTimeSeries(ctx context.Context, params *domain.TimeSeriesParams) (*domain.TimeSeries, error) {
+	if err := domain.Authorize(ctx, domain.PermAnalyticsRead); err != nil {
+		return nil, err
+	}
+	if err := validatePeriod(params.From, params.To); err != nil {
+		return nil, err
+	}
+
+	if params.Interval == "" {
+		params.Interval = "day"
+	}
+	if params.TimeZone == "" {
+		params.TimeZone = "UTC"
+	}
+	params.Currency = strings.ToUpper(params.Currency)
+	if params.Currency == "" {
+		params.Currency = domain.BaseCurrency
+	}
+	if err := s.validate.Struct(params); err != nil {
+		s.logger.Error().Err(err).Msg("Validation failed")
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	loc, err := time.LoadLocation(params.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+
+	s.logger.Info().
+		Time("from", params.From).
+		Time("to", params.To).
+		Str("interval", params.Interval).
+		Str("tz", params.TimeZone).
+		Msg("Getting analytics time series")
+	series, err := s.repo.GetTimeSeries(ctx, params)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get analytics time series")
+		if errors.Is(err, customErr.ErrRateNotFound) || errors.Is(err, customErr.ErrInvalidInput) {
+			return nil, err
+		}
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	for _, bucket := range series.Buckets {
+		bucket.Start = bucket.Start.In(loc)
+	}
+	s.logger.Info().Int("buckets", len(series.Buckets)).Msg("Analytics time series retrieved")
+	return series, nil
+}
+
+func (s *Service) GetBreakdown(ctx context.Context, params *domain.BreakdownParams) (*domain.Breakdown, error) {
+	if err := domain.Authorize(ctx, domain.PermAnalyticsRead); err != nil {
+		return nil, err
+	}
+	if err := validatePeriod(params.From, params.To); err != nil {
+		return nil, err
+	}
+
+	if params.TimeZone == "" {
+		params.TimeZone = "UTC"
+	}
+	params.Currency = strings.ToUpper(params.Currency)
+	if params.Currency == "" {
+		params.Currency = domain.BaseCurrency
+	}
+	if len(params.GroupBy) == 0 {
+		params.GroupBy = []string{"category", "type"}
+	}
+	if err := s.validate.Struct(params); err != nil {
+		s.logger.Error().Err(err).Msg("Validation failed")
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	// Доходы и расходы не складываются: тип — неявное измерение любой разбивки.
+	if !slices.Contains(params.GroupBy, "type") {
+		params.GroupBy = append(params.GroupBy, "type")
+	}
+
+	s.logger.Info().
+		Time("from", params.From).
+		Time("to", params.To).
+		Strs("group_by", params.GroupBy).
+		Int("top", params.Top).
+		Msg("Getting analytics breakdown")
+	breakdown, err := s.repo.GetBreakdown(ctx, params)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get analytics breakdown")
+		if errors.Is(err, customErr.ErrRateNotFound) || errors.Is(err, customErr.ErrInvalidInput) {
+			return nil, err
+		}
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	s.logger.Info().Int("groups", len(breakdown.Groups)).Msg("Analytics breakdown retrieved")
+	return breakdown, nil
+}
+
+// StreamDetails передаёт операции периода в fn по одной. Используется экспортом,
+// поэтому ограничение периода не проверяется: сводка запрашивается отдельно.
+func (s *Service) StreamDetails(ctx context.Context, params *domain.AnalyticsParams, fn func(*domain.Item) error) error {
+	if err := domain.Authorize(ctx, domain.PermExport); err != nil {
+		return err
+	}
+	if err := validateRange(params.From, params.To); err != nil {
+		return err
+	}
+
+	s.logger.Info().Time("from", params.From).Time("to", params.To).Msg("Streaming analytics details")
+	err := s.repo.StreamDetails(ctx, params, fn)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if errors.Is(err, customErr.ErrDatabase) {
+			s.logger.Error().Err(err).Msg("Failed to stream analytics details")
+			return customErr.ErrDatabase
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package analytics_usecase
+
+import (
+	"math"
+	"sales-tracker/internal/domain"
+	"time"
+)
+
+// comparisonWindow возвращает окно сравнения для периода [from, to].
+// previous_period — отрезок той же длительности, заканчивающийся перед from;
+// previous_year — тот же период годом ранее.
+func comparisonWindow(mode string, from, to time.Time) (time.Time, time.Time) {
+	if mode == domain.ComparePreviousYear {
+		return from.AddDate(-1, 0, 0), to.AddDate(-1, 0, 0)
+	}
+	prevTo := from.Add(-time.Microsecond)
+	return prevTo.Add(-to.Sub(from)), prevTo
+}
+
+func percentChange(cur, prev int64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	pct := math.Round(float64(cur-prev)/math.Abs(float64(prev))*10000) / 100
+	return &pct
+}
+
+func moneyDelta(cur, prev domain.Money) domain.MoneyDelta {
+	return domain.MoneyDelta{
+		Abs:     cur - prev,
+		Percent: percentChange(int64(cur), int64(prev)),
+	}
+}
+
+func analyticsDelta(cur, prev *domain.ItemAnalytics) *domain.ItemAnalyticsDelta {
+	return &domain.ItemAnalyticsDelta{
+		Sum: moneyDelta(cur.Sum, prev.Sum),
+		Avg: moneyDelta(cur.Avg, prev.Avg),
+		Count: domain.CountDelta{
+			Abs:     cur.Count - prev.Count,
+			Percent: percentChange(cur.Count, prev.Count),
+		},
+		Median:    moneyDelta(cur.Median, prev.Median),
+		Percent90: moneyDelta(cur.Percent90, prev.Percent90),
+	}
+}
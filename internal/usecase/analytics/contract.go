@@ -3,9 +3,11 @@ package analytics_usecase
 import (
 	"context"
 	"sales-tracker/internal/domain"
-	"time"
 )
 
 type analyticsRepository interface {
-	GetAnalytics(ctx context.Context, from, to time.Time) (*domain.Analytics, error)
+	GetAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error)
+	GetTimeSeries(ctx context.Context, params *domain.TimeSeriesParams) (*domain.TimeSeries, error)
+	GetBreakdown(ctx context.Context, params *domain.BreakdownParams) (*domain.Breakdown, error)
+	StreamDetails(ctx context.Context, params *domain.AnalyticsParams, fn func(*domain.Item) error) error
 }
@@ -0,0 +1,249 @@
+package auth_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/auth"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// apiKeyAttempts — число попыток выпустить ключ при совпадении префикса.
+const apiKeyAttempts = 3
+
+type Options struct {
+	// DefaultWorkspace — slug пространства для JWT без claim workspace.
+	// Пустое значение делает claim обязательным.
+	DefaultWorkspace string
+}
+
+type Service struct {
+	repo       apiKeysRepository
+	workspaces workspacesRepository
+	tokens     tokenVerifier
+	opts       Options
+	logger     *zlog.Zerolog
+	validate   *validator.Validate
+}
+
+func NewService(repo apiKeysRepository, workspaces workspacesRepository, tokens tokenVerifier, opts Options, logger *zlog.Zerolog) *Service {
+	return &Service{
+		repo:       repo,
+		workspaces: workspaces,
+		tokens:     tokens,
+		opts:       opts,
+		logger:     logger,
+		validate:   validator.New(),
+	}
+}
+
+// Authenticate определяет субъект и его пространство по API-ключу или JWT.
+// Ключ принадлежит пространству, в котором выпущен; для JWT пространство
+// задаёт claim workspace (slug). Любая проблема с учётными данными
+// возвращается как ErrUnauthorized без подробностей для клиента; причина
+// пишется в лог.
+func (s *Service) Authenticate(ctx context.Context, token string) (*domain.Principal, error) {
+	if token == "" {
+		return nil, customErr.ErrUnauthorized
+	}
+	if auth.IsAPIKey(token) {
+		return s.authenticateAPIKey(ctx, token)
+	}
+	if !s.tokens.Enabled() {
+		return nil, fmt.Errorf("%w: bearer tokens are not configured", customErr.ErrUnauthorized)
+	}
+	claims, err := s.tokens.Verify(token, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrUnauthorized, err)
+	}
+	slug := claims.Workspace
+	if slug == "" {
+		slug = s.opts.DefaultWorkspace
+	}
+	if slug == "" {
+		return nil, fmt.Errorf("%w: token has no workspace claim", customErr.ErrUnauthorized)
+	}
+	ws, err := s.workspaces.GetWorkspaceBySlug(ctx, slug)
+	if errors.Is(err, customErr.ErrWorkspaceNotFound) {
+		return nil, fmt.Errorf("%w: unknown workspace %q", customErr.ErrUnauthorized, slug)
+	}
+	if err != nil {
+		return nil, err
+	}
+	name := claims.Name
+	if name == "" {
+		name = claims.Subject
+	}
+	return &domain.Principal{
+		Subject:     claims.Subject,
+		Name:        name,
+		Method:      domain.AuthMethodJWT,
+		Roles:       claims.Roles,
+		WorkspaceID: ws.ID,
+	}, nil
+}
+
+func (s *Service) authenticateAPIKey(ctx context.Context, token string) (*domain.Principal, error) {
+	prefix, ok := auth.ParseAPIKey(token)
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed api key", customErr.ErrUnauthorized)
+	}
+	key, err := s.repo.GetAPIKeyByPrefix(domain.WithAllWorkspaces(ctx), prefix)
+	if errors.Is(err, customErr.ErrAPIKeyNotFound) {
+		return nil, fmt.Errorf("%w: unknown api key %s", customErr.ErrUnauthorized, prefix)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !auth.MatchAPIKey(token, key.Hash) {
+		return nil, fmt.Errorf("%w: api key %s does not match", customErr.ErrUnauthorized, prefix)
+	}
+	if !key.Active(time.Now()) {
+		return nil, fmt.Errorf("%w: api key %s is revoked or expired", customErr.ErrUnauthorized, prefix)
+	}
+	if err := s.repo.TouchAPIKey(domain.WithWorkspace(ctx, key.WorkspaceID), key.ID); err != nil {
+		s.logger.Warn().Err(err).Int64("key_id", key.ID).Msg("Failed to update api key usage")
+	}
+	return &domain.Principal{
+		Subject:     "apikey:" + strconv.FormatInt(key.ID, 10),
+		Name:        key.Name,
+		Method:      domain.AuthMethodAPIKey,
+		Roles:       key.Roles,
+		WorkspaceID: key.WorkspaceID,
+	}, nil
+}
+
+// CreateAPIKey выпускает ключ в пространстве из контекста и возвращает его
+// единственный раз; в базе остаются только префикс и хеш.
+func (s *Service) CreateAPIKey(ctx context.Context, key *domain.APIKey) (string, error) {
+	if err := domain.Authorize(ctx, domain.PermKeysManage); err != nil {
+		return "", err
+	}
+	key.Name = strings.TrimSpace(key.Name)
+	key.Roles = normalizeRoles(key.Roles)
+	if err := s.validate.Struct(key); err != nil {
+		return "", fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	for _, role := range key.Roles {
+		if !domain.IsRole(role) {
+			return "", fmt.Errorf("%w: unknown role %q, expected one of %s", customErr.ErrInvalidInput, role, strings.Join(domain.Roles, ", "))
+		}
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return "", fmt.Errorf("%w: expires_at must be in the future", customErr.ErrInvalidInput)
+	}
+	// Префикс для поиска короткий (32 бита), поэтому совпадение с уже
+	// выпущенным ключом возможно: в этом случае ключ генерируется заново.
+	var secret string
+	for attempt := 1; ; attempt++ {
+		var err error
+		secret, key.Prefix, err = auth.GenerateAPIKey()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+		}
+		key.Hash = auth.HashAPIKey(secret)
+		err = s.repo.CreateAPIKey(ctx, key)
+		if err == nil {
+			break
+		}
+		if errors.Is(err, customErr.ErrAPIKeyPrefixTaken) && attempt < apiKeyAttempts {
+			s.logger.Warn().Err(err).Int("attempt", attempt).Msg("API key prefix collision, regenerating")
+			continue
+		}
+		s.logger.Error().Err(err).Msg("Failed to create api key")
+		return "", err
+	}
+	s.logger.Info().
+		Int64("key_id", key.ID).
+		Int64("workspace_id", key.WorkspaceID).
+		Str("name", key.Name).
+		Strs("roles", key.Roles).
+		Str("by", domain.PrincipalFrom(ctx).Subject).
+		Msg("API key created")
+	return secret, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	if err := domain.Authorize(ctx, domain.PermKeysManage); err != nil {
+		return nil, err
+	}
+	keys, err := s.repo.ListAPIKeys(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to list api keys")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error) {
+	if err := domain.Authorize(ctx, domain.PermKeysManage); err != nil {
+		return nil, err
+	}
+	key, err := s.repo.RevokeAPIKey(ctx, id)
+	if err != nil {
+		if !errors.Is(err, customErr.ErrAPIKeyNotFound) {
+			s.logger.Error().Err(err).Int64("key_id", id).Msg("Failed to revoke api key")
+		}
+		return nil, err
+	}
+	s.logger.Info().Int64("key_id", id).Str("by", domain.PrincipalFrom(ctx).Subject).Msg("API key revoked")
+	return key, nil
+}
+
+// CreateWorkspace заводит пространство. Пространства создаются только
+// от имени system — командой CLI.
+func (s *Service) CreateWorkspace(ctx context.Context, ws *domain.Workspace) error {
+	if err := domain.Authorize(ctx, domain.PermWorkspacesManage); err != nil {
+		return err
+	}
+	ws.Slug = strings.ToLower(strings.TrimSpace(ws.Slug))
+	ws.Name = strings.TrimSpace(ws.Name)
+	if ws.Name == "" {
+		ws.Name = ws.Slug
+	}
+	if err := s.validate.Struct(ws); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if err := s.workspaces.CreateWorkspace(ctx, ws); err != nil {
+		if !errors.Is(err, customErr.ErrWorkspaceExists) {
+			s.logger.Error().Err(err).Str("slug", ws.Slug).Msg("Failed to create workspace")
+		}
+		return err
+	}
+	s.logger.Info().Int64("workspace_id", ws.ID).Str("slug", ws.Slug).Msg("Workspace created")
+	return nil
+}
+
+func (s *Service) ListWorkspaces(ctx context.Context) ([]*domain.Workspace, error) {
+	if err := domain.Authorize(ctx, domain.PermWorkspacesManage); err != nil {
+		return nil, err
+	}
+	return s.workspaces.ListWorkspaces(ctx)
+}
+
+// GetWorkspace возвращает пространство по slug.
+func (s *Service) GetWorkspace(ctx context.Context, slug string) (*domain.Workspace, error) {
+	if err := domain.Authorize(ctx, domain.PermWorkspacesManage); err != nil {
+		return nil, err
+	}
+	return s.workspaces.GetWorkspaceBySlug(ctx, strings.ToLower(strings.TrimSpace(slug)))
+}
+
+func normalizeRoles(roles []string) []string {
+	normalized := make([]string, 0, len(roles))
+	for _, role := range roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role != "" && !slices.Contains(normalized, role) {
+			normalized = append(normalized, role)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,27 @@
+package auth_usecase
+
+import (
+	"context"
+	"sales-tracker/internal/auth"
+	"sales-tracker/internal/domain"
+	"time"
+)
+
+type apiKeysRepository interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) (*domain.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int64) error
+}
+
+type workspacesRepository interface {
+	CreateWorkspace(ctx context.Context, ws *domain.Workspace) error
+	GetWorkspaceBySlug(ctx context.Context, slug string) (*domain.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]*domain.Workspace, error)
+}
+
+type tokenVerifier interface {
+	Enabled() bool
+	Verify(token string, now time.Time) (*auth.Claims, error)
+}
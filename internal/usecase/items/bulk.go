@@ -0,0 +1,262 @@
+package items_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+)
+
+const maxBulkOperations = 1000
+
+// BulkItems выполняет пакет операций create/update/delete. В атомарном режиме
+// пакет пишется одной транзакцией и отклоняется целиком при любой ошибке,
+// иначе каждая операция выполняется в своей транзакции независимо от других:
+// сбой одной операции записывается в её результат, а уже выполненные
+// остаются в ответе. Отмена контекста прерывает пакет, и оставшиеся операции
+// помечаются невыполненными.
+func (s *Service) BulkItems(ctx context.Context, ops []*domain.BulkOperation, opts *domain.BulkOptions) (*domain.BulkResult, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: no operations", customErr.ErrMissingParameter)
+	}
+	if len(ops) > maxBulkOperations {
+		return nil, fmt.Errorf("%w: at most %d operations per request", customErr.ErrInvalidInput, maxBulkOperations)
+	}
+	if err := authorizeBulk(ctx, ops); err != nil {
+		return nil, err
+	}
+
+	result := &domain.BulkResult{Atomic: opts.Atomic, Results: make([]*domain.BulkOpResult, len(ops))}
+	invalid := false
+	for i, op := range ops {
+		result.Results[i] = &domain.BulkOpResult{Index: i, Op: op.Op, ID: op.ID}
+		if err := s.validateBulkOp(op); err != nil {
+			result.Results[i].Err = err
+			invalid = true
+		}
+	}
+	s.logger.Info().Int("operations", len(ops)).Bool("atomic", opts.Atomic).Msg("Running bulk item operations")
+
+	if opts.Atomic {
+		if invalid {
+			result.Reject()
+		} else if err := s.execBulk(ctx, ops, result.Results); err != nil {
+			var opErr *domain.BulkOpError
+			if !errors.As(err, &opErr) {
+				return nil, err
+			}
+			result.Results[opErr.Index].Err = bulkOpErr(opErr.Err)
+			result.Reject()
+		}
+	} else {
+		for i, op := range ops {
+			if result.Results[i].Err != nil {
+				continue
+			}
+			if ctx.Err() != nil {
+				result.Results[i].NotExecuted = true
+				continue
+			}
+			if err := s.execBulk(ctx, []*domain.BulkOperation{op}, result.Results[i:i+1]); err != nil {
+				var opErr *domain.BulkOpError
+				if errors.As(err, &opErr) {
+					err = opErr.Err
+				}
+				result.Results[i].Err = bulkOpErr(err)
+			}
+		}
+	}
+
+	for _, res := range result.Results {
+		switch {
+		case res.Err != nil, res.NotExecuted:
+			result.Failed++
+		case !res.RolledBack:
+			result.Succeeded++
+		}
+	}
+	s.logger.Info().Int("succeeded", result.Succeeded).Int("failed", result.Failed).Msg("Bulk item operations finished")
+	return result, nil
+}
+
+// authorizeBulk требует права на запись для пакета и право на удаление,
+// если в пакете есть удаления: пакет не должен обходить проверки одиночных
+// операций.
+func authorizeBulk(ctx context.Context, ops []*domain.BulkOperation) error {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if op.Op == domain.BulkOpDelete {
+			return domain.Authorize(ctx, domain.PermItemsDelete)
+		}
+	}
+	return nil
+}
+
+// execBulk выполняет ops одной транзакцией и переносит ID и версии в results.
+func (s *Service) execBulk(ctx context.Context, ops []*domain.BulkOperation, results []*domain.BulkOpResult) error {
+	done, err := s.repo.ExecBulk(ctx, ops)
+	if err != nil {
+		var opErr *domain.BulkOpError
+		if errors.As(err, &opErr) {
+			return opErr
+		}
+		s.logger.Error().Err(err).Msg("Failed to run bulk operations")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return customErr.ErrDatabase
+		}
+		return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	for i, res := range done {
+		results[i].ID = res.ID
+		results[i].Version = res.Version
+	}
+	return nil
+}
+
+func (s *Service) validateBulkOp(op *domain.BulkOperation) error {
+	if err := s.validate.Struct(op); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if op.Op != domain.BulkOpCreate && op.ID <= 0 {
+		return fmt.Errorf("%w: id is required for %s", customErr.ErrInvalidInput, op.Op)
+	}
+	if op.Op == domain.BulkOpDelete {
+		return nil
+	}
+	if op.Item == nil {
+		return fmt.Errorf("%w: item is required for %s", customErr.ErrInvalidInput, op.Op)
+	}
+	return s.validateItem(op.Item)
+}
+
+// bulkOpErr сводит ошибку операции к бизнес-ошибкам, как одиночные методы сервиса.
+func bulkOpErr(err error) error {
+	for _, known := range []error{customErr.ErrItemNotFound, customErr.ErrVersionMismatch, customErr.ErrInvalidInput, customErr.ErrDatabase} {
+		if errors.Is(err, known) {
+			return known
+		}
+	}
+	return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+}
+
+// UpdateItemsByFilter применяет патч ко всем записям, подходящим под фильтр.
+// При dryRun только возвращает число таких записей.
+func (s *Service) UpdateItemsByFilter(ctx context.Context, filter *domain.ItemsFilter, patch *domain.ItemPatch, dryRun bool) (int64, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return 0, err
+	}
+	if err := s.validateBulkFilter(filter); err != nil {
+		return 0, err
+	}
+	if err := s.validatePatch(patch); err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return s.countItems(ctx, filter)
+	}
+	s.logger.Info().Msg("Updating items by filter")
+	n, err := s.repo.UpdateItemsByFilter(ctx, filter, patch)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to update items by filter")
+		if errors.Is(err, customErr.ErrInvalidInput) {
+			return 0, err
+		}
+		if errors.Is(err, customErr.ErrDatabase) {
+			return 0, customErr.ErrDatabase
+		}
+		return 0, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	s.logger.Info().Int64("updated", n).Msg("Items updated by filter")
+	return n, nil
+}
+
+// DeleteItemsByFilter удаляет все записи, подходящие под фильтр.
+// При dryRun только возвращает число таких записей.
+func (s *Service) DeleteItemsByFilter(ctx context.Context, filter *domain.ItemsFilter, dryRun bool) (int64, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsDelete); err != nil {
+		return 0, err
+	}
+	if err := s.validateBulkFilter(filter); err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return s.countItems(ctx, filter)
+	}
+	s.logger.Info().Msg("Deleting items by filter")
+	n, err := s.repo.DeleteItemsByFilter(ctx, filter)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to delete items by filter")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return 0, customErr.ErrDatabase
+		}
+		return 0, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	s.logger.Info().Int64("deleted", n).Msg("Items deleted by filter")
+	return n, nil
+}
+
+func (s *Service) countItems(ctx context.Context, filter *domain.ItemsFilter) (int64, error) {
+	n, err := s.repo.CountItems(ctx, filter)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to count items")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return 0, customErr.ErrDatabase
+		}
+		return 0, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	return n, nil
+}
+
+// validateBulkFilter не даёт массовой операции случайно затронуть все записи.
+func (s *Service) validateBulkFilter(filter *domain.ItemsFilter) error {
+	if filter == nil || !filter.HasConditions() {
+		return fmt.Errorf("%w: bulk operation requires at least one filter condition", customErr.ErrMissingParameter)
+	}
+	return s.validateFilter(filter)
+}
+
+// validatePatch проверяет задаваемые патчем значения по правилам domain.Item.
+func (s *Service) validatePatch(patch *domain.ItemPatch) error {
+	if patch == nil || *patch == (domain.ItemPatch{}) {
+		return fmt.Errorf("%w: nothing to update", customErr.ErrMissingParameter)
+	}
+	if patch.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*patch.Currency))
+		if currency == "" {
+			currency = domain.BaseCurrency
+		}
+		patch.Currency = &currency
+	}
+	var err error
+	check := func(value any, tag string) {
+		if err == nil {
+			err = s.validate.Var(value, tag)
+		}
+	}
+	if patch.Type != nil {
+		check(*patch.Type, "required,oneof=income expense transfer")
+	}
+	if patch.Amount != nil {
+		check(int64(*patch.Amount), "gte=0")
+	}
+	if patch.Currency != nil {
+		check(*patch.Currency, "required,iso4217")
+	}
+	if patch.Date != nil {
+		check(*patch.Date, "required")
+	}
+	if patch.AccountID != nil {
+		check(*patch.AccountID, "gte=0")
+	}
+	if patch.ToAccountID != nil {
+		check(*patch.ToAccountID, "gte=0")
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	return nil
+}
@@ -3,13 +3,28 @@ package items_usecase
 import (
 	"context"
 	"sales-tracker/internal/domain"
+	"time"
 )
 
 type itemsRepository interface {
 	CreateItem(ctx context.Context, item *domain.Item) (int64, error)
+	CreateItemIdempotent(ctx context.Context, key, requestHash string, ttl time.Duration, item *domain.Item) (int64, bool, error)
+	CreateItems(ctx context.Context, items []*domain.Item) error
+	GetExistingExternalIDs(ctx context.Context, ids []string) (map[string]bool, error)
 	GetItems(ctx context.Context) ([]*domain.Item, error)
-	GetItemsWithPagination(ctx context.Context, offset, limit int) ([]*domain.Item, int64, error)
+	GetItemsWithPagination(ctx context.Context, filter *domain.ItemsFilter, offset, limit int) ([]*domain.Item, int64, error)
+	GetItemsByCursor(ctx context.Context, filter *domain.ItemsFilter, cursor *domain.ItemsCursor, limit int, withTotal bool) (*domain.ItemsPage, error)
 	GetItemByID(ctx context.Context, id int64) (*domain.Item, error)
-	UpdateItem(ctx context.Context, id int64, item *domain.Item) error
-	DeleteItem(ctx context.Context, id int64) error
+	UpdateItem(ctx context.Context, id int64, item *domain.Item, version int64) error
+	DeleteItem(ctx context.Context, id int64, version int64) error
+	ExecBulk(ctx context.Context, ops []*domain.BulkOperation) ([]*domain.BulkOpResult, error)
+	CountItems(ctx context.Context, filter *domain.ItemsFilter) (int64, error)
+	UpdateItemsByFilter(ctx context.Context, filter *domain.ItemsFilter, patch *domain.ItemPatch) (int64, error)
+	DeleteItemsByFilter(ctx context.Context, filter *domain.ItemsFilter) (int64, error)
+	GetTrash(ctx context.Context, offset, limit int) ([]*domain.Item, int64, error)
+	RestoreItem(ctx context.Context, id int64) (*domain.Item, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	GetItemHistory(ctx context.Context, itemID int64) ([]*domain.AuditEntry, error)
+	GetAudit(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEntry, error)
+	StreamHistory(ctx context.Context, fn func(*domain.AuditEntry) error) error
 }
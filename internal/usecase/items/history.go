@@ -0,0 +1,117 @@
+package items_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+)
+
+const defaultAuditLimit = 100
+
+// GetItemHistory возвращает историю изменений записи, включая удалённые.
+// Если изменений не было, запись считается несуществующей.
+func (s *Service) GetItemHistory(ctx context.Context, id int64) ([]*domain.AuditEntry, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsRead); err != nil {
+		return nil, err
+	}
+	if id <= 0 {
+		return nil, customErr.ErrInvalidInput
+	}
+	entries, err := s.repo.GetItemHistory(ctx, id)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to get item history")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	if len(entries) == 0 {
+		return nil, customErr.ErrItemNotFound
+	}
+	return entries, nil
+}
+
+// GetAudit возвращает журнал изменений всех записей по фильтру.
+func (s *Service) GetAudit(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEntry, error) {
+	if err := domain.Authorize(ctx, domain.PermAuditRead); err != nil {
+		return nil, err
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	filter.Actor = strings.TrimSpace(filter.Actor)
+	if err := s.validate.Struct(filter); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, customErr.ErrInvalidDateRange
+	}
+	entries, err := s.repo.GetAudit(ctx, filter)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get audit log")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	return entries, nil
+}
+
+// VerifyAudit проходит цепочку журнала от начала и пересчитывает хеши.
+// Останавливается на первом нарушенном звене: пропуске seq, несовпадении
+// prev_hash с хешем предыдущей записи или хеша с содержимым записи.
+// Если задан anchor, запись с его seq должна иметь тот же хеш, а цепочка
+// не может заканчиваться раньше неё.
+func (s *Service) VerifyAudit(ctx context.Context, anchor *domain.AuditAnchor) (*domain.AuditVerification, error) {
+	if err := domain.Authorize(ctx, domain.PermAuditRead); err != nil {
+		return nil, err
+	}
+	result := &domain.AuditVerification{Valid: true, HeadHash: domain.AuditGenesisHash}
+	errBroken := errors.New("audit chain broken")
+	err := s.repo.StreamHistory(ctx, func(entry *domain.AuditEntry) error {
+		reason := ""
+		switch {
+		case entry.Seq != result.HeadSeq+1:
+			reason = domain.AuditBreakSeqGap
+		case entry.PrevHash != result.HeadHash:
+			reason = domain.AuditBreakPrevHash
+		case entry.ChainHash(entry.PrevHash) != entry.Hash:
+			reason = domain.AuditBreakHashMismatch
+		case anchor != nil && entry.Seq == anchor.Seq && entry.Hash != anchor.Hash:
+			reason = domain.AuditBreakAnchorMismatch
+		}
+		if reason != "" {
+			result.Valid = false
+			result.Broken = &domain.AuditBreak{Seq: entry.Seq, EntryID: entry.ID, Reason: reason}
+			return errBroken
+		}
+		result.Checked++
+		result.HeadSeq = entry.Seq
+		result.HeadHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBroken) {
+		s.logger.Error().Err(err).Msg("Failed to verify audit chain")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	if result.Valid && anchor != nil && result.HeadSeq < anchor.Seq {
+		result.Valid = false
+		result.Broken = &domain.AuditBreak{Seq: result.HeadSeq + 1, Reason: domain.AuditBreakTruncated}
+	}
+	if result.Valid {
+		s.logger.Info().Int64("checked", result.Checked).Str("head_hash", result.HeadHash).Msg("Audit chain verified")
+	} else {
+		s.logger.Warn().
+			Int64("seq", result.Broken.Seq).
+			Int64("entry_id", result.Broken.EntryID).
+			Str("reason", result.Broken.Reason).
+			Msg("Audit chain broken")
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package items_usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// CreateItemIdempotent создаёт запись с ключом идемпотентности. Повтор с тем же
+// ключом и теми же данными в течение IdempotencyTTL возвращает ID первой записи
+// и replayed = true; повтор с другими данными — ErrIdempotencyKey.
+func (s *Service) CreateItemIdempotent(ctx context.Context, key string, item *domain.Item) (int64, bool, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return 0, false, err
+	}
+	if key == "" || len(key) > maxIdempotencyKeyLength || strings.IndexFunc(key, unicode.IsControl) >= 0 {
+		return 0, false, fmt.Errorf("%w: invalid idempotency key", customErr.ErrInvalidInput)
+	}
+	if err := s.validateItem(item); err != nil {
+		s.logger.Error().Err(err).Msg("Validation failed")
+		return 0, false, err
+	}
+
+	s.logger.Info().Str("idempotency_key", key).Msg("Creating item idempotently")
+	id, replayed, err := s.repo.CreateItemIdempotent(ctx, key, itemHash(item), s.opts.IdempotencyTTL, item)
+	if err != nil {
+		if errors.Is(err, customErr.ErrIdempotencyKey) {
+			s.logger.Warn().Str("idempotency_key", key).Msg("Idempotency key reused with a different request")
+			return 0, false, err
+		}
+		s.logger.Error().Err(err).Msg("Failed to create item")
+		if errors.Is(err, customErr.ErrInvalidInput) {
+			return 0, false, err
+		}
+		if errors.Is(err, customErr.ErrDatabase) {
+			return 0, false, customErr.ErrDatabase
+		}
+		return 0, false, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	s.logger.Info().Int64("id", id).Bool("replayed", replayed).Msg("Item created")
+	return id, replayed, nil
+}
+
+// itemHash — отпечаток нормализованного запроса: одинаковые по смыслу
+// запросы дают один хеш независимо от форматирования JSON.
+func itemHash(item *domain.Item) string {
+	h := sha256.New()
+	for _, field := range []string{
+		item.Type,
+		item.Amount.String(),
+		item.Currency,
+		item.Date.UTC().Format(time.RFC3339Nano),
+		item.Category,
+		item.Description,
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	// Счета дописываются, только если заданы: хеши запросов без счетов
+	// совпадают с сохранёнными до их появления.
+	for i, account := range []*int64{item.AccountID, item.ToAccountID} {
+		if account != nil {
+			fmt.Fprintf(h, "account%d:%d", i, *account)
+			h.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,176 @@
+package items_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+)
+
+const defaultImportBatchSize = 500
+
+// ImportItems проверяет разобранные строки по правилам CreateItem и
+// записывает корректные партиями, каждая — в своей транзакции. В атомарном
+// режиме любая ошибка отменяет импорт целиком, а все партии пишутся одной
+// транзакцией.
+func (s *Service) ImportItems(ctx context.Context, rows []*domain.ImportRow, opts *domain.ImportOptions) (*domain.ImportResult, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return nil, err
+	}
+	if err := s.validate.Struct(opts); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows to import", customErr.ErrMissingParameter)
+	}
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	result := &domain.ImportResult{Total: len(rows), DryRun: opts.DryRun}
+	valid := make([]*domain.ImportRow, 0, len(rows))
+	for _, row := range rows {
+		if row.SkipReason != "" {
+			result.AddSkip(row.Line, "", row.SkipReason)
+			continue
+		}
+		err := row.Err
+		if err == nil && opts.AccountID > 0 {
+			row.Item.AccountID = &opts.AccountID
+		}
+		if err == nil {
+			err = s.validateItem(row.Item)
+		}
+		if err != nil {
+			result.AddError(row.Line, err)
+			continue
+		}
+		valid = append(valid, row)
+	}
+	valid, err := s.skipImported(ctx, valid, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Valid = len(valid)
+
+	s.logger.Info().
+		Int("total", result.Total).
+		Int("valid", result.Valid).
+		Bool("dry_run", opts.DryRun).
+		Bool("atomic", opts.Atomic).
+		Msg("Importing items")
+	if opts.DryRun {
+		return result, nil
+	}
+	if opts.Atomic && result.Failed > 0 {
+		result.Rejected = true
+		return result, nil
+	}
+	if len(valid) == 0 {
+		return result, nil
+	}
+
+	if opts.Atomic {
+		if err := s.repo.CreateItems(ctx, importItems(valid)); err != nil {
+			s.logger.Error().Err(err).Msg("Failed to import items")
+			if errors.Is(err, customErr.ErrInvalidInput) {
+				return nil, err
+			}
+			if errors.Is(err, customErr.ErrDatabase) {
+				return nil, customErr.ErrDatabase
+			}
+			return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+		}
+		result.Imported = countInserted(valid, result)
+		s.logger.Info().Int("imported", result.Imported).Msg("Items imported")
+		return result, nil
+	}
+
+	for start := 0; start < len(valid); start += batchSize {
+		batch := valid[start:min(start+batchSize, len(valid))]
+		if err := s.repo.CreateItems(ctx, importItems(batch)); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			s.logger.Error().Err(err).Int("line", batch[0].Line).Msg("Failed to import batch")
+			rowErr := customErr.ErrDatabase
+			if errors.Is(err, customErr.ErrInvalidInput) {
+				rowErr = err
+			}
+			for _, row := range batch {
+				result.AddError(row.Line, rowErr)
+			}
+			continue
+		}
+		result.Imported += countInserted(batch, result)
+	}
+	s.logger.Info().Int("imported", result.Imported).Int("failed", result.Failed).Msg("Items imported")
+	return result, nil
+}
+
+// skipImported убирает строки, чей внешний ID повторяется в файле
+// или уже есть в базе, и записывает их в отчёт как пропущенные.
+func (s *Service) skipImported(ctx context.Context, rows []*domain.ImportRow, result *domain.ImportResult) ([]*domain.ImportRow, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	unique := rows[:0:0]
+	for _, row := range rows {
+		id := row.Item.ExternalID
+		if id == "" {
+			unique = append(unique, row)
+			continue
+		}
+		if seen[id] {
+			result.AddSkip(row.Line, id, domain.ImportSkipDuplicate)
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		unique = append(unique, row)
+	}
+	if len(ids) == 0 {
+		return unique, nil
+	}
+
+	existing, err := s.repo.GetExistingExternalIDs(ctx, ids)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to check imported external IDs")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	fresh := unique[:0]
+	for _, row := range unique {
+		if existing[row.Item.ExternalID] {
+			result.AddSkip(row.Line, row.Item.ExternalID, domain.ImportSkipExisting)
+			continue
+		}
+		fresh = append(fresh, row)
+	}
+	return fresh, nil
+}
+
+// countInserted считает записанные строки. Строку, которую параллельный
+// импорт успел загрузить раньше, репозиторий пропускает с нулевым ID.
+func countInserted(rows []*domain.ImportRow, result *domain.ImportResult) int {
+	inserted := 0
+	for _, row := range rows {
+		if row.Item.ID == 0 {
+			result.AddSkip(row.Line, row.Item.ExternalID, domain.ImportSkipExisting)
+			continue
+		}
+		inserted++
+	}
+	return inserted
+}
+
+func importItems(rows []*domain.ImportRow) []*domain.Item {
+	items := make([]*domain.Item, len(rows))
+	for i, row := range rows {
+		items[i] = row.Item
+	}
+	return items
+}
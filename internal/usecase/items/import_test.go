@@ -0,0 +1,103 @@
+package items_usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sales-tracker/internal/domain"
+	"sales-tracker/internal/importer"
+
+	"github.com/rs/zerolog"
+)
+
+// importRepo хранит загруженные операции в памяти; остальные методы
+// репозитория в тестах импорта не вызываются.
+type importRepo struct {
+	itemsRepository
+	items  []*domain.Item
+	nextID int64
+}
+
+func (r *importRepo) CreateItems(_ context.Context, items []*domain.Item) error {
+	for _, item := range items {
+		r.nextID++
+		item.ID = r.nextID
+		r.items = append(r.items, item)
+	}
+	return nil
+}
+
+func (r *importRepo) GetExistingExternalIDs(_ context.Context, ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for _, item := range r.items {
+		for _, id := range ids {
+			if item.ExternalID == id {
+				existing[id] = true
+			}
+		}
+	}
+	return existing, nil
+}
+
+func TestImportStatementTwiceSkipsEveryRow(t *testing.T) {
+	fixtures := []struct {
+		format string
+		name   string
+	}{
+		{importer.FormatOFX, "statement.ofx"},
+		{importer.FormatQFX, "statement.qfx"},
+		{importer.FormatQIF, "statement.qif"},
+		{importer.FormatCAMT053, "camt053.xml"},
+		{importer.FormatClientBank, "clientbank.txt"},
+	}
+	ctx := domain.WithPrincipal(context.Background(), domain.SystemPrincipal)
+	logger := zerolog.Nop()
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			repo := &importRepo{}
+			s := NewService(repo, Options{}, &logger)
+			parse := func() []*domain.ImportRow {
+				file, err := os.Open(filepath.Join("..", "..", "importer", "testdata", f.name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer file.Close()
+				rows, err := importer.ParseStatement(file, f.format, "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				return rows
+			}
+
+			first, err := s.ImportItems(ctx, parse(), &domain.ImportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.Imported == 0 {
+				t.Fatal("first import loaded nothing")
+			}
+
+			second, err := s.ImportItems(ctx, parse(), &domain.ImportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if second.Imported != 0 {
+				t.Errorf("second import loaded %d rows, want 0", second.Imported)
+			}
+			existing := 0
+			for _, skip := range second.Skipped {
+				if skip.Reason == domain.ImportSkipExisting {
+					existing++
+				}
+			}
+			if existing != first.Imported {
+				t.Errorf("second import skipped %d rows as already imported, want %d", existing, first.Imported)
+			}
+			if len(repo.items) != first.Imported {
+				t.Errorf("repository holds %d items, want %d", len(repo.items), first.Imported)
+			}
+		})
+	}
+}
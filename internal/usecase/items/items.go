@@ -6,34 +6,69 @@ import (
 	"fmt"
 	"sales-tracker/internal/domain"
 	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/wb-go/wbf/zlog"
 )
 
+type Options struct {
+	// IdempotencyTTL — сколько хранится ключ идемпотентности создания записи.
+	IdempotencyTTL time.Duration
+	// TrashRetention — сколько удалённая запись хранится в корзине.
+	TrashRetention time.Duration
+	// PurgeInterval — как часто корзина очищается от просроченных записей.
+	PurgeInterval time.Duration
+}
+
 type Service struct {
 	repo     itemsRepository
+	opts     Options
 	logger   *zlog.Zerolog
 	validate *validator.Validate
 }
 
-func NewService(repo itemsRepository, logger *zlog.Zerolog) *Service {
+func NewService(repo itemsRepository, opts Options, logger *zlog.Zerolog) *Service {
 	return &Service{
 		repo:     repo,
+		opts:     opts,
 		logger:   logger,
 		validate: validator.New(),
 	}
 }
 
-func (s *Service) CreateItem(ctx context.Context, item *domain.Item) (int64, error) {
+func normalizeItem(item *domain.Item) {
+	item.Currency = strings.ToUpper(strings.TrimSpace(item.Currency))
+	if item.Currency == "" {
+		item.Currency = domain.BaseCurrency
+	}
+}
+
+// validateItem — правила, общие для создания, изменения и импорта записей.
+func (s *Service) validateItem(item *domain.Item) error {
+	normalizeItem(item)
 	if err := s.validate.Struct(item); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	return item.ValidateAccounts()
+}
+
+func (s *Service) CreateItem(ctx context.Context, item *domain.Item) (int64, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return 0, err
+	}
+	if err := s.validateItem(item); err != nil {
 		s.logger.Error().Err(err).Msg("Validation failed")
-		return 0, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+		return 0, err
 	}
 	s.logger.Info().Msg("Creating item")
 	id, err := s.repo.CreateItem(ctx, item)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Failed to create item")
+		if errors.Is(err, customErr.ErrInvalidInput) {
+			return 0, err
+		}
 		if errors.Is(err, customErr.ErrDatabase) {
 			return 0, customErr.ErrDatabase
 		}
@@ -44,6 +79,9 @@ func (s *Service) CreateItem(ctx context.Context, item *domain.Item) (int64, err
 }
 
 func (s *Service) GetItems(ctx context.Context) ([]*domain.Item, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsRead); err != nil {
+		return nil, err
+	}
 	s.logger.Info().Msg("Getting items")
 	items, err := s.repo.GetItems(ctx)
 	if err != nil {
@@ -57,9 +95,16 @@ func (s *Service) GetItems(ctx context.Context) ([]*domain.Item, error) {
 	return items, nil
 }
 
-func (s *Service) GetItemsWithPagination(ctx context.Context, offset, limit int) ([]*domain.Item, int64, error) {
+func (s *Service) GetItemsWithPagination(ctx context.Context, filter *domain.ItemsFilter, offset, limit int) ([]*domain.Item, int64, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsRead); err != nil {
+		return nil, 0, err
+	}
+	if err := s.validateFilter(filter); err != nil {
+		s.logger.Error().Err(err).Msg("Filter validation failed")
+		return nil, 0, err
+	}
 	s.logger.Info().Int("offset", offset).Int("limit", limit).Msg("Getting items with pagination")
-	items, total, err := s.repo.GetItemsWithPagination(ctx, offset, limit)
+	items, total, err := s.repo.GetItemsWithPagination(ctx, filter, offset, limit)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Failed to get items with pagination")
 		if errors.Is(err, customErr.ErrDatabase) {
@@ -71,7 +116,54 @@ func (s *Service) GetItemsWithPagination(ctx context.Context, offset, limit int)
 	return items, total, nil
 }
 
+func (s *Service) GetItemsByCursor(ctx context.Context, filter *domain.ItemsFilter, cursor *domain.ItemsCursor, limit int, withTotal bool) (*domain.ItemsPage, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsRead); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, customErr.ErrInvalidInput
+	}
+	if err := s.validateFilter(filter); err != nil {
+		s.logger.Error().Err(err).Msg("Filter validation failed")
+		return nil, err
+	}
+	if filter != nil && filter.SortBy != "" && filter.SortBy != "date" {
+		return nil, fmt.Errorf("%w: cursor pagination supports only sort by date", customErr.ErrInvalidInput)
+	}
+	s.logger.Info().Int("limit", limit).Bool("with_total", withTotal).Msg("Getting items by cursor")
+	page, err := s.repo.GetItemsByCursor(ctx, filter, cursor, limit, withTotal)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get items by cursor")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	s.logger.Info().Int("count", len(page.Items)).Msg("Items retrieved by cursor")
+	return page, nil
+}
+
+func (s *Service) validateFilter(filter *domain.ItemsFilter) error {
+	if filter == nil {
+		return nil
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	if err := s.validate.Struct(filter); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return customErr.ErrInvalidDateRange
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return fmt.Errorf("%w: min_amount is greater than max_amount", customErr.ErrInvalidAmount)
+	}
+	return nil
+}
+
 func (s *Service) GetItemByID(ctx context.Context, id int64) (*domain.Item, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsRead); err != nil {
+		return nil, err
+	}
 	if id <= 0 {
 		return nil, customErr.ErrInvalidInput
 	}
@@ -92,43 +184,86 @@ func (s *Service) GetItemByID(ctx context.Context, id int64) (*domain.Item, erro
 	return item, nil
 }
 
-func (s *Service) UpdateItem(ctx context.Context, id int64, item *domain.Item) error {
-	if id <= 0 {
+// UpdateItem полностью заменяет операцию. version > 0 включает оптимистичную
+// блокировку: если операцию успели изменить, возвращается ErrVersionMismatch.
+func (s *Service) UpdateItem(ctx context.Context, id int64, item *domain.Item, version int64) error {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return err
+	}
+	if id <= 0 || version < 0 {
 		return customErr.ErrInvalidInput
 	}
 
-	if err := s.validate.Struct(item); err != nil {
+	if err := s.validateItem(item); err != nil {
 		s.logger.Error().Err(err).Msg("Validation failed")
-		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+		return err
 	}
 	s.logger.Info().Int64("id", id).Msg("Updating item")
-	err := s.repo.UpdateItem(ctx, id, item)
+	err := s.repo.UpdateItem(ctx, id, item, version)
 	if err != nil {
 		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to update item")
 		if errors.Is(err, customErr.ErrItemNotFound) {
 			return customErr.ErrItemNotFound
 		}
+		if errors.Is(err, customErr.ErrVersionMismatch) {
+			return customErr.ErrVersionMismatch
+		}
+		if errors.Is(err, customErr.ErrInvalidInput) {
+			return err
+		}
 		if errors.Is(err, customErr.ErrDatabase) {
 			return customErr.ErrDatabase
 		}
 		return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
 	}
-	s.logger.Info().Int64("id", id).Msg("Item updated")
+	s.logger.Info().Int64("id", id).Int64("version", item.Version).Msg("Item updated")
 	return nil
 }
 
-func (s *Service) DeleteItem(ctx context.Context, id int64) error {
-	if id <= 0 {
+// PatchItem применяет частичное изменение к текущему состоянию операции.
+// Запись выполняется условно по прочитанной версии, поэтому параллельная
+// правка между чтением и записью не теряется, а приводит к ErrVersionMismatch.
+func (s *Service) PatchItem(ctx context.Context, id int64, patch *domain.ItemPatch, version int64) (*domain.Item, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsWrite); err != nil {
+		return nil, err
+	}
+	if id <= 0 || version < 0 || patch == nil {
+		return nil, customErr.ErrInvalidInput
+	}
+
+	item, err := s.GetItemByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != item.Version {
+		s.logger.Warn().Int64("id", id).Int64("expected", version).Int64("version", item.Version).Msg("Stale item version")
+		return nil, customErr.ErrVersionMismatch
+	}
+	patch.Apply(item)
+	if err := s.UpdateItem(ctx, id, item, item.Version); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *Service) DeleteItem(ctx context.Context, id int64, version int64) error {
+	if err := domain.Authorize(ctx, domain.PermItemsDelete); err != nil {
+		return err
+	}
+	if id <= 0 || version < 0 {
 		return customErr.ErrInvalidInput
 	}
 
 	s.logger.Info().Int64("id", id).Msg("Deleting item")
-	err := s.repo.DeleteItem(ctx, id)
+	err := s.repo.DeleteItem(ctx, id, version)
 	if err != nil {
 		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to delete item")
 		if errors.Is(err, customErr.ErrItemNotFound) {
 			return customErr.ErrItemNotFound
 		}
+		if errors.Is(err, customErr.ErrVersionMismatch) {
+			return customErr.ErrVersionMismatch
+		}
 		if errors.Is(err, customErr.ErrDatabase) {
 			return customErr.ErrDatabase
 		}
@@ -0,0 +1,81 @@
+package items_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"time"
+)
+
+func (s *Service) GetTrash(ctx context.Context, offset, limit int) ([]*domain.Item, int64, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsDelete); err != nil {
+		return nil, 0, err
+	}
+	if offset < 0 || limit <= 0 {
+		return nil, 0, customErr.ErrInvalidInput
+	}
+	items, total, err := s.repo.GetTrash(ctx, offset, limit)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get trash")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, 0, customErr.ErrDatabase
+		}
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	return items, total, nil
+}
+
+func (s *Service) RestoreItem(ctx context.Context, id int64) (*domain.Item, error) {
+	if err := domain.Authorize(ctx, domain.PermItemsDelete); err != nil {
+		return nil, err
+	}
+	if id <= 0 {
+		return nil, customErr.ErrInvalidInput
+	}
+	item, err := s.repo.RestoreItem(ctx, id)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to restore item")
+		if errors.Is(err, customErr.ErrItemNotFound) {
+			return nil, customErr.ErrItemNotFound
+		}
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	s.logger.Info().Int64("id", id).Msg("Item restored")
+	return item, nil
+}
+
+// Run периодически удаляет из корзины записи старше TrashRetention.
+// Блокируется до отмены ctx. Очистка обслуживает все пространства, которые
+// открывает ctx (domain.WithAllWorkspaces).
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.PurgeInterval)
+	defer ticker.Stop()
+	s.logger.Info().Dur("retention", s.opts.TrashRetention).Msg("Trash purge started")
+	for {
+		s.purge(ctx)
+		select {
+		case <-ctx.Done():
+			s.logger.Info().Msg("Trash purge stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) purge(ctx context.Context) {
+	n, err := s.repo.PurgeDeleted(ctx, time.Now().Add(-s.opts.TrashRetention))
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error().Err(err).Msg("Failed to purge trash")
+		}
+		return
+	}
+	if n > 0 {
+		s.logger.Info().Int64("count", n).Msg("Trash purged")
+	}
+}
@@ -0,0 +1,21 @@
+package ledger_usecase
+
+import (
+	"context"
+	"sales-tracker/internal/domain"
+	"time"
+)
+
+type ledgerRepository interface {
+	LedgerEnabled(ctx context.Context) (bool, error)
+	EnableLedger(ctx context.Context) error
+	ListLedgerAccounts(ctx context.Context) ([]*domain.LedgerAccount, error)
+	GetLedgerAccount(ctx context.Context, id int64) (*domain.LedgerAccount, error)
+	CreateLedgerAccount(ctx context.Context, account *domain.LedgerAccount) error
+	DeleteLedgerAccount(ctx context.Context, id int64) error
+	CreateJournalEntry(ctx context.Context, entry *domain.JournalEntry) error
+	ListJournalEntries(ctx context.Context, filter *domain.JournalFilter) ([]*domain.JournalEntry, int64, error)
+	DeleteJournalEntry(ctx context.Context, id int64) error
+	GetTrialBalance(ctx context.Context, to *time.Time) ([]*domain.TrialBalanceRow, error)
+	GetProfitAndLoss(ctx context.Context, params *domain.ProfitAndLossParams) ([]*domain.LedgerLine, *domain.LedgerReconciliation, error)
+}
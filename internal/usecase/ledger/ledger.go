@@ -0,0 +1,336 @@
+package ledger_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// moneyAccountCodePrefix — префикс кодов счетов, которые БД заводит для
+// денежных счетов (1000.<id>); вручную такие коды создавать нельзя.
+const moneyAccountCodePrefix = "1000."
+
+// maxReportPeriod — наибольший период отчёта о прибылях и убытках, как у аналитики.
+const maxReportPeriod = 365 * 24 * time.Hour
+
+type Service struct {
+	repo     ledgerRepository
+	logger   *zlog.Zerolog
+	validate *validator.Validate
+}
+
+func NewService(repo ledgerRepository, logger *zlog.Zerolog) *Service {
+	return &Service{
+		repo:     repo,
+		logger:   logger,
+		validate: validator.New(),
+	}
+}
+
+// authorize проверяет право perm и то, что двойная запись включена
+// в пространстве запроса.
+func (s *Service) authorize(ctx context.Context, perm domain.Permission) error {
+	if err := domain.Authorize(ctx, perm); err != nil {
+		return err
+	}
+	enabled, err := s.repo.LedgerEnabled(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to check ledger mode")
+		return ledgerErr(err)
+	}
+	if !enabled {
+		return customErr.ErrLedgerDisabled
+	}
+	return nil
+}
+
+// EnableLedger включает двойную запись в пространстве и проводит его
+// начальные остатки и действующие операции.
+func (s *Service) EnableLedger(ctx context.Context) error {
+	if err := domain.Authorize(ctx, domain.PermLedgerManage); err != nil {
+		return err
+	}
+	s.logger.Info().Msg("Enabling ledger")
+	if err := s.repo.EnableLedger(ctx); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to enable ledger")
+		return ledgerErr(err)
+	}
+	s.logger.Info().Msg("Ledger enabled")
+	return nil
+}
+
+func (s *Service) ListAccounts(ctx context.Context) ([]*domain.LedgerAccount, error) {
+	if err := s.authorize(ctx, domain.PermLedgerRead); err != nil {
+		return nil, err
+	}
+	accounts, err := s.repo.ListLedgerAccounts(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to list ledger accounts")
+		return nil, ledgerErr(err)
+	}
+	return accounts, nil
+}
+
+// CreateAccount добавляет счёт в план счетов. Счёт доходов или расходов
+// с категорией забирает себе операции этой категории.
+func (s *Service) CreateAccount(ctx context.Context, account *domain.LedgerAccount) error {
+	if err := s.authorize(ctx, domain.PermLedgerWrite); err != nil {
+		return err
+	}
+	account.Code = strings.TrimSpace(account.Code)
+	account.Name = strings.TrimSpace(account.Name)
+	account.Category = strings.TrimSpace(account.Category)
+	account.System, account.AccountID = "", nil
+	if err := s.validate.Struct(account); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if strings.HasPrefix(account.Code, moneyAccountCodePrefix) {
+		return fmt.Errorf("%w: codes %s<n> are reserved for money accounts", customErr.ErrInvalidInput, moneyAccountCodePrefix)
+	}
+	if account.Category != "" && account.Type != domain.LedgerIncome && account.Type != domain.LedgerExpense {
+		return fmt.Errorf("%w: category is allowed only for income and expense accounts", customErr.ErrInvalidInput)
+	}
+
+	s.logger.Info().Str("code", account.Code).Msg("Creating ledger account")
+	if err := s.repo.CreateLedgerAccount(ctx, account); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to create ledger account")
+		return ledgerErr(err)
+	}
+	s.logger.Info().Int64("id", account.ID).Msg("Ledger account created")
+	return nil
+}
+
+// DeleteAccount удаляет счёт без проводок. Системные счета и счета
+// денежных счетов ведёт БД, их удалить нельзя.
+func (s *Service) DeleteAccount(ctx context.Context, id int64) error {
+	if err := s.authorize(ctx, domain.PermLedgerWrite); err != nil {
+		return err
+	}
+	if id <= 0 {
+		return customErr.ErrInvalidInput
+	}
+	account, err := s.repo.GetLedgerAccount(ctx, id)
+	if err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to get ledger account")
+		return ledgerErr(err)
+	}
+	if account.System != "" || account.AccountID != nil {
+		return fmt.Errorf("%w: ledger account %s is maintained automatically", customErr.ErrLedgerAccountInUse, account.Code)
+	}
+	s.logger.Info().Int64("id", id).Msg("Deleting ledger account")
+	if err := s.repo.DeleteLedgerAccount(ctx, id); err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to delete ledger account")
+		return ledgerErr(err)
+	}
+	return nil
+}
+
+// CreateEntry записывает ручную проводку: корректировки, обязательства
+// и прочие операции, которых нет среди операций /items.
+func (s *Service) CreateEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	if err := s.authorize(ctx, domain.PermLedgerWrite); err != nil {
+		return err
+	}
+	entry.Currency = strings.ToUpper(strings.TrimSpace(entry.Currency))
+	if entry.Currency == "" {
+		entry.Currency = domain.BaseCurrency
+	}
+	entry.Source, entry.ItemID, entry.AccountID = domain.JournalSourceManual, nil, nil
+	if err := s.validate.Struct(entry); err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if err := entry.ValidateBalance(); err != nil {
+		return err
+	}
+
+	s.logger.Info().Time("date", entry.Date).Int("postings", len(entry.Postings)).Msg("Creating journal entry")
+	if err := s.repo.CreateJournalEntry(ctx, entry); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to create journal entry")
+		return ledgerErr(err)
+	}
+	s.logger.Info().Int64("id", entry.ID).Msg("Journal entry created")
+	return nil
+}
+
+func (s *Service) ListEntries(ctx context.Context, filter *domain.JournalFilter) ([]*domain.JournalEntry, int64, error) {
+	if err := s.authorize(ctx, domain.PermLedgerRead); err != nil {
+		return nil, 0, err
+	}
+	if err := s.validate.Struct(filter); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, 0, customErr.ErrInvalidDateRange
+	}
+	entries, total, err := s.repo.ListJournalEntries(ctx, filter)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to list journal entries")
+		return nil, 0, ledgerErr(err)
+	}
+	return entries, total, nil
+}
+
+// DeleteEntry удаляет ручную проводку.
+func (s *Service) DeleteEntry(ctx context.Context, id int64) error {
+	if err := s.authorize(ctx, domain.PermLedgerWrite); err != nil {
+		return err
+	}
+	if id <= 0 {
+		return customErr.ErrInvalidInput
+	}
+	s.logger.Info().Int64("id", id).Msg("Deleting journal entry")
+	if err := s.repo.DeleteJournalEntry(ctx, id); err != nil {
+		s.logger.Error().Err(err).Int64("id", id).Msg("Failed to delete journal entry")
+		return ledgerErr(err)
+	}
+	return nil
+}
+
+// GetTrialBalance строит оборотно-сальдовые ведомости на дату to, по одной
+// на каждую валюту проводок.
+func (s *Service) GetTrialBalance(ctx context.Context, to *time.Time) ([]*domain.TrialBalance, error) {
+	if err := s.authorize(ctx, domain.PermLedgerRead); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.GetTrialBalance(ctx, to)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get trial balance")
+		return nil, ledgerErr(err)
+	}
+	balances := []*domain.TrialBalance{}
+	for _, row := range rows {
+		if len(balances) == 0 || balances[len(balances)-1].Currency != row.Currency {
+			balances = append(balances, &domain.TrialBalance{Currency: row.Currency, Rows: []*domain.TrialBalanceRow{}})
+		}
+		tb := balances[len(balances)-1]
+		tb.Rows = append(tb.Rows, row)
+		tb.TotalDebit += row.Debit
+		tb.TotalCredit += row.Credit
+	}
+	return balances, nil
+}
+
+// GetBalanceSheet строит балансы на дату to по валютам. Сальдо счетов
+// доходов и расходов попадает в капитал строкой RetainedEarnings.
+func (s *Service) GetBalanceSheet(ctx context.Context, to *time.Time) ([]*domain.BalanceSheet, error) {
+	balances, err := s.GetTrialBalance(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	sheets := make([]*domain.BalanceSheet, len(balances))
+	for i, tb := range balances {
+		sheet := &domain.BalanceSheet{
+			Currency:    tb.Currency,
+			Assets:      []*domain.LedgerLine{},
+			Liabilities: []*domain.LedgerLine{},
+			Equity:      []*domain.LedgerLine{},
+		}
+		for _, row := range tb.Rows {
+			line := &domain.LedgerLine{Account: row.Account, Amount: row.Credit - row.Debit}
+			switch row.Account.Type {
+			case domain.LedgerAsset:
+				line.Amount = -line.Amount
+				sheet.Assets = append(sheet.Assets, line)
+				sheet.TotalAssets += line.Amount
+			case domain.LedgerLiability:
+				sheet.Liabilities = append(sheet.Liabilities, line)
+				sheet.TotalLiabilities += line.Amount
+			case domain.LedgerEquity:
+				sheet.Equity = append(sheet.Equity, line)
+				sheet.TotalEquity += line.Amount
+			default:
+				sheet.RetainedEarnings += line.Amount
+			}
+		}
+		sheet.TotalEquity += sheet.RetainedEarnings
+		sheets[i] = sheet
+	}
+	return sheets, nil
+}
+
+// GetProfitAndLoss строит отчёт о прибылях и убытках за период и сверяет
+// его с операциями за тот же период и в той же валюте — с теми же суммами,
+// что показывает /analytics.
+func (s *Service) GetProfitAndLoss(ctx context.Context, params *domain.ProfitAndLossParams) (*domain.ProfitAndLoss, error) {
+	if err := s.authorize(ctx, domain.PermLedgerRead); err != nil {
+		return nil, err
+	}
+	params.Currency = strings.ToUpper(params.Currency)
+	if params.Currency == "" {
+		params.Currency = domain.BaseCurrency
+	}
+	if err := s.validate.Struct(params); err != nil {
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+	if params.From.IsZero() || params.To.IsZero() {
+		return nil, customErr.ErrMissingParameter
+	}
+	if params.From.After(params.To) {
+		return nil, customErr.ErrInvalidDateRange
+	}
+	if params.To.Sub(params.From) > maxReportPeriod {
+		return nil, customErr.ErrPeriodTooLarge
+	}
+
+	s.logger.Info().Time("from", params.From).Time("to", params.To).Str("currency", params.Currency).Msg("Getting profit and loss")
+	lines, rec, err := s.repo.GetProfitAndLoss(ctx, params)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get profit and loss")
+		if errors.Is(err, customErr.ErrRateNotFound) {
+			return nil, err
+		}
+		return nil, ledgerErr(err)
+	}
+
+	pl := &domain.ProfitAndLoss{
+		Currency:       params.Currency,
+		From:           params.From,
+		To:             params.To,
+		Income:         []*domain.LedgerLine{},
+		Expense:        []*domain.LedgerLine{},
+		Reconciliation: rec,
+	}
+	for _, line := range lines {
+		if line.Account.Type == domain.LedgerIncome {
+			pl.Income = append(pl.Income, line)
+			pl.TotalIncome += line.Amount
+		} else {
+			pl.Expense = append(pl.Expense, line)
+			pl.TotalExpense += line.Amount
+		}
+	}
+	rec.IncomeDifference = pl.TotalIncome - rec.ManualIncome - rec.AnalyticsIncome
+	rec.ExpenseDifference = pl.TotalExpense - rec.ManualExpense - rec.AnalyticsExpense
+	if !rec.Reconciled() {
+		s.logger.Warn().
+			Str("income_difference", rec.IncomeDifference.String()).
+			Str("expense_difference", rec.ExpenseDifference.String()).
+			Msg("Profit and loss does not reconcile with analytics")
+	}
+	return pl, nil
+}
+
+// ledgerErr сводит ошибку репозитория к бизнес-ошибкам сервиса.
+func ledgerErr(err error) error {
+	for _, known := range []error{
+		customErr.ErrLedgerAccountNotFound,
+		customErr.ErrJournalEntryNotFound,
+		customErr.ErrLedgerAccountInUse,
+		customErr.ErrDatabase,
+	} {
+		if errors.Is(err, known) {
+			return known
+		}
+	}
+	if errors.Is(err, customErr.ErrLedgerAccountExists) || errors.Is(err, customErr.ErrInvalidInput) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+}
@@ -0,0 +1,203 @@
+package ledger_usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sales-tracker/internal/domain"
+
+	"github.com/rs/zerolog"
+)
+
+// reportRepo отдаёт заранее заданные обороты; остальные методы
+// репозитория в тестах отчётов не вызываются.
+type reportRepo struct {
+	ledgerRepository
+	trial  []*domain.TrialBalanceRow
+	lines  []*domain.LedgerLine
+	rec    *domain.LedgerReconciliation
+	params *domain.ProfitAndLossParams
+}
+
+func (r *reportRepo) LedgerEnabled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (r *reportRepo) GetTrialBalance(context.Context, *time.Time) ([]*domain.TrialBalanceRow, error) {
+	return r.trial, nil
+}
+
+func (r *reportRepo) GetProfitAndLoss(_ context.Context, params *domain.ProfitAndLossParams) ([]*domain.LedgerLine, *domain.LedgerReconciliation, error) {
+	r.params = params
+	return r.lines, r.rec, nil
+}
+
+func newTestService(repo ledgerRepository) (*Service, context.Context) {
+	logger := zerolog.Nop()
+	return NewService(repo, &logger), domain.WithPrincipal(context.Background(), domain.SystemPrincipal)
+}
+
+func account(code, typ string) *domain.LedgerAccount {
+	return &domain.LedgerAccount{Code: code, Name: code, Type: typ}
+}
+
+func TestGetBalanceSheet(t *testing.T) {
+	cash := account("1000.1", domain.LedgerAsset)
+	loan := account("2000", domain.LedgerLiability)
+	capital := account("3000", domain.LedgerEquity)
+	salary := account("4000", domain.LedgerIncome)
+	food := account("5000", domain.LedgerExpense)
+	cashUSD := account("1000.2", domain.LedgerAsset)
+
+	// Начальный остаток 1000, доход 500, расход 200 и заём 300 в рублях;
+	// начальный остаток 100 в долларах.
+	repo := &reportRepo{trial: []*domain.TrialBalanceRow{
+		{Account: cash, Currency: "RUB", Debit: 180000, Credit: 20000},
+		{Account: loan, Currency: "RUB", Credit: 30000},
+		{Account: capital, Currency: "RUB", Credit: 100000},
+		{Account: salary, Currency: "RUB", Credit: 50000},
+		{Account: food, Currency: "RUB", Debit: 20000},
+		{Account: cashUSD, Currency: "USD", Debit: 10000},
+		{Account: capital, Currency: "USD", Credit: 10000},
+	}}
+	s, ctx := newTestService(repo)
+
+	sheets, err := s.GetBalanceSheet(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetBalanceSheet: %v", err)
+	}
+	if len(sheets) != 2 {
+		t.Fatalf("got %d sheets, want 2", len(sheets))
+	}
+
+	rub := sheets[0]
+	if rub.Currency != "RUB" {
+		t.Fatalf("sheets[0].Currency = %s, want RUB", rub.Currency)
+	}
+	// Сальдо активов дебетовое: в балансе оно положительно.
+	if len(rub.Assets) != 1 || rub.Assets[0].Amount != 160000 {
+		t.Errorf("assets = %+v, want cash 1600.00", rub.Assets)
+	}
+	if rub.TotalAssets != 160000 {
+		t.Errorf("TotalAssets = %s, want 1600.00", rub.TotalAssets)
+	}
+	if len(rub.Liabilities) != 1 || rub.TotalLiabilities != 30000 {
+		t.Errorf("liabilities = %+v, total %s, want loan 300.00", rub.Liabilities, rub.TotalLiabilities)
+	}
+	// Доходы и расходы не выводятся строками, а сворачиваются в
+	// нераспределённую прибыль: 500 - 200.
+	if len(rub.Equity) != 1 || rub.Equity[0].Amount != 100000 {
+		t.Errorf("equity = %+v, want capital 1000.00", rub.Equity)
+	}
+	if rub.RetainedEarnings != 30000 {
+		t.Errorf("RetainedEarnings = %s, want 300.00", rub.RetainedEarnings)
+	}
+	if rub.TotalEquity != 130000 {
+		t.Errorf("TotalEquity = %s, want 1300.00", rub.TotalEquity)
+	}
+	if !rub.Balanced() {
+		t.Errorf("RUB sheet is not balanced: %s != %s + %s", rub.TotalAssets, rub.TotalLiabilities, rub.TotalEquity)
+	}
+
+	usd := sheets[1]
+	if usd.Currency != "USD" || usd.TotalAssets != 10000 || usd.TotalEquity != 10000 || usd.RetainedEarnings != 0 {
+		t.Errorf("USD sheet = %+v", usd)
+	}
+}
+
+func TestGetBalanceSheetLoss(t *testing.T) {
+	cash := account("1000.1", domain.LedgerAsset)
+	capital := account("3000", domain.LedgerEquity)
+	food := account("5000", domain.LedgerExpense)
+
+	// Расходы больше доходов: нераспределённая прибыль отрицательна,
+	// актив уходит в кредит и в балансе становится отрицательным.
+	repo := &reportRepo{trial: []*domain.TrialBalanceRow{
+		{Account: cash, Currency: "RUB", Debit: 10000, Credit: 25000},
+		{Account: capital, Currency: "RUB", Credit: 10000},
+		{Account: food, Currency: "RUB", Debit: 25000},
+	}}
+	s, ctx := newTestService(repo)
+
+	sheets, err := s.GetBalanceSheet(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetBalanceSheet: %v", err)
+	}
+	sheet := sheets[0]
+	if sheet.TotalAssets != -15000 {
+		t.Errorf("TotalAssets = %s, want -150.00", sheet.TotalAssets)
+	}
+	if sheet.RetainedEarnings != -25000 {
+		t.Errorf("RetainedEarnings = %s, want -250.00", sheet.RetainedEarnings)
+	}
+	if sheet.TotalEquity != -15000 || !sheet.Balanced() {
+		t.Errorf("TotalEquity = %s, balanced %v", sheet.TotalEquity, sheet.Balanced())
+	}
+}
+
+func TestGetProfitAndLoss(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		rec             domain.LedgerReconciliation
+		wantIncomeDiff  domain.Money
+		wantExpenseDiff domain.Money
+		wantReconciled  bool
+	}{
+		{
+			name:           "reconciled",
+			rec:            domain.LedgerReconciliation{AnalyticsIncome: 50000, ManualIncome: 5000, AnalyticsExpense: 20000},
+			wantReconciled: true,
+		},
+		{
+			// В учёте расходов на 20.00 больше, чем в аналитике, а доходов
+			// на 50.00 меньше: разница — учёт минус ручные проводки минус аналитика.
+			name:            "differences",
+			rec:             domain.LedgerReconciliation{AnalyticsIncome: 55000, ManualIncome: 5000, AnalyticsExpense: 18000},
+			wantIncomeDiff:  -5000,
+			wantExpenseDiff: 2000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := tt.rec
+			repo := &reportRepo{
+				lines: []*domain.LedgerLine{
+					{Account: account("4000", domain.LedgerIncome), Amount: 50000},
+					{Account: account("4100", domain.LedgerIncome), Amount: 5000},
+					{Account: account("5000", domain.LedgerExpense), Amount: 22000},
+					// Возврат расхода: сальдо кредитовое, строка уменьшает расходы.
+					{Account: account("5100", domain.LedgerExpense), Amount: -2000},
+				},
+				rec: &rec,
+			}
+			s, ctx := newTestService(repo)
+
+			pl, err := s.GetProfitAndLoss(ctx, &domain.ProfitAndLossParams{From: from, To: to, Currency: "rub"})
+			if err != nil {
+				t.Fatalf("GetProfitAndLoss: %v", err)
+			}
+			if repo.params.Currency != "RUB" {
+				t.Errorf("repository currency = %s, want RUB", repo.params.Currency)
+			}
+			if len(pl.Income) != 2 || len(pl.Expense) != 2 {
+				t.Errorf("got %d income and %d expense lines, want 2 and 2", len(pl.Income), len(pl.Expense))
+			}
+			if pl.TotalIncome != 55000 || pl.TotalExpense != 20000 || pl.NetIncome() != 35000 {
+				t.Errorf("totals: income %s, expense %s, net %s; want 550.00, 200.00, 350.00",
+					pl.TotalIncome, pl.TotalExpense, pl.NetIncome())
+			}
+			got := pl.Reconciliation
+			if got.IncomeDifference != tt.wantIncomeDiff || got.ExpenseDifference != tt.wantExpenseDiff {
+				t.Errorf("differences: income %s, expense %s; want %s, %s",
+					got.IncomeDifference, got.ExpenseDifference, tt.wantIncomeDiff, tt.wantExpenseDiff)
+			}
+			if got.Reconciled() != tt.wantReconciled {
+				t.Errorf("Reconciled() = %v, want %v", got.Reconciled(), tt.wantReconciled)
+			}
+		})
+	}
+}
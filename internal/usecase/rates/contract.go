@@ -0,0 +1,12 @@
+package rates_usecase
+
+import (
+	"context"
+	"sales-tracker/internal/domain"
+	"time"
+)
+
+type ratesRepository interface {
+	UpsertRates(ctx context.Context, rates []*domain.ExchangeRate) error
+	GetRates(ctx context.Context, currency string, from, to time.Time) ([]*domain.ExchangeRate, error)
+}
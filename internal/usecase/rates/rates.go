@@ -0,0 +1,90 @@
+package rates_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type Service struct {
+	repo     ratesRepository
+	logger   *zlog.Zerolog
+	validate *validator.Validate
+}
+
+func NewService(repo ratesRepository, logger *zlog.Zerolog) *Service {
+	return &Service{
+		repo:     repo,
+		logger:   logger,
+		validate: validator.New(),
+	}
+}
+
+func (s *Service) UpsertRates(ctx context.Context, rates []*domain.ExchangeRate) error {
+	if err := domain.Authorize(ctx, domain.PermRatesWrite); err != nil {
+		return err
+	}
+	if len(rates) == 0 {
+		return customErr.ErrMissingParameter
+	}
+	for i, rate := range rates {
+		rate.Currency = strings.ToUpper(rate.Currency)
+		if err := s.validate.Struct(rate); err != nil {
+			s.logger.Error().Err(err).Int("index", i).Msg("Validation failed")
+			return fmt.Errorf("%w: rate #%d: %v", customErr.ErrInvalidInput, i, err)
+		}
+		if rate.Currency == domain.BaseCurrency {
+			return fmt.Errorf("%w: rate #%d: base currency %s has fixed rate 1", customErr.ErrInvalidInput, i, domain.BaseCurrency)
+		}
+		value, ok := new(big.Rat).SetString(rate.Rate)
+		if !ok || value.Sign() <= 0 {
+			return fmt.Errorf("%w: rate #%d: rate must be positive", customErr.ErrInvalidInput, i)
+		}
+	}
+
+	s.logger.Info().Int("count", len(rates)).Msg("Upserting exchange rates")
+	if err := s.repo.UpsertRates(ctx, rates); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to upsert exchange rates")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return customErr.ErrDatabase
+		}
+		return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	s.logger.Info().Int("count", len(rates)).Msg("Exchange rates upserted")
+	return nil
+}
+
+func (s *Service) GetRates(ctx context.Context, currency string, from, to time.Time) ([]*domain.ExchangeRate, error) {
+	if err := domain.Authorize(ctx, domain.PermAnalyticsRead); err != nil {
+		return nil, err
+	}
+	currency = strings.ToUpper(currency)
+	if currency != "" {
+		if err := s.validate.Var(currency, "iso4217"); err != nil {
+			return nil, fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+		}
+	}
+	if from.After(to) {
+		return nil, customErr.ErrInvalidDateRange
+	}
+
+	s.logger.Info().Str("currency", currency).Time("from", from).Time("to", to).Msg("Getting exchange rates")
+	rates, err := s.repo.GetRates(ctx, currency, from, to)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to get exchange rates")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	s.logger.Info().Int("count", len(rates)).Msg("Exchange rates retrieved")
+	return rates, nil
+}
@@ -0,0 +1,31 @@
+package reports_usecase
+
+import (
+	"context"
+	"io"
+	"sales-tracker/internal/domain"
+	"time"
+)
+
+type reportsRepository interface {
+	CreateJob(ctx context.Context, job *domain.ReportJob) error
+	GetJob(ctx context.Context, id string) (*domain.ReportJob, error)
+	ClaimNext(ctx context.Context) (*domain.ReportJob, error)
+	UpdateProgress(ctx context.Context, id string, progress int) error
+	CompleteJob(ctx context.Context, job *domain.ReportJob) error
+	FailJob(ctx context.Context, id string, reason string) error
+	RequeueJob(ctx context.Context, id string) error
+	FailStaleJobs(ctx context.Context, before time.Time) (int64, error)
+	ExpireJobs(ctx context.Context, now time.Time) ([]*domain.ReportJob, error)
+}
+
+type analyticsUsecase interface {
+	GetReportAnalytics(ctx context.Context, params *domain.AnalyticsParams) (*domain.Analytics, error)
+	StreamDetails(ctx context.Context, params *domain.AnalyticsParams, fn func(*domain.Item) error) error
+}
+
+type artifactStorage interface {
+	Create(name string) (io.WriteCloser, string, error)
+	Open(path string) (io.ReadSeekCloser, error)
+	Remove(path string) error
+}
@@ -0,0 +1,156 @@
+package reports_usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sales-tracker/internal/domain"
+	customErr "sales-tracker/internal/domain/errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type Options struct {
+	Workers         int
+	TTL             time.Duration
+	PollInterval    time.Duration
+	CleanupInterval time.Duration
+	// JobTimeout ограничивает время построения отчёта. Задачи, оставшиеся
+	// в статусе running дольше, считаются брошенными и помечаются ошибочными.
+	JobTimeout time.Duration
+}
+
+type Service struct {
+	repo      reportsRepository
+	analytics analyticsUsecase
+	storage   artifactStorage
+	opts      Options
+	logger    *zlog.Zerolog
+	validate  *validator.Validate
+	wake      chan struct{}
+}
+
+func NewService(repo reportsRepository, analytics analyticsUsecase, storage artifactStorage, opts Options, logger *zlog.Zerolog) *Service {
+	return &Service{
+		repo:      repo,
+		analytics: analytics,
+		storage:   storage,
+		opts:      opts,
+		logger:    logger,
+		validate:  validator.New(),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+func (s *Service) CreateReport(ctx context.Context, job *domain.ReportJob) error {
+	if err := domain.Authorize(ctx, domain.PermExport); err != nil {
+		return err
+	}
+	if job.Params == nil {
+		return customErr.ErrMissingParameter
+	}
+	params := job.Params
+	if params.From.IsZero() || params.To.IsZero() {
+		return customErr.ErrMissingParameter
+	}
+	if params.From.After(params.To) {
+		return customErr.ErrInvalidDateRange
+	}
+	params.Currency = strings.ToUpper(params.Currency)
+	if params.Currency == "" {
+		params.Currency = domain.BaseCurrency
+	}
+	if params.Histogram != "" && params.Bins == 0 {
+		params.Bins = 10
+	}
+	if err := s.validate.Struct(job); err != nil {
+		s.logger.Error().Err(err).Msg("Validation failed")
+		return fmt.Errorf("%w: %v", customErr.ErrInvalidInput, err)
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	job.ID = id
+	job.Status = domain.ReportStatusQueued
+	s.logger.Info().Str("id", job.ID).Str("kind", job.Kind).Msg("Creating report job")
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to create report job")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return customErr.ErrDatabase
+		}
+		return fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *Service) GetReport(ctx context.Context, id string) (*domain.ReportJob, error) {
+	if err := domain.Authorize(ctx, domain.PermExport); err != nil {
+		return nil, err
+	}
+	if err := s.validate.Var(id, "uuid"); err != nil {
+		return nil, customErr.ErrReportNotFound
+	}
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, customErr.ErrReportNotFound) {
+			return nil, err
+		}
+		s.logger.Error().Err(err).Str("id", id).Msg("Failed to get report job")
+		if errors.Is(err, customErr.ErrDatabase) {
+			return nil, customErr.ErrDatabase
+		}
+		return nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	// Очистка идёт по расписанию: между запусками отчёт уже может быть просрочен.
+	if job.Status == domain.ReportStatusDone && job.ExpiresAt != nil && !time.Now().Before(*job.ExpiresAt) {
+		job.Status = domain.ReportStatusExpired
+	}
+	return job, nil
+}
+
+// OpenArtifact открывает готовый файл отчёта для скачивания.
+func (s *Service) OpenArtifact(ctx context.Context, id string) (*domain.ReportJob, io.ReadSeekCloser, error) {
+	if err := domain.Authorize(ctx, domain.PermExport); err != nil {
+		return nil, nil, err
+	}
+	job, err := s.GetReport(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch job.Status {
+	case domain.ReportStatusDone:
+	case domain.ReportStatusExpired:
+		return nil, nil, customErr.ErrReportExpired
+	default:
+		return nil, nil, customErr.ErrReportNotReady
+	}
+	f, err := s.storage.Open(job.ArtifactPath)
+	if err != nil {
+		s.logger.Error().Err(err).Str("id", id).Msg("Failed to open report artifact")
+		return nil, nil, fmt.Errorf("%w: %v", customErr.ErrInternal, err)
+	}
+	return job, f, nil
+}
+
+// newJobID генерирует UUID версии 4.
+func newJobID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,226 @@
+package reports_usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sales-tracker/internal/domain"
+	"sales-tracker/internal/export"
+	"sync"
+	"time"
+)
+
+// Run запускает пул воркеров и периодическую очистку просроченных отчётов.
+// Блокируется до отмены ctx и завершения всех воркеров; прерванные
+// остановкой задачи возвращаются в очередь, а брошенные аварийно
+// завершившимся экземпляром помечаются ошибочными при запуске и очистке.
+// Очередь общая для всех пространств, поэтому ctx должен открывать все
+// пространства (domain.WithAllWorkspaces); каждая задача строится в своём.
+func (s *Service) Run(ctx context.Context) {
+	s.failStale(ctx)
+	var wg sync.WaitGroup
+	for i := 0; i < s.opts.Workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			s.work(ctx, worker)
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.cleanup(ctx)
+	}()
+	s.logger.Info().Int("workers", s.opts.Workers).Msg("Report workers started")
+	wg.Wait()
+	s.logger.Info().Msg("Report workers stopped")
+}
+
+func (s *Service) work(ctx context.Context, worker int) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		// Разбираем очередь, пока в ней есть задачи, затем ждём сигнала или тика.
+		for ctx.Err() == nil {
+			job, err := s.repo.ClaimNext(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Error().Err(err).Int("worker", worker).Msg("Failed to claim report job")
+				}
+				break
+			}
+			if job == nil {
+				break
+			}
+			s.process(ctx, worker, job)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) process(ctx context.Context, worker int, job *domain.ReportJob) {
+	log := s.logger.With().Str("id", job.ID).Int64("workspace_id", job.WorkspaceID).Str("kind", job.Kind).Int("worker", worker).Logger()
+	log.Info().Msg("Processing report job")
+
+	buildCtx, cancel := context.WithTimeout(domain.WithWorkspace(ctx, job.WorkspaceID), s.opts.JobTimeout)
+	err := s.build(buildCtx, job)
+	cancel()
+	if err == nil {
+		log.Info().Str("artifact", job.ArtifactPath).Msg("Report job done")
+		return
+	}
+	if ctx.Err() != nil {
+		log.Warn().Err(err).Msg("Report job interrupted, requeueing")
+		if err := s.repo.RequeueJob(domain.WithWorkspace(context.Background(), job.WorkspaceID), job.ID); err != nil {
+			log.Error().Err(err).Msg("Failed to requeue report job")
+		}
+		return
+	}
+	log.Error().Err(err).Msg("Report job failed")
+	if err := s.repo.FailJob(domain.WithWorkspace(context.Background(), job.WorkspaceID), job.ID, err.Error()); err != nil {
+		log.Error().Err(err).Msg("Failed to mark report job as failed")
+	}
+}
+
+func (s *Service) build(ctx context.Context, job *domain.ReportJob) error {
+	params := *job.Params
+	params.SkipDetails = true
+	analytics, err := s.analytics.GetReportAnalytics(ctx, &params)
+	if err != nil {
+		return err
+	}
+	s.setProgress(ctx, job, 50)
+
+	period := fmt.Sprintf("%s_%s", params.From.Format("2006-01-02"), params.To.Format("2006-01-02"))
+	var ext string
+	switch job.Kind {
+	case domain.ReportKindExport:
+		ext = "csv"
+		job.ArtifactName = fmt.Sprintf("sales_tracker_%s.csv", period)
+		job.ContentType = "text/csv; charset=utf-8"
+	default:
+		ext = "json"
+		job.ArtifactName = fmt.Sprintf("analytics_%s.json", period)
+		job.ContentType = "application/json"
+	}
+
+	w, path, err := s.storage.Create(fmt.Sprintf("%s.%s", job.ID, ext))
+	if err != nil {
+		return fmt.Errorf("create artifact: %w", err)
+	}
+	job.ArtifactPath = path
+	if job.Kind == domain.ReportKindExport {
+		err = s.writeExport(ctx, job, w, analytics, &params)
+	} else {
+		err = export.WriteAnalyticsJSON(w, analytics, params.From, params.To)
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.removeArtifact(path)
+		return fmt.Errorf("write artifact: %w", err)
+	}
+
+	finished := time.Now()
+	expires := finished.Add(s.opts.TTL)
+	job.FinishedAt = &finished
+	job.ExpiresAt = &expires
+	if err := s.repo.CompleteJob(ctx, job); err != nil {
+		s.removeArtifact(path)
+		return err
+	}
+	return nil
+}
+
+func (s *Service) writeExport(ctx context.Context, job *domain.ReportJob, w io.Writer, analytics *domain.Analytics, params *domain.AnalyticsParams) error {
+	report, err := export.NewCSVReport(w)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteSummary(analytics, params.From, params.To); err != nil {
+		return err
+	}
+	total := analytics.Income.Count + analytics.Expense.Count
+	step := total/10 + 1
+	var written int64
+	err = s.analytics.StreamDetails(ctx, params, func(item *domain.Item) error {
+		if err := report.WriteItem(item); err != nil {
+			return err
+		}
+		written++
+		if written%step == 0 && written <= total {
+			s.setProgress(ctx, job, 50+int(45*written/total))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return report.Close()
+}
+
+func (s *Service) setProgress(ctx context.Context, job *domain.ReportJob, progress int) {
+	job.Progress = progress
+	if err := s.repo.UpdateProgress(ctx, job.ID, progress); err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Warn().Err(err).Str("id", job.ID).Msg("Failed to update report progress")
+	}
+}
+
+func (s *Service) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		s.expire(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.failStale(ctx)
+	}
+}
+
+func (s *Service) failStale(ctx context.Context) {
+	n, err := s.repo.FailStaleJobs(ctx, time.Now().Add(-s.opts.JobTimeout))
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error().Err(err).Msg("Failed to recover stale report jobs")
+		}
+		return
+	}
+	if n > 0 {
+		s.logger.Warn().Int64("count", n).Msg("Stale report jobs marked as failed")
+	}
+}
+
+func (s *Service) expire(ctx context.Context) {
+	jobs, err := s.repo.ExpireJobs(ctx, time.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error().Err(err).Msg("Failed to expire reports")
+		}
+		return
+	}
+	for _, job := range jobs {
+		s.removeArtifact(job.ArtifactPath)
+	}
+	if len(jobs) > 0 {
+		s.logger.Info().Int("count", len(jobs)).Msg("Expired reports removed")
+	}
+}
+
+func (s *Service) removeArtifact(path string) {
+	if path == "" {
+		return
+	}
+	if err := s.storage.Remove(path); err != nil {
+		s.logger.Warn().Err(err).Str("path", path).Msg("Failed to remove report artifact")
+	}
+}
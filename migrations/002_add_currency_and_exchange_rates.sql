@@ -0,0 +1,36 @@
+-- +goose Up
+ALTER TABLE items ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) NOT NULL,
+    date DATE NOT NULL,
+    rate NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (currency, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_items_currency ON items (currency);
+
+-- Курс валюты к базовой (RUB) на дату: берётся последний известный курс не позже даты.
+-- Для базовой валюты курс всегда равен 1.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION exchange_rate_on(cur CHAR(3), at TIMESTAMPTZ) RETURNS NUMERIC AS $$
+    SELECT CASE
+        WHEN cur = 'RUB' THEN 1::numeric
+        ELSE (
+            SELECT rate
+            FROM exchange_rates
+            WHERE currency = cur AND date <= (at AT TIME ZONE 'UTC')::date
+            ORDER BY date DESC
+            LIMIT 1
+        )
+    END
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS exchange_rate_on(CHAR(3), TIMESTAMPTZ);
+DROP INDEX IF EXISTS idx_items_currency;
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE items DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_items_date_id ON items (date, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_items_date_id;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS report_jobs (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('analytics', 'export')),
+    params JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('queued', 'running', 'done', 'failed', 'expired')),
+    progress SMALLINT NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    error TEXT NOT NULL DEFAULT '',
+    artifact_path TEXT NOT NULL DEFAULT '',
+    artifact_name TEXT NOT NULL DEFAULT '',
+    content_type TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_jobs_status_created ON report_jobs (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_report_jobs_expires_at ON report_jobs (expires_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_report_jobs_expires_at;
+DROP INDEX IF EXISTS idx_report_jobs_status_created;
+DROP TABLE IF EXISTS report_jobs;
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE items ADD COLUMN IF NOT EXISTS external_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_items_external_id ON items (external_id) WHERE external_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_items_external_id;
+ALTER TABLE items DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS item_history (
+    id BIGSERIAL PRIMARY KEY,
+    item_id BIGINT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge')),
+    before JSONB,
+    after JSONB,
+    actor TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_item_history_item_id ON item_history (item_id, id);
+CREATE INDEX IF NOT EXISTS idx_item_history_created_at ON item_history (created_at);
+CREATE INDEX IF NOT EXISTS idx_item_history_actor ON item_history (actor, id);
+
+-- История только дополняется: изменение и удаление записей запрещены.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION item_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'item_history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER item_history_append_only
+    BEFORE UPDATE OR DELETE ON item_history
+    FOR EACH ROW EXECUTE FUNCTION item_history_append_only();
+
+CREATE TRIGGER item_history_no_truncate
+    BEFORE TRUNCATE ON item_history
+    FOR EACH STATEMENT EXECUTE FUNCTION item_history_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS item_history_no_truncate ON item_history;
+DROP TRIGGER IF EXISTS item_history_append_only ON item_history;
+DROP FUNCTION IF EXISTS item_history_append_only();
+DROP TABLE IF EXISTS item_history;
@@ -0,0 +1,94 @@
+-- +goose Up
+ALTER TABLE item_history
+    ADD COLUMN IF NOT EXISTS seq BIGINT,
+    ADD COLUMN IF NOT EXISTS prev_hash TEXT,
+    ADD COLUMN IF NOT EXISTS hash TEXT;
+
+-- Хеш записи журнала: SHA-256 от хеша предыдущей записи и полей записи,
+-- каждое из которых предваряется длиной в байтах. Должен совпадать
+-- с domain.AuditEntry.ChainHash.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION item_history_hash(
+    prev_hash TEXT, seq BIGINT, item_id BIGINT, action TEXT, before JSONB, after JSONB,
+    actor TEXT, request_id TEXT, created_at TIMESTAMPTZ
+) RETURNS TEXT AS $$
+    SELECT encode(sha256(convert_to(
+        prev_hash || string_agg(octet_length(f)::text || ':' || f, '' ORDER BY n),
+        'UTF8'
+    )), 'hex')
+    FROM unnest(ARRAY[
+        seq::text,
+        item_id::text,
+        action,
+        coalesce(before::text, ''),
+        coalesce(after::text, ''),
+        actor,
+        request_id,
+        (extract(epoch FROM created_at) * 1000000)::bigint::text
+    ]) WITH ORDINALITY AS t(f, n)
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- Записи выстраиваются в цепочку под advisory-блокировкой, поэтому
+-- параллельные транзакции получают последовательные seq.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION item_history_chain() RETURNS trigger AS $$
+DECLARE
+    last_seq BIGINT;
+    last_hash TEXT;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('item_history'), 0);
+    SELECT seq, hash INTO last_seq, last_hash FROM item_history ORDER BY seq DESC LIMIT 1;
+    NEW.seq := coalesce(last_seq, 0) + 1;
+    NEW.prev_hash := coalesce(last_hash, repeat('0', 64));
+    NEW.hash := item_history_hash(NEW.prev_hash, NEW.seq, NEW.item_id, NEW.action, NEW.before, NEW.after,
+        NEW.actor, NEW.request_id, NEW.created_at);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+ALTER TABLE item_history DISABLE TRIGGER item_history_append_only;
+
+-- +goose StatementBegin
+DO $$
+DECLARE
+    r RECORD;
+    n BIGINT := 0;
+    prev TEXT := repeat('0', 64);
+BEGIN
+    FOR r IN SELECT * FROM item_history ORDER BY id LOOP
+        n := n + 1;
+        UPDATE item_history
+        SET seq = n,
+            prev_hash = prev,
+            hash = item_history_hash(prev, n, r.item_id, r.action, r.before, r.after, r.actor, r.request_id, r.created_at)
+        WHERE id = r.id
+        RETURNING hash INTO prev;
+    END LOOP;
+END;
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE item_history ENABLE TRIGGER item_history_append_only;
+
+ALTER TABLE item_history
+    ALTER COLUMN seq SET NOT NULL,
+    ALTER COLUMN prev_hash SET NOT NULL,
+    ALTER COLUMN hash SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_history_seq ON item_history (seq);
+
+CREATE TRIGGER item_history_chain
+    BEFORE INSERT ON item_history
+    FOR EACH ROW EXECUTE FUNCTION item_history_chain();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS item_history_chain ON item_history;
+DROP INDEX IF EXISTS idx_item_history_seq;
+DROP FUNCTION IF EXISTS item_history_chain();
+DROP FUNCTION IF EXISTS item_history_hash(TEXT, BIGINT, BIGINT, TEXT, JSONB, JSONB, TEXT, TEXT, TIMESTAMPTZ);
+ALTER TABLE item_history
+    DROP COLUMN IF EXISTS hash,
+    DROP COLUMN IF EXISTS prev_hash,
+    DROP COLUMN IF EXISTS seq;
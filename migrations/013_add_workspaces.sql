@@ -0,0 +1,156 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS workspaces (
+    id BIGSERIAL PRIMARY KEY,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Существующие данные переходят в пространство default.
+INSERT INTO workspaces (id, slug, name) VALUES (1, 'default', 'Default') ON CONFLICT DO NOTHING;
+SELECT setval(pg_get_serial_sequence('workspaces', 'id'), GREATEST((SELECT max(id) FROM workspaces), 1));
+
+-- Пространство запроса задаётся приложением в начале каждой транзакции:
+-- set_config('app.workspace_id', '<id>', true). Значение '*' открывает все
+-- пространства и используется только фоновыми задачами; для него, как и без
+-- значения, current_workspace_id() возвращает NULL.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION current_workspace_id() RETURNS BIGINT AS $$
+    SELECT NULLIF(NULLIF(current_setting('app.workspace_id', true), ''), '*')::bigint
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION workspace_visible(ws BIGINT) RETURNS BOOLEAN AS $$
+    SELECT current_setting('app.workspace_id', true) IN ('*', ws::text)
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+ALTER TABLE items ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces (id);
+ALTER TABLE items ALTER COLUMN workspace_id DROP DEFAULT;
+
+DROP INDEX IF EXISTS idx_items_external_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_items_workspace_external_id ON items (workspace_id, external_id) WHERE external_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_items_workspace_date_id ON items (workspace_id, date, id);
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces (id);
+ALTER TABLE idempotency_keys ALTER COLUMN workspace_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (workspace_id, key);
+
+ALTER TABLE report_jobs ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces (id);
+ALTER TABLE report_jobs ALTER COLUMN workspace_id DROP DEFAULT;
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces (id);
+ALTER TABLE api_keys ALTER COLUMN workspace_id DROP DEFAULT;
+
+-- Журнал ведётся отдельной цепочкой хешей в каждом пространстве. Вся
+-- существующая история принадлежит default, поэтому её seq и хеши не меняются.
+ALTER TABLE item_history ADD COLUMN IF NOT EXISTS workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces (id);
+ALTER TABLE item_history ALTER COLUMN workspace_id DROP DEFAULT;
+
+DROP INDEX IF EXISTS idx_item_history_seq;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_history_workspace_seq ON item_history (workspace_id, seq);
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION item_history_chain() RETURNS trigger AS $$
+DECLARE
+    last_seq BIGINT;
+    last_hash TEXT;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('item_history'), NEW.workspace_id::int);
+    SELECT seq, hash INTO last_seq, last_hash
+    FROM item_history
+    WHERE workspace_id = NEW.workspace_id
+    ORDER BY seq DESC
+    LIMIT 1;
+    NEW.seq := coalesce(last_seq, 0) + 1;
+    NEW.prev_hash := coalesce(last_hash, repeat('0', 64));
+    NEW.hash := item_history_hash(NEW.prev_hash, NEW.seq, NEW.item_id, NEW.action, NEW.before, NEW.after,
+        NEW.actor, NEW.request_id, NEW.created_at);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Второй рубеж изоляции: даже запрос без условия по workspace_id видит
+-- только строки своего пространства. FORCE распространяет политики на
+-- владельца таблиц; суперпользователь и роли с BYPASSRLS их не соблюдают.
+ALTER TABLE items ENABLE ROW LEVEL SECURITY;
+ALTER TABLE items FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON items
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+ALTER TABLE item_history ENABLE ROW LEVEL SECURITY;
+ALTER TABLE item_history FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON item_history
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON idempotency_keys
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+ALTER TABLE report_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE report_jobs FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON report_jobs
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+ALTER TABLE api_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE api_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON api_keys
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+-- +goose Down
+DROP POLICY IF EXISTS workspace_isolation ON api_keys;
+ALTER TABLE api_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE api_keys DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS workspace_isolation ON report_jobs;
+ALTER TABLE report_jobs NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE report_jobs DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS workspace_isolation ON idempotency_keys;
+ALTER TABLE idempotency_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS workspace_isolation ON item_history;
+ALTER TABLE item_history NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE item_history DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS workspace_isolation ON items;
+ALTER TABLE items NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE items DISABLE ROW LEVEL SECURITY;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION item_history_chain() RETURNS trigger AS $$
+DECLARE
+    last_seq BIGINT;
+    last_hash TEXT;
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('item_history'), 0);
+    SELECT seq, hash INTO last_seq, last_hash FROM item_history ORDER BY seq DESC LIMIT 1;
+    NEW.seq := coalesce(last_seq, 0) + 1;
+    NEW.prev_hash := coalesce(last_hash, repeat('0', 64));
+    NEW.hash := item_history_hash(NEW.prev_hash, NEW.seq, NEW.item_id, NEW.action, NEW.before, NEW.after,
+        NEW.actor, NEW.request_id, NEW.created_at);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP INDEX IF EXISTS idx_item_history_workspace_seq;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_history_seq ON item_history (seq);
+ALTER TABLE item_history DROP COLUMN IF EXISTS workspace_id;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE report_jobs DROP COLUMN IF EXISTS workspace_id;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
+
+DROP INDEX IF EXISTS idx_items_workspace_date_id;
+DROP INDEX IF EXISTS idx_items_workspace_external_id;
+ALTER TABLE items DROP COLUMN IF EXISTS workspace_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_items_external_id ON items (external_id) WHERE external_id IS NOT NULL;
+
+DROP FUNCTION IF EXISTS workspace_visible(BIGINT);
+DROP FUNCTION IF EXISTS current_workspace_id();
+DROP TABLE IF EXISTS workspaces;
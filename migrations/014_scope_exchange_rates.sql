@@ -0,0 +1,60 @@
+-- +goose Up
+-- Курсы принадлежат пространству: редактор одного пространства не может
+-- изменить пересчёт аналитики и отчётов другого. Курсы, загруженные
+-- до разделения (workspace_id IS NULL), остаются общими: их видят все
+-- пространства, а изменить может только владелец БД или контекст system.
+ALTER TABLE exchange_rates ADD COLUMN IF NOT EXISTS workspace_id BIGINT REFERENCES workspaces (id);
+ALTER TABLE exchange_rates DROP CONSTRAINT IF EXISTS exchange_rates_pkey;
+ALTER TABLE exchange_rates ADD CONSTRAINT exchange_rates_workspace_currency_date
+    UNIQUE NULLS NOT DISTINCT (workspace_id, currency, date);
+
+-- Курс валюты к базовой (RUB) на дату: последний известный курс не позже
+-- даты среди курсов текущего пространства и общих; на одну дату курс
+-- пространства важнее общего.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION exchange_rate_on(cur CHAR(3), at TIMESTAMPTZ) RETURNS NUMERIC AS $$
+    SELECT CASE
+        WHEN cur = 'RUB' THEN 1::numeric
+        ELSE (
+            SELECT rate
+            FROM exchange_rates
+            WHERE currency = cur AND date <= (at AT TIME ZONE 'UTC')::date
+                AND (workspace_id IS NULL OR workspace_id = current_workspace_id())
+            ORDER BY date DESC, workspace_id NULLS LAST
+            LIMIT 1
+        )
+    END
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+ALTER TABLE exchange_rates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE exchange_rates FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON exchange_rates
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+-- Общие курсы пространства только читают.
+CREATE POLICY shared_rates_read ON exchange_rates FOR SELECT
+    USING (workspace_id IS NULL);
+
+-- +goose Down
+DROP POLICY IF EXISTS shared_rates_read ON exchange_rates;
+DROP POLICY IF EXISTS workspace_isolation ON exchange_rates;
+ALTER TABLE exchange_rates NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE exchange_rates DISABLE ROW LEVEL SECURITY;
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION exchange_rate_on(cur CHAR(3), at TIMESTAMPTZ) RETURNS NUMERIC AS $$
+    SELECT CASE
+        WHEN cur = 'RUB' THEN 1::numeric
+        ELSE (
+            SELECT rate
+            FROM exchange_rates
+            WHERE currency = cur AND date <= (at AT TIME ZONE 'UTC')::date
+            ORDER BY date DESC
+            LIMIT 1
+        )
+    END
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+DELETE FROM exchange_rates WHERE workspace_id IS NOT NULL;
+ALTER TABLE exchange_rates DROP CONSTRAINT IF EXISTS exchange_rates_workspace_currency_date;
+ALTER TABLE exchange_rates ADD PRIMARY KEY (currency, date);
+ALTER TABLE exchange_rates DROP COLUMN IF EXISTS workspace_id;
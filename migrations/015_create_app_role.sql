@@ -0,0 +1,32 @@
+-- +goose Up
+-- Приложение подключается ролью sales_tracker_app без SUPERUSER и BYPASSRLS,
+-- иначе политики workspace_isolation на него не действуют. Миграции остаются
+-- за ролью-владельцем схемы. Пароль и право входа роли задаёт скрипт
+-- инициализации контейнера (docker/postgres/init-app-role.sh) или
+-- администратор БД; здесь роль создаётся без входа, если её ещё нет.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'sales_tracker_app') THEN
+        CREATE ROLE sales_tracker_app NOLOGIN NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE;
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+GRANT USAGE ON SCHEMA public TO sales_tracker_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO sales_tracker_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO sales_tracker_app;
+REVOKE ALL ON goose_db_version FROM sales_tracker_app;
+REVOKE ALL ON SEQUENCE goose_db_version_id_seq FROM sales_tracker_app;
+
+-- Таблицы следующих миграций доступны приложению без отдельных GRANT.
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO sales_tracker_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO sales_tracker_app;
+
+-- +goose Down
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT ON SEQUENCES FROM sales_tracker_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM sales_tracker_app;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM sales_tracker_app;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM sales_tracker_app;
+REVOKE USAGE ON SCHEMA public FROM sales_tracker_app;
@@ -0,0 +1,80 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS accounts (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id BIGINT NOT NULL REFERENCES workspaces (id),
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('cash', 'bank', 'card')),
+    currency CHAR(3) NOT NULL,
+    opening_balance NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (workspace_id, name),
+    -- Цель составных внешних ключей items: счёт операции всегда из её пространства.
+    UNIQUE (workspace_id, id)
+);
+
+ALTER TABLE accounts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE accounts FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON accounts
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+-- account_id — счёт операции (для перевода — счёт списания), to_account_id —
+-- счёт зачисления перевода.
+ALTER TABLE items
+    ADD COLUMN IF NOT EXISTS account_id BIGINT,
+    ADD COLUMN IF NOT EXISTS to_account_id BIGINT;
+
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_type_check;
+ALTER TABLE items ADD CONSTRAINT items_type_check CHECK (type IN ('income', 'expense', 'transfer'));
+ALTER TABLE items ADD CONSTRAINT items_account_fk
+    FOREIGN KEY (workspace_id, account_id) REFERENCES accounts (workspace_id, id);
+ALTER TABLE items ADD CONSTRAINT items_to_account_fk
+    FOREIGN KEY (workspace_id, to_account_id) REFERENCES accounts (workspace_id, id);
+ALTER TABLE items ADD CONSTRAINT items_transfer_accounts CHECK (
+    CASE WHEN type = 'transfer'
+        THEN account_id IS NOT NULL AND to_account_id IS NOT NULL AND account_id <> to_account_id
+        ELSE to_account_id IS NULL
+    END
+);
+
+-- Остатки ведутся в валюте счёта, поэтому операция по счёту должна быть
+-- в его валюте.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION items_account_currency() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM accounts
+        WHERE id IN (NEW.account_id, NEW.to_account_id) AND currency <> NEW.currency
+    ) THEN
+        RAISE EXCEPTION 'item currency % does not match account currency', NEW.currency
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'items_account_currency';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER items_account_currency
+    BEFORE INSERT OR UPDATE OF currency, account_id, to_account_id ON items
+    FOR EACH ROW EXECUTE FUNCTION items_account_currency();
+
+CREATE INDEX IF NOT EXISTS idx_items_account_date_id ON items (account_id, date, id) WHERE account_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_items_to_account_date_id ON items (to_account_id, date, id) WHERE to_account_id IS NOT NULL;
+
+-- +goose Down
+-- Переводы не выражаются без счетов и удаляются вместе с ними.
+SELECT set_config('app.workspace_id', '*', true);
+DELETE FROM items WHERE type = 'transfer';
+
+DROP INDEX IF EXISTS idx_items_to_account_date_id;
+DROP INDEX IF EXISTS idx_items_account_date_id;
+DROP TRIGGER IF EXISTS items_account_currency ON items;
+DROP FUNCTION IF EXISTS items_account_currency();
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_transfer_accounts;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_to_account_fk;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_account_fk;
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_type_check;
+ALTER TABLE items ADD CONSTRAINT items_type_check CHECK (type IN ('income', 'expense'));
+ALTER TABLE items
+    DROP COLUMN IF EXISTS to_account_id,
+    DROP COLUMN IF EXISTS account_id;
+DROP TABLE IF EXISTS accounts;
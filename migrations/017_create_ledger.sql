@@ -0,0 +1,330 @@
+-- +goose Up
+-- Двойная запись включается для пространства отдельно (sales-tracker ledger
+-- enable); пока она выключена, проводки не создаются.
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS ledger_enabled BOOLEAN NOT NULL DEFAULT false;
+
+-- План счетов. system отмечает счета по умолчанию: cash — деньги операций
+-- без счёта, income и expense — доходы и расходы без отдельного счёта
+-- категории, equity — капитал, в корреспонденции с которым отражаются
+-- начальные остатки. account_id связывает счёт учёта со счётом accounts,
+-- category — счёт доходов или расходов с категорией операций.
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id BIGINT NOT NULL REFERENCES workspaces (id),
+    code VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('asset', 'liability', 'equity', 'income', 'expense')),
+    system VARCHAR(20) CHECK (system IN ('cash', 'income', 'expense', 'equity')),
+    account_id BIGINT,
+    category VARCHAR(100) CHECK (category <> ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (workspace_id, code),
+    UNIQUE (workspace_id, id),
+    UNIQUE (workspace_id, system),
+    UNIQUE (workspace_id, account_id),
+    UNIQUE (workspace_id, type, category),
+    FOREIGN KEY (workspace_id, account_id) REFERENCES accounts (workspace_id, id) ON DELETE CASCADE,
+    CHECK (account_id IS NULL OR type = 'asset'),
+    CHECK (category IS NULL OR type IN ('income', 'expense'))
+);
+
+-- Проводка операции (source = item) и начального остатка счёта (opening)
+-- ведутся триггерами и пересоздаются при каждом изменении источника;
+-- история изменений остаётся в item_history. manual — ручные проводки.
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id BIGINT NOT NULL REFERENCES workspaces (id),
+    date TIMESTAMPTZ NOT NULL,
+    currency CHAR(3) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    source VARCHAR(20) NOT NULL CHECK (source IN ('item', 'opening', 'manual')),
+    item_id BIGINT REFERENCES items (id) ON DELETE CASCADE,
+    account_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (workspace_id, id),
+    FOREIGN KEY (workspace_id, account_id) REFERENCES accounts (workspace_id, id) ON DELETE CASCADE,
+    CHECK ((source = 'item') = (item_id IS NOT NULL)),
+    CHECK ((source = 'opening') = (account_id IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journal_entries_item_id ON journal_entries (item_id) WHERE item_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journal_entries_account_id ON journal_entries (account_id) WHERE account_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_journal_entries_workspace_date_id ON journal_entries (workspace_id, date, id);
+
+-- Строка проводки — ровно одна из сумм debit и credit.
+CREATE TABLE IF NOT EXISTS journal_postings (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id BIGINT NOT NULL,
+    entry_id BIGINT NOT NULL,
+    ledger_account_id BIGINT NOT NULL,
+    debit NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    credit NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    CHECK (debit >= 0 AND credit >= 0 AND (debit > 0) <> (credit > 0)),
+    FOREIGN KEY (workspace_id, entry_id) REFERENCES journal_entries (workspace_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (workspace_id, ledger_account_id) REFERENCES ledger_accounts (workspace_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_postings_entry_id ON journal_postings (entry_id);
+CREATE INDEX IF NOT EXISTS idx_journal_postings_ledger_account_id ON journal_postings (ledger_account_id);
+
+-- Баланс проводки проверяется в конце транзакции, когда записаны все её строки.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION journal_entry_balanced() RETURNS trigger AS $$
+DECLARE
+    entry BIGINT;
+    total_debit NUMERIC;
+    total_credit NUMERIC;
+    lines INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        entry := OLD.entry_id;
+    ELSE
+        entry := NEW.entry_id;
+    END IF;
+    SELECT coalesce(sum(debit), 0), coalesce(sum(credit), 0), count(*)
+    INTO total_debit, total_credit, lines
+    FROM journal_postings
+    WHERE entry_id = entry;
+    IF lines > 0 AND (lines < 2 OR total_debit <> total_credit) THEN
+        RAISE EXCEPTION 'journal entry % is not balanced: debit %, credit %', entry, total_debit, total_credit
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'journal_entry_balanced';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE CONSTRAINT TRIGGER journal_entry_balanced
+    AFTER INSERT OR UPDATE OR DELETE ON journal_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION journal_entry_balanced();
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_enabled(ws BIGINT) RETURNS BOOLEAN AS $$
+    SELECT coalesce((SELECT ledger_enabled FROM workspaces WHERE id = ws), false)
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- ledger_money_account возвращает счёт учёта денег счёта acc, создавая его
+-- при первом обращении; для операций без счёта — системный счёт cash.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_money_account(ws BIGINT, acc BIGINT) RETURNS BIGINT AS $$
+DECLARE
+    result BIGINT;
+BEGIN
+    IF acc IS NULL THEN
+        SELECT id INTO result FROM ledger_accounts WHERE workspace_id = ws AND system = 'cash';
+        RETURN result;
+    END IF;
+    SELECT id INTO result FROM ledger_accounts WHERE workspace_id = ws AND account_id = acc;
+    IF result IS NULL THEN
+        INSERT INTO ledger_accounts (workspace_id, code, name, type, account_id)
+        SELECT ws, '1000.' || a.id, a.name, 'asset', a.id FROM accounts a WHERE a.id = acc
+        ON CONFLICT (workspace_id, account_id) DO NOTHING;
+        SELECT id INTO result FROM ledger_accounts WHERE workspace_id = ws AND account_id = acc;
+    END IF;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- ledger_category_account возвращает счёт доходов или расходов категории,
+-- а если его нет — системный счёт типа kind.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_category_account(ws BIGINT, kind TEXT, cat TEXT) RETURNS BIGINT AS $$
+    SELECT coalesce(
+        (SELECT id FROM ledger_accounts WHERE workspace_id = ws AND type = kind AND category = cat),
+        (SELECT id FROM ledger_accounts WHERE workspace_id = ws AND system = kind)
+    )
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- Доход: Дт деньги — Кт доходы; расход: Дт расходы — Кт деньги;
+-- перевод: Дт счёт зачисления — Кт счёт списания.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_post_item(item items) RETURNS void AS $$
+DECLARE
+    entry BIGINT;
+    debit_account BIGINT;
+    credit_account BIGINT;
+BEGIN
+    IF item.type = 'income' THEN
+        debit_account := ledger_money_account(item.workspace_id, item.account_id);
+        credit_account := ledger_category_account(item.workspace_id, 'income', item.category);
+    ELSIF item.type = 'expense' THEN
+        debit_account := ledger_category_account(item.workspace_id, 'expense', item.category);
+        credit_account := ledger_money_account(item.workspace_id, item.account_id);
+    ELSE
+        debit_account := ledger_money_account(item.workspace_id, item.to_account_id);
+        credit_account := ledger_money_account(item.workspace_id, item.account_id);
+    END IF;
+
+    INSERT INTO journal_entries (workspace_id, date, currency, description, source, item_id)
+    VALUES (item.workspace_id, item.date, item.currency,
+        coalesce(nullif(item.description, ''), item.category, ''), 'item', item.id)
+    RETURNING id INTO entry;
+    INSERT INTO journal_postings (workspace_id, entry_id, ledger_account_id, debit, credit)
+    VALUES (item.workspace_id, entry, debit_account, item.amount, 0),
+        (item.workspace_id, entry, credit_account, 0, item.amount);
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Начальный остаток: Дт деньги — Кт капитал (для отрицательного — наоборот).
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_post_opening(acc accounts) RETURNS void AS $$
+DECLARE
+    entry BIGINT;
+    money BIGINT;
+    equity BIGINT;
+BEGIN
+    DELETE FROM journal_entries WHERE account_id = acc.id;
+    IF acc.opening_balance = 0 THEN
+        RETURN;
+    END IF;
+    money := ledger_money_account(acc.workspace_id, acc.id);
+    SELECT id INTO equity FROM ledger_accounts WHERE workspace_id = acc.workspace_id AND system = 'equity';
+
+    INSERT INTO journal_entries (workspace_id, date, currency, description, source, account_id)
+    VALUES (acc.workspace_id, acc.created_at, acc.currency, 'Начальный остаток: ' || acc.name, 'opening', acc.id)
+    RETURNING id INTO entry;
+    INSERT INTO journal_postings (workspace_id, entry_id, ledger_account_id, debit, credit)
+    VALUES (acc.workspace_id, entry, money, greatest(acc.opening_balance, 0), greatest(-acc.opening_balance, 0)),
+        (acc.workspace_id, entry, equity, greatest(-acc.opening_balance, 0), greatest(acc.opening_balance, 0));
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- ledger_enable включает двойную запись для пространства и заново проводит
+-- все его счета и действующие операции; повторный вызов безопасен.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_enable(ws BIGINT) RETURNS void AS $$
+BEGIN
+    UPDATE workspaces SET ledger_enabled = true WHERE id = ws;
+    INSERT INTO ledger_accounts (workspace_id, code, name, type, system) VALUES
+        (ws, '1000', 'Денежные средства', 'asset', 'cash'),
+        (ws, '3000', 'Капитал', 'equity', 'equity'),
+        (ws, '4000', 'Доходы', 'income', 'income'),
+        (ws, '5000', 'Расходы', 'expense', 'expense')
+    ON CONFLICT DO NOTHING;
+
+    DELETE FROM journal_entries WHERE workspace_id = ws AND source IN ('item', 'opening');
+    PERFORM ledger_post_opening(a) FROM accounts a WHERE a.workspace_id = ws;
+    PERFORM ledger_post_item(i) FROM items i
+    WHERE i.workspace_id = ws AND i.deleted_at IS NULL AND i.amount > 0;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- Операция в корзине и операция с нулевой суммой проводок не имеют.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_items_sync() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        DELETE FROM journal_entries WHERE item_id = OLD.id;
+    END IF;
+    IF NEW.deleted_at IS NULL AND NEW.amount > 0 AND ledger_enabled(NEW.workspace_id) THEN
+        PERFORM ledger_post_item(NEW);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER ledger_items_sync
+    AFTER INSERT OR UPDATE ON items
+    FOR EACH ROW EXECUTE FUNCTION ledger_items_sync();
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_accounts_sync() RETURNS trigger AS $$
+BEGIN
+    IF NOT ledger_enabled(NEW.workspace_id) THEN
+        RETURN NULL;
+    END IF;
+    IF TG_OP = 'UPDATE' AND NEW.name IS DISTINCT FROM OLD.name THEN
+        UPDATE ledger_accounts SET name = NEW.name WHERE workspace_id = NEW.workspace_id AND account_id = NEW.id;
+    END IF;
+    IF TG_OP = 'INSERT' OR NEW.opening_balance IS DISTINCT FROM OLD.opening_balance THEN
+        PERFORM ledger_post_opening(NEW);
+    END IF;
+    PERFORM ledger_money_account(NEW.workspace_id, NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER ledger_accounts_sync
+    AFTER INSERT OR UPDATE ON accounts
+    FOR EACH ROW EXECUTE FUNCTION ledger_accounts_sync();
+
+-- Проводка начального остатка удаляется до счёта, чтобы каскадное удаление
+-- счёта учёта не натолкнулось на её строки.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_accounts_unpost() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM journal_entries WHERE account_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER ledger_accounts_unpost
+    BEFORE DELETE ON accounts
+    FOR EACH ROW EXECUTE FUNCTION ledger_accounts_unpost();
+
+-- Новый счёт категории забирает проводки её операций у системного счёта.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION ledger_category_repost() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM journal_entries e
+    USING items i
+    WHERE e.item_id = i.id AND i.workspace_id = NEW.workspace_id
+        AND i.type = NEW.type AND i.category = NEW.category;
+    PERFORM ledger_post_item(i) FROM items i
+    WHERE i.workspace_id = NEW.workspace_id AND i.type = NEW.type AND i.category = NEW.category
+        AND i.deleted_at IS NULL AND i.amount > 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER ledger_category_repost
+    AFTER INSERT ON ledger_accounts
+    FOR EACH ROW WHEN (NEW.category IS NOT NULL)
+    EXECUTE FUNCTION ledger_category_repost();
+
+ALTER TABLE ledger_accounts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ledger_accounts FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON ledger_accounts
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+ALTER TABLE journal_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE journal_entries FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON journal_entries
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+ALTER TABLE journal_postings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE journal_postings FORCE ROW LEVEL SECURITY;
+CREATE POLICY workspace_isolation ON journal_postings
+    USING (workspace_visible(workspace_id)) WITH CHECK (workspace_visible(workspace_id));
+
+-- +goose Down
+DROP TRIGGER IF EXISTS ledger_category_repost ON ledger_accounts;
+DROP TRIGGER IF EXISTS ledger_accounts_unpost ON accounts;
+DROP TRIGGER IF EXISTS ledger_accounts_sync ON accounts;
+DROP TRIGGER IF EXISTS ledger_items_sync ON items;
+DROP TABLE IF EXISTS journal_postings;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS ledger_accounts;
+DROP FUNCTION IF EXISTS ledger_category_repost();
+DROP FUNCTION IF EXISTS ledger_accounts_unpost();
+DROP FUNCTION IF EXISTS ledger_accounts_sync();
+DROP FUNCTION IF EXISTS ledger_items_sync();
+DROP FUNCTION IF EXISTS ledger_enable(BIGINT);
+DROP FUNCTION IF EXISTS ledger_post_opening(accounts);
+DROP FUNCTION IF EXISTS ledger_post_item(items);
+DROP FUNCTION IF EXISTS ledger_category_account(BIGINT, TEXT, TEXT);
+DROP FUNCTION IF EXISTS ledger_money_account(BIGINT, BIGINT);
+DROP FUNCTION IF EXISTS ledger_enabled(BIGINT);
+DROP FUNCTION IF EXISTS journal_entry_balanced();
+ALTER TABLE workspaces DROP COLUMN IF EXISTS ledger_enabled;
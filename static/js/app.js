@@ -3,8 +3,8 @@ class SalesTrackerApp {
         this.apiUrl = window.location.origin;
         this.currentPage = 1;
         this.limit = 25;
-        this.allItems = [];
-        this.filteredItems = [];
+        this.items = [];
+        this.filters = {};
         this.totalItems = 0;
         this.analyticData = null;
         this.init();
@@ -16,6 +16,28 @@ class SalesTrackerApp {
         this.setupDateTimePickers();
     }
 
+    // Запросы к API с ключом или JWT из localStorage; при 401 ключ
+    // запрашивается у пользователя и запрос повторяется.
+    async apiFetch(url, options = {}) {
+        const send = () => {
+            const headers = new Headers(options.headers || {});
+            const token = localStorage.getItem('salesTrackerToken');
+            if (token) {
+                headers.set('Authorization', `Bearer ${token}`);
+            }
+            return fetch(url, { ...options, headers });
+        };
+        let response = await send();
+        if (response.status === 401) {
+            const token = prompt('Введите API-ключ или токен доступа');
+            if (token) {
+                localStorage.setItem('salesTrackerToken', token.trim());
+                response = await send();
+            }
+        }
+        return response;
+    }
+
     setupDateTimePickers() {
         const now = new Date();
         const setDateTime = (elementId) => {
@@ -47,15 +69,13 @@ class SalesTrackerApp {
         document.getElementById('prev-page').addEventListener('click', () => {
             if (this.currentPage > 1) {
                 this.currentPage--;
-                this.renderItems();
-                this.updatePagination();
+                this.loadItems();
             }
         });
         
         document.getElementById('next-page').addEventListener('click', () => {
             this.currentPage++;
-            this.renderItems();
-            this.updatePagination();
+            this.loadItems();
         });
         
         document.getElementById('add-item-form').addEventListener('submit', e => {
@@ -92,7 +112,11 @@ class SalesTrackerApp {
 
     async loadItems() {
         try {
-            const response = await fetch(`${this.apiUrl}/items`);
+            const params = new URLSearchParams({ page: this.currentPage, limit: this.limit });
+            Object.entries(this.filters).forEach(([key, value]) => {
+                if (value) params.append(key, value);
+            });
+            const response = await this.apiFetch(`${this.apiUrl}/items?${params}`);
             if (!response.ok) {
                 throw new Error(`Ошибка сервера: ${response.status}`);
             }
@@ -100,10 +124,8 @@ class SalesTrackerApp {
             if (!data.items || !Array.isArray(data.items)) {
                 throw new Error('Некорректные данные от сервера');
             }
-            this.allItems = data.items;
-            this.filteredItems = [...this.allItems];
-            this.totalItems = this.filteredItems.length;
-            this.currentPage = 1;
+            this.items = data.items;
+            this.totalItems = data.total;
             this.renderItems();
             this.updatePagination();
         } catch (error) {
@@ -115,42 +137,19 @@ class SalesTrackerApp {
     }
 
     applyFilters() {
-        const type = document.getElementById('filter-type').value;
-        const category = document.getElementById('filter-category').value.trim();
         const dateFrom = document.getElementById('filter-date-from').value;
         const dateTo = document.getElementById('filter-date-to').value;
-        
-        this.filteredItems = this.allItems.filter(item => {
-            if (type && item.type !== type) {
-                return false;
-            }
-            if (category && item.category && !item.category.toLowerCase().includes(category.toLowerCase())) {
-                return false;
-            }
-            if (dateFrom || dateTo) {
-                const itemDate = new Date(item.date);
-                if (dateFrom) {
-                    const fromDate = new Date(dateFrom);
-                    fromDate.setHours(0, 0, 0, 0);
-                    if (itemDate < fromDate) {
-                        return false;
-                    }
-                }
-                if (dateTo) {
-                    const toDate = new Date(dateTo);
-                    toDate.setHours(23, 59, 59, 999);
-                    if (itemDate > toDate) {
-                        return false;
-                    }
-                }
-            }
-            return true;
-        });
-        
-        this.totalItems = this.filteredItems.length;
+        const from = dateFrom ? new Date(`${dateFrom}T00:00:00`) : null;
+        const to = dateTo ? new Date(`${dateTo}T23:59:59.999`) : null;
+
+        this.filters = {
+            type: document.getElementById('filter-type').value,
+            q: document.getElementById('filter-category').value.trim(),
+            from: from && !isNaN(from) ? from.toISOString() : '',
+            to: to && !isNaN(to) ? to.toISOString() : ''
+        };
         this.currentPage = 1;
-        this.renderItems();
-        this.updatePagination();
+        this.loadItems();
     }
 
     resetFilters() {
@@ -161,18 +160,14 @@ class SalesTrackerApp {
         yesterday.setDate(yesterday.getDate() - 7);
         document.getElementById('filter-date-from').value = yesterday.toISOString().split('T')[0];
         document.getElementById('filter-date-to').value = today.toISOString().split('T')[0];
-        this.filteredItems = [...this.allItems];
-        this.totalItems = this.filteredItems.length;
+        this.filters = {};
         this.currentPage = 1;
-        this.renderItems();
-        this.updatePagination();
+        this.loadItems();
     }
 
     renderItems() {
         const tbody = document.getElementById('items-body');
-        const startIndex = (this.currentPage - 1) * this.limit;
-        const endIndex = startIndex + this.limit;
-        const itemsToShow = this.filteredItems.slice(startIndex, endIndex);
+        const itemsToShow = this.items;
         
         if (itemsToShow.length === 0) {
             tbody.innerHTML = '<tr><td colspan="7" class="no-data">Записей не найдено</td></tr>';
@@ -183,7 +178,7 @@ class SalesTrackerApp {
             <tr>
                 <td>${item.id}</td>
                 <td><span class="${item.type}">${this.getTypeLabel(item.type)}</span></td>
-                <td><span class="${item.type}">${this.formatCurrency(item.amount)}</span></td>
+                <td><span class="${item.type}">${this.formatCurrency(item.amount, item.currency)}</span></td>
                 <td>${this.formatDate(item.date)}</td>
                 <td>${item.category || '-'}</td>
                 <td>${item.description || '-'}</td>
@@ -206,11 +201,11 @@ class SalesTrackerApp {
         return type === 'income' ? 'Доход' : 'Расход';
     }
 
-    formatCurrency(amount) {
+    formatCurrency(amount, currency = 'RUB') {
         if (amount === undefined || amount === null) return '0.00 ₽';
         return new Intl.NumberFormat('ru-RU', {
             style: 'currency',
-            currency: 'RUB',
+            currency: currency || 'RUB',
             minimumFractionDigits: 2
         }).format(amount);
     }
@@ -255,7 +250,7 @@ class SalesTrackerApp {
         }
         
         try {
-            const response = await fetch(`${this.apiUrl}/items`, {
+            const response = await this.apiFetch(`${this.apiUrl}/items`, {
                 method: 'POST',
                 headers: { 'Content-Type': 'application/json' },
                 body: JSON.stringify(formData)
@@ -284,11 +279,12 @@ class SalesTrackerApp {
 
     async openEditModal(id) {
         try {
-            const response = await fetch(`${this.apiUrl}/items/${id}`);
+            const response = await this.apiFetch(`${this.apiUrl}/items/${id}`);
             if (!response.ok) {
                 throw new Error('Не удалось загрузить запись');
             }
             const item = await response.json();
+            this.editETag = response.headers.get('ETag');
             document.getElementById('edit-item-id').value = item.id;
             document.getElementById('edit-item-type').value = item.type;
             document.getElementById('edit-item-amount').value = item.amount;
@@ -342,11 +338,18 @@ class SalesTrackerApp {
         }
         
         try {
-            const response = await fetch(`${this.apiUrl}/items/${id}`, {
-                method: 'PUT',
-                headers: { 'Content-Type': 'application/json' },
+            const headers = { 'Content-Type': 'application/merge-patch+json' };
+            if (this.editETag) {
+                headers['If-Match'] = this.editETag;
+            }
+            const response = await this.apiFetch(`${this.apiUrl}/items/${id}`, {
+                method: 'PATCH',
+                headers,
                 body: JSON.stringify(formData)
             });
+            if (response.status === 412) {
+                throw new Error('запись изменена другим пользователем, откройте её заново');
+            }
             if (!response.ok) {
                 const errorText = await response.text();
                 throw new Error(errorText || 'Ошибка обновления записи');
@@ -362,7 +365,7 @@ class SalesTrackerApp {
     async confirmDelete(id) {
         if (!confirm('Вы уверены, что хотите удалить эту запись?')) return;
         try {
-            const response = await fetch(`${this.apiUrl}/items/${id}`, {
+            const response = await this.apiFetch(`${this.apiUrl}/items/${id}`, {
                 method: 'DELETE'
             });
             if (!response.ok) {
@@ -398,7 +401,7 @@ class SalesTrackerApp {
         }
         
         try {
-            const response = await fetch(`${this.apiUrl}/analytics?from=${encodeURIComponent(from)}&to=${encodeURIComponent(to)}`);
+            const response = await this.apiFetch(`${this.apiUrl}/analytics?from=${encodeURIComponent(from)}&to=${encodeURIComponent(to)}`);
             if (!response.ok) {
                 const errorText = await response.text();
                 throw new Error(errorText || 'Ошибка получения аналитики');
@@ -456,7 +459,7 @@ class SalesTrackerApp {
             <tr>
                 <td>${item.id}</td>
                 <td><span class="${item.type}">${this.getTypeLabel(item.type)}</span></td>
-                <td><span class="${item.type}">${this.formatCurrency(item.amount)}</span></td>
+                <td><span class="${item.type}">${this.formatCurrency(item.amount, item.currency)}</span></td>
                 <td>${this.formatDate(item.date)}</td>
                 <td>${item.category || '-'}</td>
             </tr>
@@ -468,22 +471,29 @@ class SalesTrackerApp {
             const fromInput = document.getElementById('analytics-from').value;
             const toInput = document.getElementById('analytics-to').value;
             
-            let exportUrl = `${this.apiUrl}/items/export`;
+            const [format, layout] = document.getElementById('export-format').value.split(':');
+            const params = new URLSearchParams({ format, layout });
             
             // Если есть период из аналитики, передаём его
             if (fromInput && toInput) {
-                const from = this.convertToUTC(fromInput);
-                const to = this.convertToUTC(toInput);
-                exportUrl += `?from=${encodeURIComponent(from)}&to=${encodeURIComponent(to)}`;
+                params.set('from', this.convertToUTC(fromInput));
+                params.set('to', this.convertToUTC(toInput));
             }
             
+            const response = await this.apiFetch(`${this.apiUrl}/items/export?${params}`);
+            if (!response.ok) {
+                const errorText = await response.text();
+                throw new Error(errorText || 'Ошибка экспорта');
+            }
+            const url = URL.createObjectURL(await response.blob());
             const link = document.createElement('a');
-            link.href = exportUrl;
-            link.download = `sales_tracker_${new Date().toISOString().slice(0, 10)}.csv`;
+            link.href = url;
+            link.download = `sales_tracker_${new Date().toISOString().slice(0, 10)}.${format}`;
             document.body.appendChild(link);
             link.click();
             document.body.removeChild(link);
-            this.showSuccessMessage('Отчёт успешно экспортирован в CSV');
+            URL.revokeObjectURL(url);
+            this.showSuccessMessage('Отчёт успешно экспортирован');
         } catch (error) {
             this.showErrorMessage(`Ошибка экспорта: ${error.message}`);
         }